@@ -16,6 +16,7 @@ import (
 	"github.com/inkyblackness/hacked/editor/texts"
 	"github.com/inkyblackness/hacked/ss1/content/archive"
 	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/bitmap"
 	"github.com/inkyblackness/hacked/ss1/content/movie"
 	"github.com/inkyblackness/hacked/ss1/content/text"
 	"github.com/inkyblackness/hacked/ss1/resource"
@@ -65,16 +66,19 @@ type Application struct {
 
 	levels [archive.MaxLevels]*level.Level
 
-	projectView      *project.View
-	archiveView      *archives.View
-	levelControlView *levels.ControlView
-	levelTilesView   *levels.TilesView
-	levelObjectsView *levels.ObjectsView
-	messagesView     *messages.View
-	textsView        *texts.View
-	bitmapsView      *bitmaps.View
-	aboutView        *about.View
-	licensesView     *about.LicensesView
+	projectView       *project.View
+	archiveView       *archives.View
+	levelControlView  *levels.ControlView
+	levelTilesView    *levels.TilesView
+	levelObjectsView  *levels.ObjectsView
+	levelSearchView   *levels.ObjectSearchView
+	levelPreviewView  *levels.PreviewView
+	levelOverviewView *levels.OverviewView
+	messagesView      *messages.View
+	textsView         *texts.View
+	bitmapsView       *bitmaps.View
+	aboutView         *about.View
+	licensesView      *about.LicensesView
 
 	modalState gui.ModalStateWrapper
 
@@ -140,17 +144,25 @@ func (app *Application) render() {
 	app.projectView.Render()
 	app.archiveView.Render()
 	activeLevel := app.levels[app.levelControlView.SelectedLevel()]
-	app.levelControlView.Render(activeLevel)
+	app.levelControlView.Render(activeLevel, app.levels[:])
 	app.levelTilesView.Render(activeLevel)
 	app.levelObjectsView.Render(activeLevel)
+	app.levelSearchView.Render(activeLevel)
 	app.messagesView.Render()
 	app.textsView.Render()
 	app.bitmapsView.Render()
 
 	paletteTexture, _ := app.paletteCache.Palette(0)
+	app.levelPreviewView.Render(activeLevel, paletteTexture)
+	app.levelOverviewView.Render(app.levels[:], activeLevel.ID())
+	var palette bitmap.Palette
+	if paletteTexture != nil {
+		palette = paletteTexture.Palette()
+	}
 	app.mapDisplay.Render(app.mod.ObjectProperties(), activeLevel,
 		paletteTexture, app.textureCache.Texture,
-		app.levelTilesView.TextureDisplay(), app.levelTilesView.ColorDisplay(activeLevel))
+		app.levelTilesView.TextureDisplay(), app.levelTilesView.ColorQuery(activeLevel, palette),
+		app.levelTilesView.MapTool(), app.levelTilesView.MapSnap(), app.levelObjectsView.LinkFilter())
 
 	// imgui.ShowDemoWindow(nil)
 
@@ -414,6 +426,9 @@ func (app *Application) resourcesChanged(modifiedIDs []resource.ID, failedIDs []
 	}
 	app.paletteCache.InvalidateResources(modifiedIDs)
 	app.textureCache.InvalidateResources(modifiedIDs)
+	app.levelPreviewView.InvalidateResources(modifiedIDs)
+	app.levelOverviewView.InvalidateResources(modifiedIDs)
+	app.levelTilesView.InvalidateResources(modifiedIDs)
 }
 
 func (app *Application) modReset() {
@@ -423,9 +438,12 @@ func (app *Application) modReset() {
 func (app *Application) initView() {
 	app.projectView = project.NewView(app.mod, app.GuiScale, app)
 	app.archiveView = archives.NewArchiveView(app.mod, app.GuiScale, app)
-	app.levelControlView = levels.NewControlView(app.mod, app.GuiScale, app.textLineCache, app.textureCache, app, &app.eventQueue, app.eventDispatcher)
-	app.levelTilesView = levels.NewTilesView(app.mod, app.GuiScale, app.textLineCache, app.textureCache, app, &app.eventQueue, app.eventDispatcher)
+	app.levelControlView = levels.NewControlView(app.mod, app.GuiScale, app.textLineCache, app.textureCache, app.clipboard, app, &app.eventQueue, app.eventDispatcher)
+	app.levelTilesView = levels.NewTilesView(app.mod, app.GuiScale, app.textLineCache, app.textureCache, app.clipboard, &app.modalState, app, &app.eventQueue, app.eventDispatcher)
 	app.levelObjectsView = levels.NewObjectsView(app.mod, app.GuiScale, app.textLineCache, app.textureCache, app, &app.eventQueue, app.eventDispatcher)
+	app.levelPreviewView = levels.NewPreviewView(app.mod, app.gl, app.GuiScale, app.eventDispatcher)
+	app.levelSearchView = levels.NewObjectSearchView(app.mod, app.GuiScale, app.textLineCache, &app.eventQueue)
+	app.levelOverviewView = levels.NewOverviewView(app.gl, app.GuiScale, &app.eventQueue)
 	app.messagesView = messages.NewMessagesView(app.mod, app.messagesCache, app.cp, app.movieCache, app.textureCache, &app.modalState, app.clipboard, app.GuiScale, app)
 	app.textsView = texts.NewTextsView(app.mod, app.textLineCache, app.textPageCache, app.cp, app.movieCache, &app.modalState, app.clipboard, app.GuiScale, app)
 	app.bitmapsView = bitmaps.NewBitmapsView(app.mod, app.textureCache, app.paletteCache, &app.modalState, app.clipboard, app.GuiScale, app)
@@ -433,6 +451,10 @@ func (app *Application) initView() {
 	app.licensesView = about.NewLicensesView(app.GuiScale)
 
 	app.eventDispatcher.RegisterHandler(app.onLevelObjectRequestCreateEvent)
+	app.eventDispatcher.RegisterHandler(app.onLevelTilePaintRequestEvent)
+	app.eventDispatcher.RegisterHandler(app.onLevelObjectMoveRequestEvent)
+	app.eventDispatcher.RegisterHandler(app.onLevelObjectRotateRequestEvent)
+	app.eventDispatcher.RegisterHandler(app.onLevelCritterPatrolRequestEvent)
 }
 
 // Queue requests to perform the given command.
@@ -461,6 +483,35 @@ func (app *Application) onLevelObjectRequestCreateEvent(evt levels.ObjectRequest
 	app.levelObjectsView.RequestCreateObject(lvl, evt.Pos)
 }
 
+func (app *Application) onLevelObjectMoveRequestEvent(evt levels.ObjectMoveRequestEvent) {
+	lvl := app.levels[app.levelControlView.SelectedLevel()]
+	app.levelObjectsView.RequestMoveObjects(lvl, evt.Objects, evt.Positions)
+}
+
+func (app *Application) onLevelObjectRotateRequestEvent(evt levels.ObjectRotateRequestEvent) {
+	lvl := app.levels[app.levelControlView.SelectedLevel()]
+	app.levelObjectsView.RequestRotateObjects(lvl, evt.Objects, evt.Heading)
+}
+
+func (app *Application) onLevelCritterPatrolRequestEvent(evt levels.CritterPatrolRequestEvent) {
+	lvl := app.levels[app.levelControlView.SelectedLevel()]
+	app.levelObjectsView.RequestSetCritterPatrol(lvl, evt.Critter, evt.Pos)
+}
+
+func (app *Application) onLevelTilePaintRequestEvent(evt levels.TilePaintRequestEvent) {
+	lvl := app.levels[app.levelControlView.SelectedLevel()]
+	app.levelTilesView.RequestPaintTiles(lvl, evt.Tiles)
+}
+
+func (app *Application) exportLevelMap(pixelsPerTile int) {
+	var palette bitmap.Palette
+	if paletteTexture, err := app.paletteCache.Palette(0); err == nil {
+		palette = paletteTexture.Palette()
+	}
+	lvl := app.levels[app.levelControlView.SelectedLevel()]
+	app.levelTilesView.ExportMapImage(lvl, palette, pixelsPerTile)
+}
+
 func (app *Application) renderMainMenu() {
 	windowEntry := func(name string, shortcut string, isOpen *bool) {
 		if imgui.MenuItemV(name, shortcut, *isOpen, true) {
@@ -472,6 +523,15 @@ func (app *Application) renderMainMenu() {
 		if imgui.BeginMenu("File") {
 			windowEntry("Project", "F1", app.projectView.WindowOpen())
 			imgui.Separator()
+			if imgui.BeginMenu("Export Level Map") {
+				for _, pixelsPerTile := range []int{16, 32, 64, 128} {
+					if imgui.MenuItem(fmt.Sprintf("%d Pixels per Tile", pixelsPerTile)) {
+						app.exportLevelMap(pixelsPerTile)
+					}
+				}
+				imgui.EndMenu()
+			}
+			imgui.Separator()
 			if imgui.MenuItem("Exit") {
 				app.window.SetCloseRequest(true)
 			}
@@ -491,6 +551,9 @@ func (app *Application) renderMainMenu() {
 			windowEntry("Level Control", "F2", app.levelControlView.WindowOpen())
 			windowEntry("Level Tiles", "F3", app.levelTilesView.WindowOpen())
 			windowEntry("Level Objects", "F4", app.levelObjectsView.WindowOpen())
+			windowEntry("Level Object Search", "", app.levelSearchView.WindowOpen())
+			windowEntry("Level Preview", "", app.levelPreviewView.WindowOpen())
+			windowEntry("Level Overview", "", app.levelOverviewView.WindowOpen())
 			windowEntry("Messages", "F5", app.messagesView.WindowOpen())
 			windowEntry("Texts", "", app.textsView.WindowOpen())
 			windowEntry("Bitmaps", "", app.bitmapsView.WindowOpen())
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"github.com/inkyblackness/hacked/ss1/resource"
+)
+
+// SetResourcesCommand replaces the data of resources that consist of a single block.
+// The previous resources are removed entirely before the new ones are set.
+// A resource without data in the state to set is deleted.
+type SetResourcesCommand struct {
+	OldData map[resource.ID][]byte
+	NewData map[resource.ID][]byte
+
+	// RestoreState is called after the data was set. forward is true for Do(), false for Undo().
+	RestoreState func(forward bool)
+}
+
+// Do removes the old resources and sets the new data.
+func (command SetResourcesCommand) Do(trans Transaction) error {
+	command.perform(trans, command.OldData, command.NewData)
+	command.restoreState(true)
+	return nil
+}
+
+// Undo removes the new resources and sets the old data.
+func (command SetResourcesCommand) Undo(trans Transaction) error {
+	command.perform(trans, command.NewData, command.OldData)
+	command.restoreState(false)
+	return nil
+}
+
+func (command SetResourcesCommand) perform(trans Transaction, previous, next map[resource.ID][]byte) {
+	for id := range previous {
+		trans.DelResource(resource.LangAny, id)
+	}
+	for id, blockData := range next {
+		if len(blockData) > 0 {
+			trans.SetResourceBlocks(resource.LangAny, id, [][]byte{blockData})
+		} else {
+			trans.DelResource(resource.LangAny, id)
+		}
+	}
+}
+
+func (command SetResourcesCommand) restoreState(forward bool) {
+	if command.RestoreState != nil {
+		command.RestoreState(forward)
+	}
+}
@@ -0,0 +1,130 @@
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/editor/cmd"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type testingTransaction struct {
+	resources map[resource.ID][]byte
+}
+
+func (trans *testingTransaction) SetResource(id resource.ID, compound bool, contentType resource.ContentType, compressed bool) {
+}
+
+func (trans *testingTransaction) SetResourceBlock(lang resource.Language, id resource.ID, index int, data []byte) {
+}
+
+func (trans *testingTransaction) PatchResourceBlock(lang resource.Language, id resource.ID, index int, expectedLength int, patch []byte) {
+}
+
+func (trans *testingTransaction) SetResourceBlocks(lang resource.Language, id resource.ID, data [][]byte) {
+	trans.resources[id] = data[0]
+}
+
+func (trans *testingTransaction) DelResource(lang resource.Language, id resource.ID) {
+	delete(trans.resources, id)
+}
+
+type SetResourcesCommandSuite struct {
+	suite.Suite
+
+	instance  cmd.SetResourcesCommand
+	trans     testingTransaction
+	restored  []bool
+	lastError error
+}
+
+func TestSetResourcesCommandSuite(t *testing.T) {
+	suite.Run(t, new(SetResourcesCommandSuite))
+}
+
+func (suite *SetResourcesCommandSuite) SetupTest() {
+	suite.trans = testingTransaction{resources: make(map[resource.ID][]byte)}
+	suite.restored = nil
+	suite.instance = cmd.SetResourcesCommand{
+		OldData: make(map[resource.ID][]byte),
+		NewData: make(map[resource.ID][]byte),
+		RestoreState: func(forward bool) {
+			suite.restored = append(suite.restored, forward)
+		},
+	}
+}
+
+func (suite *SetResourcesCommandSuite) TestDoSetsNewData() {
+	suite.givenOldData(0x1000, []byte{0x01})
+	suite.givenNewData(0x1000, []byte{0x02})
+	suite.givenNewData(0x1001, []byte{0x03})
+	suite.whenCommandIsDone()
+	suite.thenLastErrorShouldBeNil()
+	suite.thenResourceShouldBe(0x1000, []byte{0x02})
+	suite.thenResourceShouldBe(0x1001, []byte{0x03})
+}
+
+func (suite *SetResourcesCommandSuite) TestDoRemovesOldDataNotPartOfNewData() {
+	suite.givenOldData(0x1000, []byte{0x01})
+	suite.givenNewData(0x1001, []byte{0x03})
+	suite.whenCommandIsDone()
+	suite.thenResourceShouldNotExist(0x1000)
+}
+
+func (suite *SetResourcesCommandSuite) TestUndoRestoresOldData() {
+	suite.givenOldData(0x1000, []byte{0x01})
+	suite.givenNewData(0x1000, []byte{0x02})
+	suite.givenNewData(0x1001, []byte{0x03})
+	suite.whenCommandIsDone()
+	suite.whenCommandIsUndone()
+	suite.thenLastErrorShouldBeNil()
+	suite.thenResourceShouldBe(0x1000, []byte{0x01})
+	suite.thenResourceShouldNotExist(0x1001)
+}
+
+func (suite *SetResourcesCommandSuite) TestUndoDeletesResourcesWithoutOldData() {
+	suite.givenOldData(0x1000, nil)
+	suite.givenNewData(0x1000, []byte{0x02})
+	suite.whenCommandIsDone()
+	suite.whenCommandIsUndone()
+	suite.thenResourceShouldNotExist(0x1000)
+}
+
+func (suite *SetResourcesCommandSuite) TestCommandRestoresState() {
+	suite.whenCommandIsDone()
+	suite.whenCommandIsUndone()
+	assert.Equal(suite.T(), []bool{true, false}, suite.restored)
+}
+
+func (suite *SetResourcesCommandSuite) givenOldData(id resource.ID, data []byte) {
+	suite.instance.OldData[id] = data
+	if len(data) > 0 {
+		suite.trans.resources[id] = data
+	}
+}
+
+func (suite *SetResourcesCommandSuite) givenNewData(id resource.ID, data []byte) {
+	suite.instance.NewData[id] = data
+}
+
+func (suite *SetResourcesCommandSuite) whenCommandIsDone() {
+	suite.lastError = suite.instance.Do(&suite.trans)
+}
+
+func (suite *SetResourcesCommandSuite) whenCommandIsUndone() {
+	suite.lastError = suite.instance.Undo(&suite.trans)
+}
+
+func (suite *SetResourcesCommandSuite) thenLastErrorShouldBeNil() {
+	assert.Nil(suite.T(), suite.lastError, "No error expected")
+}
+
+func (suite *SetResourcesCommandSuite) thenResourceShouldBe(id resource.ID, expected []byte) {
+	assert.Equal(suite.T(), expected, suite.trans.resources[id], "Resource data mismatch for %v", id)
+}
+
+func (suite *SetResourcesCommandSuite) thenResourceShouldNotExist(id resource.ID) {
+	_, exists := suite.trans.resources[id]
+	assert.False(suite.T(), exists, "Resource %v should not exist", id)
+}
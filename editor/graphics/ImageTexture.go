@@ -0,0 +1,54 @@
+package graphics
+
+import (
+	"image"
+
+	"github.com/inkyblackness/hacked/ui/opengl"
+)
+
+// ImageTexture wraps an OpenGL handle for a true-color image.
+type ImageTexture struct {
+	gl     opengl.OpenGL
+	handle uint32
+
+	width, height float32
+}
+
+// NewImageTexture downloads the provided image to OpenGL and returns an ImageTexture instance.
+func NewImageTexture(gl opengl.OpenGL, img *image.RGBA) *ImageTexture {
+	bounds := img.Bounds()
+	tex := &ImageTexture{
+		gl:     gl,
+		handle: gl.GenTextures(1)[0],
+
+		width:  float32(bounds.Dx()),
+		height: float32(bounds.Dy()),
+	}
+
+	gl.BindTexture(opengl.TEXTURE_2D, tex.handle)
+	gl.TexImage2D(opengl.TEXTURE_2D, 0, opengl.RGBA, int32(bounds.Dx()), int32(bounds.Dy()),
+		0, opengl.RGBA, opengl.UNSIGNED_BYTE, img.Pix)
+	gl.TexParameteri(opengl.TEXTURE_2D, opengl.TEXTURE_MAG_FILTER, opengl.NEAREST)
+	gl.TexParameteri(opengl.TEXTURE_2D, opengl.TEXTURE_MIN_FILTER, opengl.NEAREST)
+	gl.BindTexture(opengl.TEXTURE_2D, 0)
+
+	return tex
+}
+
+// Dispose releases the OpenGL texture.
+func (tex *ImageTexture) Dispose() {
+	if tex.handle != 0 {
+		tex.gl.DeleteTextures([]uint32{tex.handle})
+		tex.handle = 0
+	}
+}
+
+// Handle returns the texture handle.
+func (tex *ImageTexture) Handle() uint32 {
+	return tex.handle
+}
+
+// Size returns the dimensions of the image.
+func (tex *ImageTexture) Size() (width, height float32) {
+	return tex.width, tex.height
+}
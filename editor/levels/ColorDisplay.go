@@ -2,6 +2,10 @@ package levels
 
 import (
 	"fmt"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlrender"
+	"github.com/inkyblackness/hacked/ss1/content/bitmap"
 )
 
 // ColorDisplay is an enumeration to how a tile should be colored.
@@ -32,3 +36,17 @@ func (display ColorDisplay) String() string {
 func ColorDisplays() []ColorDisplay {
 	return []ColorDisplay{ColorDisplayNone, ColorDisplayFloor, ColorDisplayCeiling}
 }
+
+// QueryFor returns the query to color the tiles of given level.
+// Cyberspace levels are colored with their palette entries, real-world levels with their shadows.
+// The returned query is nil if no coloring is to be done.
+func (display ColorDisplay) QueryFor(lvl *level.Level, palette bitmap.Palette) ColorQuery {
+	switch display {
+	case ColorDisplayFloor:
+		return ColorQuery(lvlrender.FloorColors(lvl, palette))
+	case ColorDisplayCeiling:
+		return ColorQuery(lvlrender.CeilingColors(lvl, palette))
+	default:
+		return nil
+	}
+}
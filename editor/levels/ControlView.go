@@ -2,9 +2,12 @@ package levels
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/inkyblackness/hacked/editor/cmd"
 	"github.com/inkyblackness/hacked/editor/event"
+	"github.com/inkyblackness/hacked/editor/external"
 	"github.com/inkyblackness/hacked/editor/graphics"
 	"github.com/inkyblackness/hacked/editor/model"
 	"github.com/inkyblackness/hacked/editor/render"
@@ -29,13 +32,15 @@ type ControlView struct {
 
 	textCache    *text.Cache
 	textureCache *graphics.TextureCache
+	clipboard    external.Clipboard
 
-	model controlViewModel
+	model         controlViewModel
+	selectedTiles tileCoordinates
 }
 
 // NewControlView returns a new instance.
 func NewControlView(mod *model.Mod, guiScale float32, textCache *text.Cache, textureCache *graphics.TextureCache,
-	commander cmd.Commander, eventListener event.Listener, eventRegistry event.Registry) *ControlView {
+	clipboard external.Clipboard, commander cmd.Commander, eventListener event.Listener, eventRegistry event.Registry) *ControlView {
 	view := &ControlView{
 		mod:           mod,
 		guiScale:      guiScale,
@@ -43,9 +48,13 @@ func NewControlView(mod *model.Mod, guiScale float32, textCache *text.Cache, tex
 		eventListener: eventListener,
 		textCache:     textCache,
 		textureCache:  textureCache,
+		clipboard:     clipboard,
 		model:         freshControlViewModel(),
 	}
+	view.selectedTiles.registerAt(eventRegistry)
 	eventRegistry.RegisterHandler(view.onLevelSelectionSetEvent)
+	eventRegistry.RegisterHandler(view.onMapNoteSelectionSetEvent)
+	eventRegistry.RegisterHandler(view.onHeightSemaphoreSelectionSetEvent)
 	view.setSelectedLevel(view.model.selectedLevel)
 	return view
 }
@@ -61,7 +70,8 @@ func (view *ControlView) SelectedLevel() int {
 }
 
 // Render renders the view.
-func (view *ControlView) Render(lvl *level.Level) {
+// The other levels are necessary to check references among the levels.
+func (view *ControlView) Render(lvl *level.Level, levels []*level.Level) {
 	if view.model.restoreFocus {
 		imgui.SetNextWindowFocus()
 		view.model.restoreFocus = false
@@ -75,7 +85,7 @@ func (view *ControlView) Render(lvl *level.Level) {
 			title += " (read-only)"
 		}
 		if imgui.BeginV(title+"###Level Control", view.WindowOpen(), imgui.WindowFlagsNoCollapse) {
-			view.renderContent(lvl, readOnly)
+			view.renderContent(lvl, levels, readOnly)
 		}
 		imgui.End()
 	}
@@ -92,7 +102,7 @@ var levelHeights = []string{
 	"1/4 Tile",
 }
 
-func (view *ControlView) renderContent(lvl *level.Level, readOnly bool) {
+func (view *ControlView) renderContent(lvl *level.Level, levels []*level.Level, readOnly bool) {
 	imgui.PushItemWidth(-200 * view.guiScale)
 	selectedLevel := view.model.selectedLevel
 	if gui.StepSliderInt("Active Level", &selectedLevel, 0, archive.MaxLevels-1) {
@@ -112,7 +122,13 @@ func (view *ControlView) renderContent(lvl *level.Level, readOnly bool) {
 		view.renderSurveillanceObjects(lvl, readOnly)
 		view.renderHazards(lvl, readOnly)
 		view.renderTextureAnimations(lvl, readOnly)
+		view.renderMapNotes(lvl, readOnly)
+		view.renderHeightSemaphores(lvl, readOnly)
 	}
+	view.renderLoopConfig(lvl, readOnly)
+	view.renderSchedules(lvl, readOnly)
+	view.renderObjectCapacities(lvl, readOnly)
+	view.renderObjectCompaction(lvl, levels, readOnly)
 
 	imgui.PopItemWidth()
 }
@@ -300,6 +316,359 @@ func (view *ControlView) renderTextureAnimations(lvl *level.Level, readOnly bool
 	}
 }
 
+func (view *ControlView) renderMapNotes(lvl *level.Level, readOnly bool) {
+	imgui.Separator()
+
+	notes := lvl.MapNotes()
+	noteTitle := func(index int) string {
+		note := notes[index]
+		return fmt.Sprintf("%2d: (%2d, %2d) %s", index, note.X, note.Y, note.Text)
+	}
+	selectedIndex := view.model.selectedMapNoteIndex
+	hasSelectedNote := (selectedIndex >= 0) && (selectedIndex < len(notes))
+	selectedText := ""
+	if hasSelectedNote {
+		selectedText = noteTitle(selectedIndex)
+	}
+	if imgui.BeginCombo("Map Note", selectedText) {
+		for i := 0; i < len(notes); i++ {
+			if imgui.SelectableV(noteTitle(i), i == selectedIndex, 0, imgui.Vec2{}) {
+				view.eventListener.Event(MapNoteSelectionSetEvent{index: i})
+			}
+		}
+		imgui.EndCombo()
+	}
+	imgui.LabelText("Map Notes Space", fmt.Sprintf("%d / %d bytes", notes.Size(), level.MapNotesSize))
+
+	if hasSelectedNote {
+		note := notes[selectedIndex]
+		columns, rows, _ := lvl.Size()
+		noteText := note.Text
+		if readOnly {
+			imgui.LabelText("Map Note Text", noteText)
+		} else if imgui.InputText("Map Note Text", &noteText) {
+			view.requestSetMapNoteText(lvl, selectedIndex, noteText)
+		}
+		view.renderSliderInt(readOnly, "Map Note X", int(note.X),
+			func(int) string { return "%d" },
+			0, columns-1,
+			func(newValue int) {
+				view.requestSetMapNotePosition(lvl, selectedIndex, byte(newValue), note.Y)
+			})
+		view.renderSliderInt(readOnly, "Map Note Y", int(note.Y),
+			func(int) string { return "%d" },
+			0, rows-1,
+			func(newValue int) {
+				view.requestSetMapNotePosition(lvl, selectedIndex, note.X, byte(newValue))
+			})
+	}
+	if !readOnly {
+		hasSelectedTile := len(view.selectedTiles.list) > 0
+		if hasSelectedTile && imgui.Button("Add Note at Tile") {
+			pos := view.selectedTiles.list[0]
+			view.requestAddMapNote(lvl, pos.X.Tile(), pos.Y.Tile())
+		}
+		if hasSelectedNote {
+			if hasSelectedTile {
+				imgui.SameLine()
+				if imgui.Button("Move Note to Tile") {
+					pos := view.selectedTiles.list[0]
+					view.requestSetMapNotePosition(lvl, selectedIndex, pos.X.Tile(), pos.Y.Tile())
+				}
+			}
+			if imgui.Button("Note -> Clip") {
+				view.clipboard.SetString(notes[selectedIndex].Text)
+			}
+			imgui.SameLine()
+			if imgui.Button("Note <- Clip") {
+				view.setMapNoteTextFromClipboard(lvl, selectedIndex)
+			}
+			imgui.SameLine()
+			if imgui.Button("Remove Note") {
+				view.requestRemoveMapNote(lvl, selectedIndex)
+			}
+		}
+	}
+}
+
+func (view *ControlView) renderHeightSemaphores(lvl *level.Level, readOnly bool) {
+	imgui.Separator()
+
+	sems := lvl.HeightSemaphores()
+	semTitle := func(index int) string {
+		if (index < 0) || (index >= len(sems)) {
+			return ""
+		}
+		sem := sems[index]
+		if !sem.IsInUse() {
+			return fmt.Sprintf("%2d: (unused)", index)
+		}
+		part := "Ceiling"
+		if sem.IsFloor() {
+			part = "Floor"
+		}
+		return fmt.Sprintf("%2d: %s at (%2d, %2d)", index, part, sem.X, sem.Y)
+	}
+	selectedIndex := view.model.selectedHeightSemaphoreIndex
+	if imgui.BeginCombo("Height Semaphore", semTitle(selectedIndex)) {
+		for i := 0; i < len(sems); i++ {
+			if imgui.SelectableV(semTitle(i), i == selectedIndex, 0, imgui.Vec2{}) {
+				view.eventListener.Event(HeightSemaphoreSelectionSetEvent{index: i})
+			}
+		}
+		imgui.EndCombo()
+	}
+	if len(view.selectedTiles.list) > 0 {
+		pos := view.selectedTiles.list[0]
+		tileSems := sems.At(pos.X.Tile(), pos.Y.Tile())
+		if len(tileSems) == 0 {
+			imgui.LabelText("Tile Semaphores", "(none)")
+		} else if imgui.BeginCombo("Tile Semaphores", fmt.Sprintf("%d locking selected tile", len(tileSems))) {
+			for _, index := range tileSems {
+				if imgui.SelectableV(semTitle(index), index == selectedIndex, 0, imgui.Vec2{}) {
+					view.eventListener.Event(HeightSemaphoreSelectionSetEvent{index: index})
+				}
+			}
+			imgui.EndCombo()
+		}
+	}
+	if (selectedIndex < 0) || (selectedIndex >= len(sems)) {
+		return
+	}
+
+	sem := sems[selectedIndex]
+	columns, rows, _ := lvl.Size()
+	partName := func(floor bool) string {
+		if floor {
+			return "Floor"
+		}
+		return "Ceiling"
+	}
+	if readOnly {
+		imgui.LabelText("Semaphore Part", partName(sem.IsFloor()))
+	} else if imgui.BeginCombo("Semaphore Part", partName(sem.IsFloor())) {
+		for _, floor := range []bool{true, false} {
+			if imgui.SelectableV(partName(floor), floor == sem.IsFloor(), 0, imgui.Vec2{}) {
+				view.requestSetHeightSemaphore(lvl, selectedIndex, sem.WithFloor(floor))
+			}
+		}
+		imgui.EndCombo()
+	}
+	view.renderSliderInt(readOnly, "Semaphore Use Count", sem.UseCount(),
+		func(int) string { return "%d" },
+		0, 127,
+		func(newValue int) {
+			view.requestSetHeightSemaphore(lvl, selectedIndex, sem.WithUseCount(newValue))
+		})
+	view.renderSliderInt(readOnly, "Semaphore X", int(sem.X),
+		func(int) string { return "%d" },
+		0, columns-1,
+		func(newValue int) {
+			sem.X = byte(newValue)
+			view.requestSetHeightSemaphore(lvl, selectedIndex, sem)
+		})
+	view.renderSliderInt(readOnly, "Semaphore Y", int(sem.Y),
+		func(int) string { return "%d" },
+		0, rows-1,
+		func(newValue int) {
+			sem.Y = byte(newValue)
+			view.requestSetHeightSemaphore(lvl, selectedIndex, sem)
+		})
+	view.renderSliderInt(readOnly, "Semaphore Unknown 03", int(sem.Unknown03),
+		func(int) string { return "0x%02X" },
+		0, 0xFF,
+		func(newValue int) {
+			sem.Unknown03 = byte(newValue)
+			view.requestSetHeightSemaphore(lvl, selectedIndex, sem)
+		})
+	if imgui.Button("Select Semaphore Tile") {
+		view.eventListener.Event(TileSelectionSetEvent{tiles: []MapPosition{heightSemaphorePosition(sem)}})
+	}
+	if !readOnly {
+		imgui.SameLine()
+		if len(view.selectedTiles.list) > 0 {
+			if imgui.Button("Move Semaphore to Tile") {
+				pos := view.selectedTiles.list[0]
+				sem.X = pos.X.Tile()
+				sem.Y = pos.Y.Tile()
+				view.requestSetHeightSemaphore(lvl, selectedIndex, sem)
+			}
+			imgui.SameLine()
+		}
+		if imgui.Button("Clear Semaphore") {
+			view.requestSetHeightSemaphore(lvl, selectedIndex, level.HeightSemaphore{})
+		}
+	}
+}
+
+func (view *ControlView) renderLoopConfig(lvl *level.Level, readOnly bool) {
+	imgui.Separator()
+
+	entries := lvl.LoopConfig()
+	invalidEntries := make(map[int]bool)
+	for _, index := range lvl.InvalidLoopConfigEntries() {
+		invalidEntries[index] = true
+	}
+	entryTitle := func(index int) string {
+		entry := entries[index]
+		if !entry.IsInUse() {
+			return fmt.Sprintf("%2d: (unused)", index)
+		}
+		title := fmt.Sprintf("%2d: Object %d", index, entry.ObjectID)
+		if invalidEntries[index] {
+			title += " (invalid)"
+		}
+		return title
+	}
+	selectedIndex := view.model.selectedLoopConfigIndex
+	hasSelectedEntry := (selectedIndex >= 0) && (selectedIndex < len(entries))
+	selectedText := ""
+	if hasSelectedEntry {
+		selectedText = entryTitle(selectedIndex)
+	}
+	if imgui.BeginCombo("Loop Configuration", selectedText) {
+		for i := 0; i < len(entries); i++ {
+			if imgui.SelectableV(entryTitle(i), i == selectedIndex, 0, imgui.Vec2{}) {
+				view.model.selectedLoopConfigIndex = i
+			}
+		}
+		imgui.EndCombo()
+	}
+	if len(invalidEntries) > 0 {
+		imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{X: 1.0, Y: 0.0, Z: 0.0, W: 1.0})
+		imgui.Text(fmt.Sprintf("%d loop entries refer to objects not in use.", len(invalidEntries)))
+		imgui.PopStyleColor()
+	}
+	if !hasSelectedEntry {
+		return
+	}
+
+	entry := entries[selectedIndex]
+	view.renderSliderInt(readOnly, "Loop Object", int(entry.ObjectID),
+		func(int) string { return "%d" },
+		0, int(lvl.ObjectLimit()),
+		func(newValue int) {
+			view.requestSetLoopConfigEntry(lvl, selectedIndex, func(entry *level.LoopConfigEntry) {
+				entry.ObjectID = level.ObjectID(newValue)
+			})
+		})
+	view.renderSliderInt(readOnly, "Loop Flags", int(entry.Flags),
+		func(int) string { return "0x%02X" },
+		0, 0xFF,
+		func(newValue int) {
+			view.requestSetLoopConfigEntry(lvl, selectedIndex, func(entry *level.LoopConfigEntry) {
+				entry.Flags = byte(newValue)
+			})
+		})
+	view.renderSliderInt(readOnly, "Loop Callback Type", int(entry.CallbackType),
+		func(int) string { return "%d" },
+		0, 0xFFFF,
+		func(newValue int) {
+			view.requestSetLoopConfigEntry(lvl, selectedIndex, func(entry *level.LoopConfigEntry) {
+				entry.CallbackType = uint16(newValue)
+			})
+		})
+	view.renderHexInputUint32(readOnly, "Loop User Data", entry.UserData,
+		func(newValue uint32) {
+			view.requestSetLoopConfigEntry(lvl, selectedIndex, func(entry *level.LoopConfigEntry) {
+				entry.UserData = newValue
+			})
+		})
+	view.renderSliderInt(readOnly, "Loop Frame Time", int(entry.FrameTime),
+		func(int) string { return "%d msec" },
+		0, 0xFFFF,
+		func(newValue int) {
+			view.requestSetLoopConfigEntry(lvl, selectedIndex, func(entry *level.LoopConfigEntry) {
+				entry.FrameTime = uint16(newValue)
+			})
+		})
+	imgui.LabelText("Loop Time Remainder", fmt.Sprintf("%d msec", entry.TimeRemainder))
+}
+
+func (view *ControlView) renderSchedules(lvl *level.Level, readOnly bool) {
+	imgui.Separator()
+
+	events := lvl.Schedules()
+	capacity := lvl.ScheduleCapacity()
+	imgui.LabelText("Schedules", fmt.Sprintf("%d / %d events", len(events), capacity))
+	if !readOnly && (capacity > 0) {
+		minCapacity := len(events)
+		if minCapacity < 1 {
+			minCapacity = 1
+		}
+		maxCapacity := int(level.DefaultSchedulerInfo().Size)
+		if maxCapacity < capacity {
+			maxCapacity = capacity
+		}
+		view.renderSliderInt(readOnly, "Schedule Capacity", capacity,
+			func(int) string { return "%d" },
+			minCapacity, maxCapacity,
+			func(newValue int) {
+				view.requestSetScheduleCapacity(lvl, newValue)
+			})
+	}
+	selectedIndex := view.model.selectedScheduleIndex
+	hasSelectedEvent := (selectedIndex >= 0) && (selectedIndex < len(events))
+
+	imgui.BeginChildV("Schedule Timeline", imgui.Vec2{X: -200 * view.guiScale, Y: 100 * view.guiScale}, true, 0)
+	for i, event := range events {
+		text := fmt.Sprintf("%5d: Type %3d - %02X %02X %02X %02X", event.Timestamp, event.Type,
+			event.Data[0], event.Data[1], event.Data[2], event.Data[3])
+		if imgui.SelectableV(text, i == selectedIndex, 0, imgui.Vec2{}) {
+			view.model.selectedScheduleIndex = i
+		}
+	}
+	imgui.EndChild()
+	if !readOnly {
+		imgui.SameLine()
+		imgui.BeginGroup()
+		if (len(events) < capacity) && imgui.Button("Add Event") {
+			lastTimestamp := uint16(0)
+			if len(events) > 0 {
+				lastTimestamp = events[len(events)-1].Timestamp
+			}
+			view.requestSetSchedules(lvl, append(events, level.ScheduleEvent{Timestamp: lastTimestamp}), len(events))
+		}
+		if hasSelectedEvent && imgui.Button("Remove Event") {
+			newEvents := append(level.ScheduleEvents{}, events[:selectedIndex]...)
+			view.requestSetSchedules(lvl, append(newEvents, events[selectedIndex+1:]...), -1)
+		}
+		imgui.EndGroup()
+	}
+	if !hasSelectedEvent {
+		return
+	}
+
+	event := events[selectedIndex]
+	view.renderSliderInt(readOnly, "Event Timestamp", int(event.Timestamp),
+		func(int) string { return "%d" },
+		0, 0xFFFF,
+		func(newValue int) {
+			view.requestSetScheduleEvent(lvl, events, selectedIndex, func(event *level.ScheduleEvent) {
+				event.Timestamp = uint16(newValue)
+			})
+		})
+	view.renderSliderInt(readOnly, "Event Type", int(event.Type),
+		func(int) string { return "%d" },
+		0, 0xFFFF,
+		func(newValue int) {
+			view.requestSetScheduleEvent(lvl, events, selectedIndex, func(event *level.ScheduleEvent) {
+				event.Type = uint16(newValue)
+			})
+		})
+	for dataIndex := 0; dataIndex < len(event.Data); dataIndex++ {
+		byteIndex := dataIndex
+		view.renderSliderInt(readOnly, fmt.Sprintf("Event Data %d", byteIndex), int(event.Data[byteIndex]),
+			func(int) string { return "0x%02X" },
+			0, 0xFF,
+			func(newValue int) {
+				view.requestSetScheduleEvent(lvl, events, selectedIndex, func(event *level.ScheduleEvent) {
+					event.Data[byteIndex] = byte(newValue)
+				})
+			})
+	}
+}
+
 func (view *ControlView) editingAllowed(id int) bool {
 	gameStateData := view.mod.ModifiedBlocks(resource.LangAny, ids.GameState)
 	isSavegame := (len(gameStateData) == 1) && (len(gameStateData[0]) == archive.GameStateSize) && (gameStateData[0][0x009C] > 0)
@@ -322,6 +691,22 @@ func (view *ControlView) renderSliderInt(readOnly bool, label string, selectedVa
 	}
 }
 
+// renderHexInputUint32 shows the value as hexadecimal text, covering the full range of the type.
+// Text that is not a valid value is ignored.
+func (view *ControlView) renderHexInputUint32(readOnly bool, label string, value uint32, changeHandler func(uint32)) {
+	valueText := fmt.Sprintf("%08X", value)
+	if readOnly {
+		imgui.LabelText(label, "0x"+valueText)
+		return
+	}
+	if imgui.InputText(label, &valueText) {
+		newValue, err := strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(valueText), "0X"), 16, 32)
+		if err == nil {
+			changeHandler(uint32(newValue))
+		}
+	}
+}
+
 func (view *ControlView) requestSetZShift(lvl *level.Level, newValue int) {
 	lvl.SetHeightShift(level.HeightShift(newValue))
 	view.patchLevelResources(lvl, func() {})
@@ -400,6 +785,99 @@ func (view *ControlView) requestSetTextureAnimationType(lvl *level.Level, index
 	})
 }
 
+func (view *ControlView) requestSetLoopConfigEntry(lvl *level.Level, index int, modifier func(*level.LoopConfigEntry)) {
+	modifier(&lvl.LoopConfig()[index])
+	view.patchLevelResources(lvl, func() {
+		view.model.selectedLoopConfigIndex = index
+	})
+}
+
+func (view *ControlView) requestSetScheduleEvent(lvl *level.Level, events level.ScheduleEvents, index int,
+	modifier func(*level.ScheduleEvent)) {
+	modifier(&events[index])
+	modified := events[index]
+	events.SortByTime()
+	newIndex := index
+	for i, event := range events {
+		if event == modified {
+			newIndex = i
+			break
+		}
+	}
+	view.requestSetSchedules(lvl, events, newIndex)
+}
+
+func (view *ControlView) requestSetSchedules(lvl *level.Level, events level.ScheduleEvents, selectedIndex int) {
+	err := lvl.SetSchedules(events)
+	if err != nil {
+		return
+	}
+	view.patchLevelResources(lvl, func() {
+		view.model.selectedScheduleIndex = selectedIndex
+	})
+}
+
+func (view *ControlView) requestSetScheduleCapacity(lvl *level.Level, capacity int) {
+	err := lvl.SetScheduleCapacity(capacity)
+	if err != nil {
+		return
+	}
+	selectedIndex := view.model.selectedScheduleIndex
+	view.setLevelResources(lvl, func() {
+		view.model.selectedScheduleIndex = selectedIndex
+	})
+}
+
+func (view *ControlView) requestSetHeightSemaphore(lvl *level.Level, index int, sem level.HeightSemaphore) {
+	lvl.HeightSemaphores()[index] = sem
+	view.patchLevelResources(lvl, func() {
+		view.eventListener.Event(HeightSemaphoreSelectionSetEvent{index: index})
+	})
+}
+
+func (view *ControlView) setMapNoteTextFromClipboard(lvl *level.Level, index int) {
+	value, err := view.clipboard.String()
+	if err != nil {
+		return
+	}
+	view.requestSetMapNoteText(lvl, index, value)
+}
+
+func (view *ControlView) requestSetMapNoteText(lvl *level.Level, index int, value string) {
+	notes := append(level.MapNotes{}, lvl.MapNotes()...)
+	notes[index].Text = value
+	view.requestSetMapNotes(lvl, notes, index)
+}
+
+func (view *ControlView) requestAddMapNote(lvl *level.Level, x, y byte) {
+	notes := append(level.MapNotes{}, lvl.MapNotes()...)
+	notes = append(notes, level.MapNote{X: x, Y: y, Text: "Note"})
+	view.requestSetMapNotes(lvl, notes, len(notes)-1)
+}
+
+func (view *ControlView) requestSetMapNotePosition(lvl *level.Level, index int, x, y byte) {
+	notes := append(level.MapNotes{}, lvl.MapNotes()...)
+	notes[index].X = x
+	notes[index].Y = y
+	view.requestSetMapNotes(lvl, notes, index)
+}
+
+func (view *ControlView) requestRemoveMapNote(lvl *level.Level, index int) {
+	notes := append(level.MapNotes{}, lvl.MapNotes()[:index]...)
+	notes = append(notes, lvl.MapNotes()[index+1:]...)
+	view.requestSetMapNotes(lvl, notes, -1)
+}
+
+func (view *ControlView) requestSetMapNotes(lvl *level.Level, notes level.MapNotes, selectedIndex int) {
+	if notes.Size() > level.MapNotesSize {
+		return
+	}
+	lvl.SetMapNotes(notes)
+	view.patchLevelResources(lvl, func() {
+		view.eventListener.Event(MapNoteSelectionSetEvent{index: selectedIndex})
+	})
+}
+
 func (view *ControlView) patchLevelResources(lvl *level.Level, extraRestoreState func()) {
 
 	command := patchLevelDataCommand{
@@ -432,5 +910,23 @@ func (view *ControlView) setSelectedLevel(id int) {
 }
 
 func (view *ControlView) onLevelSelectionSetEvent(evt LevelSelectionSetEvent) {
+	if view.model.selectedLevel != evt.id {
+		view.model.selectedMapNoteIndex = -1
+		view.model.selectedLoopConfigIndex = -1
+		view.model.selectedScheduleIndex = -1
+		view.model.selectedHeightSemaphoreIndex = -1
+		view.model.capacityLimit = 0
+		view.model.capacityMessage = ""
+		view.model.compactionResult = nil
+		view.model.compactionMessage = ""
+	}
 	view.model.selectedLevel = evt.id
 }
+
+func (view *ControlView) onHeightSemaphoreSelectionSetEvent(evt HeightSemaphoreSelectionSetEvent) {
+	view.model.selectedHeightSemaphoreIndex = evt.index
+}
+
+func (view *ControlView) onMapNoteSelectionSetEvent(evt MapNoteSelectionSetEvent) {
+	view.model.selectedMapNoteIndex = evt.index
+}
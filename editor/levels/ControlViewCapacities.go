@@ -0,0 +1,154 @@
+package levels
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/inkyblackness/hacked/editor/cmd"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlids"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+	"github.com/inkyblackness/hacked/ui/gui"
+	"github.com/inkyblackness/imgui-go"
+)
+
+const (
+	capacityMasterTable = -1
+	capacityMaximum     = 0x7FFF
+)
+
+func (view *ControlView) renderObjectCapacities(lvl *level.Level, readOnly bool) {
+	imgui.Separator()
+
+	tableString := func(table int) string {
+		if table == capacityMasterTable {
+			used := 0
+			lvl.ForEachObject(func(level.ObjectID, level.ObjectMasterEntry) { used++ })
+			return fmt.Sprintf("All Objects -- %d/%d", used, int(lvl.ObjectLimit()))
+		}
+		class := object.Class(table)
+		active, limit := lvl.ObjectClassStats(class)
+		return fmt.Sprintf("%2d: %v -- %d/%d", table, class, active, limit)
+	}
+	selectedTable := view.model.selectedCapacityTable
+	if imgui.BeginCombo("Object Table", tableString(selectedTable)) {
+		if imgui.SelectableV(tableString(capacityMasterTable), selectedTable == capacityMasterTable, 0, imgui.Vec2{}) {
+			view.selectCapacityTable(capacityMasterTable)
+		}
+		for _, class := range object.Classes() {
+			if imgui.SelectableV(tableString(int(class)), int(class) == selectedTable, 0, imgui.Vec2{}) {
+				view.selectCapacityTable(int(class))
+			}
+		}
+		imgui.EndCombo()
+	}
+
+	current, minimum, vanilla := view.capacityInfo(lvl, selectedTable)
+	if view.model.capacityLimit < 1 {
+		view.model.capacityLimit = current
+	}
+	if readOnly {
+		imgui.LabelText("Capacity", fmt.Sprintf("%d", current))
+	} else {
+		gui.StepSliderIntV("Capacity", &view.model.capacityLimit, minimum, capacityMaximum, "%d")
+		if view.model.capacityLimit != current {
+			if imgui.Button("Apply Capacity") {
+				view.requestSetCapacity(lvl, selectedTable, view.model.capacityLimit)
+			}
+			imgui.SameLine()
+		}
+		if (current != vanilla) && (minimum <= vanilla) {
+			if imgui.Button("Reset to Default") {
+				view.requestSetCapacity(lvl, selectedTable, vanilla)
+			}
+		}
+	}
+	if (view.model.capacityLimit > vanilla) || (current > vanilla) {
+		imgui.Text(fmt.Sprintf("Warning: The original engine supports up to %d entries.", vanilla))
+		imgui.Text("Larger tables require an engine that supports them.")
+	}
+	if selectedTable == int(object.ClassSmallStuff) {
+		imgui.Text(fmt.Sprintf("The engine requires %d free entries to back up the inventory.", level.InventorySize))
+	}
+	if len(view.model.capacityMessage) > 0 {
+		imgui.Text(view.model.capacityMessage)
+	}
+}
+
+func (view *ControlView) selectCapacityTable(table int) {
+	view.model.selectedCapacityTable = table
+	view.model.capacityLimit = 0
+	view.model.capacityMessage = ""
+}
+
+// capacityInfo returns the current limit of the table, the lowest limit it can be shrunk to,
+// and the limit of the original engine.
+func (view *ControlView) capacityInfo(lvl *level.Level, table int) (current, minimum, vanilla int) {
+	if table == capacityMasterTable {
+		minimum = 1
+		lvl.ForEachObject(func(id level.ObjectID, entry level.ObjectMasterEntry) {
+			if int(id) > minimum {
+				minimum = int(id)
+			}
+		})
+		return int(lvl.ObjectLimit()), minimum, int(level.DefaultObjectLimit)
+	}
+	class := object.Class(table)
+	active, limit := lvl.ObjectClassStats(class)
+	minimum = active
+	if minimum < 1 {
+		minimum = 1
+	}
+	return limit, minimum, level.ObjectClassInfoFor(class).EntryCount - 1
+}
+
+func (view *ControlView) requestSetCapacity(lvl *level.Level, table int, limit int) {
+	var err error
+	if table == capacityMasterTable {
+		err = lvl.SetObjectLimit(level.ObjectID(limit))
+	} else {
+		err = lvl.SetObjectClassLimit(object.Class(table), limit)
+	}
+	if err != nil {
+		view.model.capacityMessage = fmt.Sprintf("Could not change capacity: %v", err)
+		return
+	}
+	view.model.capacityMessage = ""
+	view.setLevelResources(lvl, func() {
+		view.model.selectedCapacityTable = table
+		view.model.capacityLimit = 0
+	})
+}
+
+// setLevelResources stores the complete state of the level, allowing resources to change their size.
+// The state of any further given levels is stored with the same command, so that they are undone together.
+// Resources that were not part of the mod before are removed again on undo.
+func (view *ControlView) setLevelResources(lvl *level.Level, extraRestoreState func(), others ...*level.Level) {
+	command := cmd.SetResourcesCommand{
+		RestoreState: func(bool) {
+			view.model.restoreFocus = true
+			view.setSelectedLevel(lvl.ID())
+			extraRestoreState()
+		},
+		OldData: make(map[resource.ID][]byte),
+		NewData: make(map[resource.ID][]byte),
+	}
+
+	for _, modified := range append([]*level.Level{lvl}, others...) {
+		newDataSet := modified.EncodeState()
+		for id, newData := range newDataSet {
+			if len(newData) > 0 {
+				resourceID := ids.LevelResourcesStart.Plus(lvlids.PerLevel*modified.ID() + id)
+				oldData := view.mod.ModifiedBlock(resource.LangAny, resourceID, 0)
+				if !bytes.Equal(oldData, newData) {
+					command.OldData[resourceID] = oldData
+					command.NewData[resourceID] = newData
+				}
+			}
+		}
+	}
+
+	view.commander.Queue(command)
+}
@@ -0,0 +1,79 @@
+package levels
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/imgui-go"
+)
+
+func (view *ControlView) renderObjectCompaction(lvl *level.Level, levels []*level.Level, readOnly bool) {
+	if !readOnly && imgui.Button("Compact Objects") {
+		view.requestCompactObjects(lvl, levels)
+	}
+	if len(view.model.compactionMessage) > 0 {
+		imgui.Text(view.model.compactionMessage)
+	}
+	result := view.model.compactionResult
+	if result == nil {
+		return
+	}
+	imgui.Text(fmt.Sprintf("%d objects renumbered, %d references cleared, %d references of other levels updated, %d class entries released.",
+		len(result.Mapping), len(result.ClearedReferences), len(result.UpdatedIncoming), result.ReleasedClassEntries))
+	if (len(result.Mapping) > 0) && imgui.TreeNodeV("Renumbered Objects", imgui.TreeNodeFlagsFramed) {
+		oldIDs := make([]level.ObjectID, 0, len(result.Mapping))
+		for oldID := range result.Mapping {
+			oldIDs = append(oldIDs, oldID)
+		}
+		sort.Slice(oldIDs, func(a, b int) bool { return oldIDs[a] < oldIDs[b] })
+		for _, oldID := range oldIDs {
+			imgui.Text(fmt.Sprintf("%3d -> %3d", int(oldID), int(result.Mapping[oldID])))
+		}
+		imgui.TreePop()
+	}
+	if (len(result.ClearedReferences) > 0) && imgui.TreeNodeV("Cleared References", imgui.TreeNodeFlagsFramed) {
+		for _, ref := range result.ClearedReferences {
+			imgui.Text(fmt.Sprintf("%3d: %s (was %d)", int(ref.Source), ref.Key, ref.ID))
+		}
+		imgui.TreePop()
+	}
+	if (len(result.UpdatedIncoming) > 0) && imgui.TreeNodeV("Updated References of Other Levels", imgui.TreeNodeFlagsFramed) {
+		for _, ref := range result.UpdatedIncoming {
+			imgui.Text(fmt.Sprintf("Level %2d, object %3d: %s = %d, now %d",
+				ref.Level, int(ref.Source), ref.Key, ref.ID, int(result.Mapping[level.ObjectID(ref.ID)])))
+		}
+		imgui.TreePop()
+	}
+}
+
+func (view *ControlView) requestCompactObjects(lvl *level.Level, levels []*level.Level) {
+	result, err := lvlobj.CompactObjects(lvl, levels)
+	if err != nil {
+		view.model.compactionResult = nil
+		view.model.compactionMessage = fmt.Sprintf("Could not compact objects: %v", err)
+		return
+	}
+	view.model.compactionResult = &result
+	view.model.compactionMessage = ""
+	var others []*level.Level
+	for _, ref := range result.UpdatedIncoming {
+		other := levels[ref.Level]
+		if !containsLevel(others, other) {
+			others = append(others, other)
+		}
+	}
+	view.setLevelResources(lvl, func() {
+		view.eventListener.Event(ObjectSelectionSetEvent{})
+	}, others...)
+}
+
+func containsLevel(levels []*level.Level, lvl *level.Level) bool {
+	for _, candidate := range levels {
+		if candidate == lvl {
+			return true
+		}
+	}
+	return false
+}
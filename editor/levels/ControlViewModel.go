@@ -1,12 +1,26 @@
 package levels
 
-import "github.com/inkyblackness/hacked/ss1/world"
+import (
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/hacked/ss1/world"
+)
 
 type controlViewModel struct {
 	selectedLevel                   int
 	selectedAtlasIndex              int
 	selectedSurveillanceObjectIndex int
 	selectedTextureAnimationIndex   int
+	selectedMapNoteIndex            int
+	selectedLoopConfigIndex         int
+	selectedScheduleIndex           int
+	selectedHeightSemaphoreIndex    int
+	selectedCapacityTable           int
+
+	capacityLimit   int
+	capacityMessage string
+
+	compactionResult  *lvlobj.CompactionResult
+	compactionMessage string
 
 	restoreFocus bool
 	windowOpen   bool
@@ -16,5 +30,10 @@ func freshControlViewModel() controlViewModel {
 	return controlViewModel{
 		selectedLevel:                 world.StartingLevel,
 		selectedTextureAnimationIndex: 1,
+		selectedMapNoteIndex:          -1,
+		selectedLoopConfigIndex:       -1,
+		selectedHeightSemaphoreIndex:  -1,
+		selectedScheduleIndex:         -1,
+		selectedCapacityTable:         capacityMasterTable,
 	}
 }
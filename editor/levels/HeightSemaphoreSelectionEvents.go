@@ -0,0 +1,6 @@
+package levels
+
+// HeightSemaphoreSelectionSetEvent notifies about the currently selected height semaphore.
+type HeightSemaphoreSelectionSetEvent struct {
+	index int
+}
@@ -0,0 +1,46 @@
+package levels
+
+import (
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+)
+
+// mapPatrolDrag keeps track of the patrol tile of a critter being dragged on the map.
+// Patrol tiles are always placed at the center of a tile.
+type mapPatrolDrag struct {
+	critter level.ObjectID
+	start   MapPosition
+	pos     MapPosition
+	active  bool
+	moved   bool
+}
+
+func (drag *mapPatrolDrag) begin(critter level.ObjectID, pos MapPosition) {
+	drag.critter = critter
+	drag.start = pos
+	drag.pos = pos
+	drag.active = true
+	drag.moved = false
+}
+
+func (drag *mapPatrolDrag) update(pos MapPosition) {
+	drag.pos = tileCenter(pos)
+	drag.moved = drag.pos != drag.start
+}
+
+func (drag *mapPatrolDrag) stop() {
+	drag.active = false
+}
+
+// critterPatrolPosition returns the map position of the patrol tile of the critter.
+// The position of a dragged patrol tile is that of the drag operation.
+func (drag *mapPatrolDrag) critterPatrolPosition(lvl *level.Level, critter level.ObjectID) (MapPosition, bool) {
+	if drag.active && (drag.critter == critter) {
+		return drag.pos, true
+	}
+	x, y, set := lvlobj.CritterPatrolTile(lvl, critter)
+	if !set {
+		return MapPosition{}, false
+	}
+	return MapPosition{X: level.CoordinateAt(byte(x), 0x80), Y: level.CoordinateAt(byte(y), 0x80)}, true
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/inkyblackness/hacked/editor/graphics"
 	"github.com/inkyblackness/hacked/editor/render"
 	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlrender"
 	"github.com/inkyblackness/hacked/ss1/content/object"
 	"github.com/inkyblackness/hacked/ss1/resource"
 	"github.com/inkyblackness/hacked/ss1/world/ids"
@@ -48,6 +50,32 @@ func (item objectHoverItem) Size() float32 {
 	return fineCoordinatesPerTileSide / 4
 }
 
+type mapNoteHoverItem struct {
+	index int
+	pos   MapPosition
+}
+
+func (item mapNoteHoverItem) Pos() MapPosition {
+	return item.pos
+}
+
+func (item mapNoteHoverItem) Size() float32 {
+	return fineCoordinatesPerTileSide / 2
+}
+
+type heightSemaphoreHoverItem struct {
+	index int
+	pos   MapPosition
+}
+
+func (item heightSemaphoreHoverItem) Pos() MapPosition {
+	return item.pos
+}
+
+func (item heightSemaphoreHoverItem) Size() float32 {
+	return fineCoordinatesPerTileSide / 2
+}
+
 // MapDisplay renders a level map.
 type MapDisplay struct {
 	context  render.Context
@@ -61,17 +89,29 @@ type MapDisplay struct {
 	colors      *MapColors
 	mapGrid     *MapGrid
 	highlighter *Highlighter
+	links       *MapLinks
 	icons       *MapIcons
 
 	moveCapture func(pixelX, pixelY float32)
 	mouseMoved  bool
 
+	activeTool  MapTool
+	painting    bool
+	paintStroke []MapPosition
+	ruler       mapRuler
+	drag        mapObjectDrag
+	rotation    mapObjectRotation
+	patrol      mapPatrolDrag
+	snap        MapSnap
+
 	positionPopupPos imgui.Vec2
 	positionValid    bool
 	position         MapPosition
 
-	selectedTiles   tileCoordinates
-	selectedObjects objectIDs
+	selectedTiles           tileCoordinates
+	selectedObjects         objectIDs
+	selectedMapNote         int
+	selectedHeightSemaphore int
 
 	activeLevel         *level.Level
 	availableHoverItems []hoverItem
@@ -101,6 +141,9 @@ func NewMapDisplay(gl opengl.OpenGL, guiScale float32,
 		guiScale:      guiScale,
 		eventListener: eventListener,
 		moveCapture:   func(float32, float32) {},
+
+		selectedMapNote:         -1,
+		selectedHeightSemaphore: -1,
 	}
 	display.context.ViewMatrix = display.camera.ViewMatrix()
 	display.background = NewBackgroundGrid(&display.context)
@@ -108,6 +151,7 @@ func NewMapDisplay(gl opengl.OpenGL, guiScale float32,
 	display.colors = NewMapColors(&display.context)
 	display.mapGrid = NewMapGrid(&display.context)
 	display.highlighter = NewHighlighter(&display.context)
+	display.links = NewMapLinks(&display.context)
 	display.icons = NewMapIcons(&display.context)
 
 	centerX, centerY := (tilesPerMapSide*tileBaseLength)/-2.0, (tilesPerMapSide*tileBaseLength)/-2.0
@@ -117,6 +161,8 @@ func NewMapDisplay(gl opengl.OpenGL, guiScale float32,
 	display.selectedTiles.registerAt(eventRegistry)
 	display.selectedObjects.registerAt(eventRegistry)
 	eventRegistry.RegisterHandler(display.onLevelSelectionSetEvent)
+	eventRegistry.RegisterHandler(display.onMapNoteSelectionSetEvent)
+	eventRegistry.RegisterHandler(display.onHeightSemaphoreSelectionSetEvent)
 
 	return display
 }
@@ -124,31 +170,18 @@ func NewMapDisplay(gl opengl.OpenGL, guiScale float32,
 // Render renders the whole map display.
 func (display *MapDisplay) Render(properties object.PropertiesTable, lvl *level.Level,
 	paletteTexture *graphics.PaletteTexture, textureRetriever func(resource.Key) (*graphics.BitmapTexture, error),
-	textureDisplay TextureDisplay, colorDisplay ColorDisplay) {
+	textureDisplay TextureDisplay, colorQuery ColorQuery, tool MapTool, snap MapSnap, linkFilter ObjectLinkFilter) {
 	columns, rows, _ := lvl.Size()
 
 	display.selectedObjects.filterInvalid(lvl)
 
 	display.activeLevel = lvl
+	display.activeTool = tool
+	display.snap = snap
 	display.background.Render()
 	if lvl.IsCyberspace() {
-		if paletteTexture != nil {
-			var colorQuery ColorQuery
-			palette := paletteTexture.Palette()
-			if colorDisplay == ColorDisplayFloor {
-				colorQuery = display.colorQueryFor(lvl, func(tile *level.TileMapEntry) [4]float32 {
-					rgb := palette[tile.TextureInfo.FloorPaletteIndex()]
-					return [4]float32{float32(rgb.Red) / 255, float32(rgb.Green) / 255, float32(rgb.Blue) / 255, 0.8}
-				})
-			} else if colorDisplay == ColorDisplayCeiling {
-				colorQuery = display.colorQueryFor(lvl, func(tile *level.TileMapEntry) [4]float32 {
-					rgb := palette[tile.TextureInfo.CeilingPaletteIndex()]
-					return [4]float32{float32(rgb.Red) / 255, float32(rgb.Green) / 255, float32(rgb.Blue) / 255, 0.8}
-				})
-			}
-			if colorQuery != nil {
-				display.colors.Render(columns, rows, colorQuery)
-			}
+		if (paletteTexture != nil) && (colorQuery != nil) {
+			display.colors.Render(columns, rows, colorQuery)
 		}
 	} else {
 		if paletteTexture != nil {
@@ -167,16 +200,6 @@ func (display *MapDisplay) Render(properties object.PropertiesTable, lvl *level.
 			}, paletteTexture)
 		}
 
-		var colorQuery ColorQuery
-		if colorDisplay == ColorDisplayFloor {
-			colorQuery = display.colorQueryFor(lvl, func(tile *level.TileMapEntry) [4]float32 {
-				return [4]float32{0.0, 0.0, 0.0, float32(tile.Flags.ForRealWorld().FloorShadow()) / 15.0}
-			})
-		} else if colorDisplay == ColorDisplayCeiling {
-			colorQuery = display.colorQueryFor(lvl, func(tile *level.TileMapEntry) [4]float32 {
-				return [4]float32{0.0, 0.0, 0.0, float32(tile.Flags.ForRealWorld().CeilingShadow()) / 15.0}
-			})
-		}
 		if colorQuery != nil {
 			display.colors.Render(columns, rows, colorQuery)
 		}
@@ -190,6 +213,12 @@ func (display *MapDisplay) Render(properties object.PropertiesTable, lvl *level.
 		}
 	}
 	display.highlighter.Render(display.selectedTiles.list, fineCoordinatesPerTileSide, [4]float32{0.0, 0.8, 0.2, 0.5})
+	if display.painting {
+		display.highlighter.Render(display.paintStroke, fineCoordinatesPerTileSide, [4]float32{1.0, 0.5, 0.0, 0.5})
+	}
+	if (display.activeTool == MapToolRuler) && display.ruler.valid {
+		display.highlighter.Render(display.ruler.markers(), fineCoordinatesPerTileSide/8, [4]float32{0.0, 0.8, 1.0, 0.8})
+	}
 	{
 		var objects []MapPosition
 		lvl.ForEachObject(func(id level.ObjectID, entry level.ObjectMasterEntry) {
@@ -197,23 +226,11 @@ func (display *MapDisplay) Render(properties object.PropertiesTable, lvl *level.
 		})
 		display.highlighter.Render(objects, fineCoordinatesPerTileSide/4, [4]float32{1.0, 1.0, 1.0, 0.3})
 	}
+	display.renderObjectLinks(lvl, linkFilter)
+	display.renderFacingIndicators(lvl)
+	display.renderCritterPatrols(lvl)
 	if paletteTexture != nil {
-		tripleOffsets := make(map[object.Triple]int)
-
-		{
-			offset := 0
-			properties.Iterate(func(triple object.Triple, prop *object.Properties) bool {
-				numExtra := prop.Common.Bitmap3D >> 12
-
-				if triple.Class != object.ClassTrap {
-					tripleOffsets[triple] = offset + 2
-				} else {
-					tripleOffsets[triple] = offset
-				}
-				offset += 3 + int(numExtra)
-				return true
-			})
-		}
+		tripleOffsets := lvlrender.ObjectBitmapOffsets(properties)
 		var icons []iconData
 		var highlightIcon iconData
 		var highlightID level.ObjectID
@@ -256,11 +273,198 @@ func (display *MapDisplay) Render(properties object.PropertiesTable, lvl *level.
 		}
 		display.highlighter.Render(selectedObjectHighlights, fineCoordinatesPerTileSide/4, [4]float32{0.0, 0.8, 0.2, 0.5})
 	}
+	if display.drag.active && display.drag.moved {
+		display.highlighter.Render(display.drag.positions(), fineCoordinatesPerTileSide/4, [4]float32{1.0, 0.5, 0.0, 0.6})
+	}
+	{
+		notes := lvl.MapNotes()
+		notePositions := make([]MapPosition, 0, len(notes))
+		var selectedNotePositions []MapPosition
+		for index, note := range notes {
+			pos := mapNotePosition(note)
+			if index == display.selectedMapNote {
+				selectedNotePositions = append(selectedNotePositions, pos)
+			} else {
+				notePositions = append(notePositions, pos)
+			}
+		}
+		display.highlighter.Render(notePositions, fineCoordinatesPerTileSide/2, [4]float32{1.0, 0.8, 0.0, 0.4})
+		display.highlighter.Render(selectedNotePositions, fineCoordinatesPerTileSide/2, [4]float32{1.0, 0.8, 0.0, 0.8})
+	}
+	if !lvl.IsCyberspace() {
+		var floorPositions []MapPosition
+		var ceilingPositions []MapPosition
+		var selectedPositions []MapPosition
+		for index, sem := range lvl.HeightSemaphores() {
+			pos := heightSemaphorePosition(sem)
+			if index == display.selectedHeightSemaphore {
+				selectedPositions = append(selectedPositions, pos)
+			} else if !sem.IsInUse() {
+				continue
+			} else if sem.IsFloor() {
+				floorPositions = append(floorPositions, pos)
+			} else {
+				ceilingPositions = append(ceilingPositions, pos)
+			}
+		}
+		display.highlighter.Render(floorPositions, fineCoordinatesPerTileSide*3/4, [4]float32{0.8, 0.0, 0.8, 0.4})
+		display.highlighter.Render(ceilingPositions, fineCoordinatesPerTileSide*3/4, [4]float32{0.0, 0.8, 0.8, 0.4})
+		display.highlighter.Render(selectedPositions, fineCoordinatesPerTileSide, [4]float32{0.8, 0.8, 0.8, 0.6})
+	}
 	if display.activeHoverItem != nil {
 		display.highlighter.Render([]MapPosition{display.activeHoverItem.Pos()}, display.activeHoverItem.Size(), [4]float32{0.0, 0.2, 0.8, 0.3})
 	}
 
 	display.renderPositionOverlay(lvl)
+	if display.activeTool == MapToolRuler {
+		rulerPos := display.positionPopupPos
+		rulerPos.Y -= 110 * display.guiScale
+		display.ruler.renderOverlay(lvl, rulerPos, display.guiScale)
+	}
+}
+
+func (display *MapDisplay) renderObjectLinks(lvl *level.Level, filter ObjectLinkFilter) {
+	var links []MapLink
+	var selectedLinks []MapLink
+	for _, link := range objectLinks(lvl, filter) {
+		from := lvl.Object(link.from)
+		to := lvl.Object(link.to)
+		mapLink := MapLink{From: MapPosition{X: from.X, Y: from.Y}, To: MapPosition{X: to.X, Y: to.Y}}
+		if display.selectedObjects.contains(link.from) || display.selectedObjects.contains(link.to) {
+			selectedLinks = append(selectedLinks, mapLink)
+		} else {
+			links = append(links, mapLink)
+		}
+	}
+	display.links.Render(links, fineCoordinatesPerTileSide/4, [4]float32{1.0, 0.6, 0.0, 0.6})
+	display.links.Render(selectedLinks, fineCoordinatesPerTileSide/4, [4]float32{0.2, 1.0, 0.4, 0.9})
+}
+
+// renderFacingIndicators draws the heading of objects for which the orientation is typically relevant,
+// as well as of all selected objects. While the rotation tool is active, a handle is shown for the selection.
+func (display *MapDisplay) renderFacingIndicators(lvl *level.Level) {
+	indicatorLength := fineCoordinatesPerTileSide / 3
+	headLength := fineCoordinatesPerTileSide / 10
+	var indicators []MapLink
+	var selectedIndicators []MapLink
+	lvl.ForEachObject(func(id level.ObjectID, entry level.ObjectMasterEntry) {
+		pos := MapPosition{X: entry.X, Y: entry.Y}
+		heading := entry.ZRotation
+		if display.selectedObjects.contains(id) {
+			if display.rotation.active {
+				heading = display.rotation.heading
+			}
+			selectedIndicators = append(selectedIndicators, facingLink(pos, heading, indicatorLength))
+		} else if (entry.Class == object.ClassCritter) || (entry.Class == object.ClassBigStuff) || (entry.Class == object.ClassFixture) {
+			indicators = append(indicators, facingLink(pos, heading, indicatorLength))
+		}
+	})
+	display.links.Render(indicators, headLength, [4]float32{1.0, 1.0, 1.0, 0.5})
+	display.links.Render(selectedIndicators, headLength, [4]float32{0.0, 0.8, 0.2, 0.9})
+
+	if display.activeTool != MapToolRotate {
+		return
+	}
+	pivotID, pivot, heading, available := display.rotationPivot()
+	if !available {
+		return
+	}
+	if display.rotation.active && (display.rotation.objects[0] == pivotID) {
+		heading = display.rotation.heading
+	}
+	handle := facingLink(pivot, heading, fineCoordinatesPerTileSide)
+	display.links.Render([]MapLink{handle}, fineCoordinatesPerTileSide/6, [4]float32{0.0, 0.8, 1.0, 0.9})
+	display.highlighter.Render([]MapPosition{handle.To}, fineCoordinatesPerTileSide/8, [4]float32{0.0, 0.8, 1.0, 0.8})
+}
+
+// rotationPivot returns the first selected object, around which the rotation handle is shown.
+func (display *MapDisplay) rotationPivot() (level.ObjectID, MapPosition, level.RotationUnit, bool) {
+	if display.activeLevel == nil {
+		return 0, MapPosition{}, 0, false
+	}
+	for _, id := range display.selectedObjects.list {
+		obj := display.activeLevel.Object(id)
+		if (obj != nil) && (obj.InUse != 0) {
+			return id, MapPosition{X: obj.X, Y: obj.Y}, obj.ZRotation, true
+		}
+	}
+	return 0, MapPosition{}, 0, false
+}
+
+// startObjectRotation starts to rotate all selected objects, with the first one being the pivot.
+func (display *MapDisplay) startObjectRotation() bool {
+	pivotID, pivot, heading, available := display.rotationPivot()
+	if !display.positionValid || !available {
+		return false
+	}
+	objects := []level.ObjectID{pivotID}
+	for _, id := range display.selectedObjects.list {
+		if id != pivotID {
+			objects = append(objects, id)
+		}
+	}
+	display.rotation.start(objects, pivot, heading)
+	return true
+}
+
+// renderCritterPatrols draws the patrol route of each selected critter, which leads from its position to the tile
+// it patrols to. Critters store no further waypoints.
+// While the patrol tool is active, the patrol tiles are highlighted.
+func (display *MapDisplay) renderCritterPatrols(lvl *level.Level) {
+	var links []MapLink
+	var markers []MapPosition
+	for _, id := range display.selectedObjects.list {
+		if !lvlobj.IsCritter(lvl, id) {
+			continue
+		}
+		pos, set := display.patrol.critterPatrolPosition(lvl, id)
+		if !set {
+			continue
+		}
+		obj := lvl.Object(id)
+		links = append(links, MapLink{From: MapPosition{X: obj.X, Y: obj.Y}, To: pos})
+		markers = append(markers, pos)
+	}
+	display.links.Render(links, fineCoordinatesPerTileSide/4, [4]float32{0.9, 0.2, 0.9, 0.8})
+	alpha := float32(0.4)
+	if display.activeTool == MapToolPatrol {
+		alpha = 0.8
+	}
+	display.highlighter.Render(markers, fineCoordinatesPerTileSide/3, [4]float32{0.9, 0.2, 0.9, alpha})
+}
+
+// patrolCritter returns the first selected object if it is a critter. This is the critter the patrol tool edits.
+func (display *MapDisplay) patrolCritter() (level.ObjectID, bool) {
+	if (display.activeLevel == nil) || (len(display.selectedObjects.list) == 0) {
+		return 0, false
+	}
+	id := display.selectedObjects.list[0]
+	return id, lvlobj.IsCritter(display.activeLevel, id)
+}
+
+// startPatrolDrag starts to drag the patrol tile of the patrol critter if it is close to the current position.
+func (display *MapDisplay) startPatrolDrag() bool {
+	critter, available := display.patrolCritter()
+	if !display.positionValid || !available {
+		return false
+	}
+	pos, set := display.patrol.critterPatrolPosition(display.activeLevel, critter)
+	refVec := mgl.Vec2{float32(display.position.X), float32(display.position.Y)}
+	if !set || (refVec.Sub(mgl.Vec2{float32(pos.X), float32(pos.Y)}).Len() >= fineCoordinatesPerTileSide/3) {
+		return false
+	}
+	display.patrol.begin(critter, pos)
+	return true
+}
+
+// placeCritterPatrol requests to place the patrol tile of the patrol critter at the current position.
+func (display *MapDisplay) placeCritterPatrol() bool {
+	critter, available := display.patrolCritter()
+	if !display.positionValid || !available {
+		return false
+	}
+	display.eventListener.Event(CritterPatrolRequestEvent{Critter: critter, Pos: tileCenter(display.position)})
+	return true
 }
 
 func (display *MapDisplay) nearestHoverItems(lvl *level.Level, ref MapPosition) []hoverItem {
@@ -277,6 +481,21 @@ func (display *MapDisplay) nearestHoverItems(lvl *level.Level, ref MapPosition)
 			distances = append(distances, distance)
 		}
 	})
+	for index, note := range lvl.MapNotes() {
+		if (note.X == ref.X.Tile()) && (note.Y == ref.Y.Tile()) {
+			pos := mapNotePosition(note)
+			items = append(items, mapNoteHoverItem{index: index, pos: pos})
+			distances = append(distances, refVec.Sub(mgl.Vec2{float32(pos.X), float32(pos.Y)}).Len())
+		}
+	}
+	if !lvl.IsCyberspace() {
+		for index, sem := range lvl.HeightSemaphores() {
+			if sem.IsInUse() && (sem.X == ref.X.Tile()) && (sem.Y == ref.Y.Tile()) {
+				items = append(items, heightSemaphoreHoverItem{index: index, pos: heightSemaphorePosition(sem)})
+				distances = append(distances, fineCoordinatesPerTileSide*3/4)
+			}
+		}
+	}
 	items = append(items, tileHoverItem{pos: MapPosition{
 		X: level.CoordinateAt(ref.X.Tile(), 128),
 		Y: level.CoordinateAt(ref.Y.Tile(), 128),
@@ -288,14 +507,12 @@ func (display *MapDisplay) nearestHoverItems(lvl *level.Level, ref MapPosition)
 	return items
 }
 
-func (display *MapDisplay) colorQueryFor(lvl *level.Level, tileToColor func(*level.TileMapEntry) [4]float32) func(int, int) [4]float32 {
-	return func(x, y int) [4]float32 {
-		tile := lvl.Tile(x, y)
-		if tile == nil {
-			return [4]float32{}
-		}
-		return tileToColor(tile)
-	}
+func mapNotePosition(note level.MapNote) MapPosition {
+	return MapPosition{X: level.CoordinateAt(note.X, 128), Y: level.CoordinateAt(note.Y, 128)}
+}
+
+func heightSemaphorePosition(sem level.HeightSemaphore) MapPosition {
+	return MapPosition{X: level.CoordinateAt(sem.X, 128), Y: level.CoordinateAt(sem.Y, 128)}
 }
 
 func (display *MapDisplay) renderPositionOverlay(lvl *level.Level) {
@@ -353,6 +570,18 @@ func (display *MapDisplay) renderPositionOverlay(lvl *level.Level) {
 					floorRaw = int(obj.Z)
 					hasFloor = true
 				}
+			} else if noteItem, isNoteItem := display.activeHoverItem.(mapNoteHoverItem); isNoteItem {
+				notes := lvl.MapNotes()
+				if noteItem.index < len(notes) {
+					typeString = "Note: " + notes[noteItem.index].Text
+				}
+			} else if semItem, isSemItem := display.activeHoverItem.(heightSemaphoreHoverItem); isSemItem {
+				sem := lvl.HeightSemaphores()[semItem.index]
+				part := "Ceiling"
+				if sem.IsFloor() {
+					part = "Floor"
+				}
+				typeString = fmt.Sprintf("Semaphore %d: %s x%d", semItem.index, part, sem.UseCount())
 			}
 		}
 		imgui.Text("T: " + typeString)
@@ -397,7 +626,38 @@ func (display *MapDisplay) unprojectPixel(pixelX, pixelY float32) (x, y float32)
 // MouseButtonDown must be called when a button was pressed.
 func (display *MapDisplay) MouseButtonDown(mouseX, mouseY float32, button uint32) {
 	display.updateMouseWorldPosition(mouseX, mouseY)
-	if button == input.MousePrimary {
+	if (button == input.MousePrimary) && display.activeTool.IsPainting() {
+		if display.positionValid && (display.activeLevel != nil) {
+			display.startPaintStroke()
+		}
+	} else if (button == input.MousePrimary) && (display.activeTool == MapToolRuler) {
+		if display.positionValid {
+			display.ruler.start(display.position)
+			display.moveCapture = func(float32, float32) {
+				if display.positionValid {
+					display.ruler.to = display.position
+				}
+			}
+		}
+	} else if (button == input.MousePrimary) && (display.activeTool == MapToolRotate) && display.startObjectRotation() {
+		display.moveCapture = func(float32, float32) {
+			if display.positionValid {
+				display.rotation.update(display.position)
+			}
+		}
+	} else if (button == input.MousePrimary) && (display.activeTool == MapToolPatrol) && display.startPatrolDrag() {
+		display.moveCapture = func(float32, float32) {
+			if display.positionValid {
+				display.patrol.update(display.position)
+			}
+		}
+	} else if (button == input.MousePrimary) && (display.activeTool == MapToolMove) && display.startObjectDrag() {
+		display.moveCapture = func(float32, float32) {
+			if display.positionValid {
+				display.drag.update(display.position, display.snap)
+			}
+		}
+	} else if button == input.MousePrimary {
 		lastPixelX, lastPixelY := mouseX, mouseY
 
 		display.mouseMoved = false
@@ -415,7 +675,33 @@ func (display *MapDisplay) MouseButtonDown(mouseX, mouseY float32, button uint32
 // MouseButtonUp must be called when a button was released.
 func (display *MapDisplay) MouseButtonUp(mouseX, mouseY float32, button uint32, modifier input.Modifier) {
 	display.updateMouseWorldPosition(mouseX, mouseY)
-	if button == input.MousePrimary {
+	if (button == input.MousePrimary) && display.painting {
+		display.moveCapture = func(float32, float32) {}
+		display.finishPaintStroke()
+	} else if (button == input.MousePrimary) && (display.activeTool == MapToolRuler) {
+		display.moveCapture = func(float32, float32) {}
+	} else if (button == input.MousePrimary) && display.rotation.active {
+		display.moveCapture = func(float32, float32) {}
+		display.rotation.stop()
+		if display.rotation.changed {
+			display.eventListener.Event(ObjectRotateRequestEvent{Objects: display.rotation.objects, Heading: display.rotation.heading})
+		}
+	} else if (button == input.MousePrimary) && display.drag.active {
+		display.moveCapture = func(float32, float32) {}
+		display.drag.stop()
+		if display.drag.moved {
+			display.eventListener.Event(ObjectMoveRequestEvent{Objects: display.drag.objects, Positions: display.drag.positions()})
+		}
+	} else if (button == input.MousePrimary) && display.patrol.active {
+		display.moveCapture = func(float32, float32) {}
+		display.patrol.stop()
+		if display.patrol.moved {
+			display.eventListener.Event(CritterPatrolRequestEvent{Critter: display.patrol.critter, Pos: display.patrol.pos})
+		}
+	} else if (button == input.MousePrimary) && (display.activeTool == MapToolPatrol) && !display.mouseMoved &&
+		display.placeCritterPatrol() {
+		display.moveCapture = func(float32, float32) {}
+	} else if button == input.MousePrimary {
 		display.moveCapture = func(float32, float32) {}
 		if !display.mouseMoved && display.positionValid {
 			if modifier.Has(input.ModControl) {
@@ -470,6 +756,160 @@ func (display *MapDisplay) MouseButtonUp(mouseX, mouseY float32, button uint32,
 	}
 }
 
+// startObjectDrag starts to drag objects if an object is hovered.
+// A hovered object that is not selected becomes the only selected one.
+func (display *MapDisplay) startObjectDrag() bool {
+	objectItem, isObject := display.activeHoverItem.(objectHoverItem)
+	if !display.positionValid || !isObject || (display.activeLevel == nil) {
+		return false
+	}
+	objects := display.selectedObjects.list
+	if !display.selectedObjects.contains(objectItem.id) {
+		objects = []level.ObjectID{objectItem.id}
+		display.eventListener.Event(ObjectSelectionSetEvent{objects: objects})
+	}
+	display.drag.start(display.activeLevel, objects, objectItem.pos)
+	return display.drag.active
+}
+
+func (display *MapDisplay) startPaintStroke() {
+	startPos := tileCenter(display.position)
+	display.painting = true
+	display.paintStroke = []MapPosition{startPos}
+	switch display.activeTool {
+	case MapToolBrush:
+		lastPos := startPos
+		display.moveCapture = func(float32, float32) {
+			if !display.positionValid {
+				return
+			}
+			pos := tileCenter(display.position)
+			if pos == lastPos {
+				return
+			}
+			for _, linePos := range tileLine(lastPos, pos) {
+				if !containsMapPosition(display.paintStroke, linePos) {
+					display.paintStroke = append(display.paintStroke, linePos)
+				}
+			}
+			lastPos = pos
+		}
+	case MapToolRectangle:
+		display.moveCapture = func(float32, float32) {
+			if display.positionValid {
+				display.paintStroke = tileRectangle(startPos, tileCenter(display.position))
+			}
+		}
+	case MapToolFloodFill:
+		display.paintStroke = floodFillTiles(display.activeLevel, startPos)
+		display.moveCapture = func(float32, float32) {}
+	}
+}
+
+func (display *MapDisplay) finishPaintStroke() {
+	if len(display.paintStroke) > 0 {
+		display.eventListener.Event(TilePaintRequestEvent{Tiles: display.paintStroke})
+	}
+	display.painting = false
+	display.paintStroke = nil
+}
+
+func tileCenter(pos MapPosition) MapPosition {
+	return MapPosition{X: level.CoordinateAt(pos.X.Tile(), 128), Y: level.CoordinateAt(pos.Y.Tile(), 128)}
+}
+
+func tileRectangle(from, to MapPosition) []MapPosition {
+	fromX, toX := int(from.X.Tile()), int(to.X.Tile())
+	fromY, toY := int(from.Y.Tile()), int(to.Y.Tile())
+	if fromX > toX {
+		fromX, toX = toX, fromX
+	}
+	if fromY > toY {
+		fromY, toY = toY, fromY
+	}
+	list := make([]MapPosition, 0, (toX-fromX+1)*(toY-fromY+1))
+	for y := fromY; y <= toY; y++ {
+		for x := fromX; x <= toX; x++ {
+			list = append(list, MapPosition{X: level.CoordinateAt(byte(x), 128), Y: level.CoordinateAt(byte(y), 128)})
+		}
+	}
+	return list
+}
+
+// tileLine returns the tiles on the line between the two positions, including both ends.
+// Consecutive tiles of the line share at least a corner, so fast strokes leave no gaps.
+func tileLine(from, to MapPosition) []MapPosition {
+	x, y := int(from.X.Tile()), int(from.Y.Tile())
+	toX, toY := int(to.X.Tile()), int(to.Y.Tile())
+	dx, dy := toX-x, toY-y
+	stepX, stepY := 1, 1
+	if dx < 0 {
+		dx, stepX = -dx, -1
+	}
+	if dy < 0 {
+		dy, stepY = -dy, -1
+	}
+	list := make([]MapPosition, 0, dx+dy+1)
+	err := dx - dy
+	for {
+		list = append(list, MapPosition{X: level.CoordinateAt(byte(x), 128), Y: level.CoordinateAt(byte(y), 128)})
+		if (x == toX) && (y == toY) {
+			return list
+		}
+		doubled := 2 * err
+		if doubled > -dy {
+			err -= dy
+			x += stepX
+		}
+		if doubled < dx {
+			err += dx
+			y += stepY
+		}
+	}
+}
+
+func containsMapPosition(list []MapPosition, pos MapPosition) bool {
+	for _, entry := range list {
+		if entry == pos {
+			return true
+		}
+	}
+	return false
+}
+
+// floodFillTiles returns all tiles connected to the start position that share its solidity.
+// Starting on an open tile, the fill is bounded by solid tiles.
+func floodFillTiles(lvl *level.Level, start MapPosition) []MapPosition {
+	startTile := lvl.Tile(int(start.X.Tile()), int(start.Y.Tile()))
+	if startTile == nil {
+		return nil
+	}
+	startSolid := startTile.Type == level.TileTypeSolid
+	type coord struct{ x, y int }
+	visited := make(map[coord]bool)
+	pending := []coord{{x: int(start.X.Tile()), y: int(start.Y.Tile())}}
+	visited[pending[0]] = true
+	var list []MapPosition
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+		list = append(list, MapPosition{X: level.CoordinateAt(byte(current.x), 128), Y: level.CoordinateAt(byte(current.y), 128)})
+		for _, next := range []coord{
+			{x: current.x - 1, y: current.y}, {x: current.x + 1, y: current.y},
+			{x: current.x, y: current.y - 1}, {x: current.x, y: current.y + 1}} {
+			if visited[next] {
+				continue
+			}
+			tile := lvl.Tile(next.x, next.y)
+			if (tile != nil) && ((tile.Type == level.TileTypeSolid) == startSolid) {
+				visited[next] = true
+				pending = append(pending, next)
+			}
+		}
+	}
+	return list
+}
+
 func (display *MapDisplay) setSelectionByActiveHoverItem() {
 	var tiles []MapPosition
 	var objects []level.ObjectID
@@ -478,6 +918,12 @@ func (display *MapDisplay) setSelectionByActiveHoverItem() {
 			tiles = append(tiles, tileItem.pos)
 		} else if objectItem, isObject := display.activeHoverItem.(objectHoverItem); isObject {
 			objects = append(objects, objectItem.id)
+		} else if noteItem, isNote := display.activeHoverItem.(mapNoteHoverItem); isNote {
+			tiles = append(tiles, noteItem.pos)
+			display.eventListener.Event(MapNoteSelectionSetEvent{index: noteItem.index})
+		} else if semItem, isSem := display.activeHoverItem.(heightSemaphoreHoverItem); isSem {
+			tiles = append(tiles, semItem.pos)
+			display.eventListener.Event(HeightSemaphoreSelectionSetEvent{index: semItem.index})
 		}
 	}
 	display.eventListener.Event(TileSelectionSetEvent{tiles: tiles})
@@ -582,4 +1028,16 @@ func (display *MapDisplay) resetHoverItems() {
 
 func (display *MapDisplay) onLevelSelectionSetEvent(evt LevelSelectionSetEvent) {
 	display.resetHoverItems()
+	if (display.activeLevel == nil) || (display.activeLevel.ID() != evt.id) {
+		display.selectedMapNote = -1
+		display.selectedHeightSemaphore = -1
+	}
+}
+
+func (display *MapDisplay) onHeightSemaphoreSelectionSetEvent(evt HeightSemaphoreSelectionSetEvent) {
+	display.selectedHeightSemaphore = evt.index
+}
+
+func (display *MapDisplay) onMapNoteSelectionSetEvent(evt MapNoteSelectionSetEvent) {
+	display.selectedMapNote = evt.index
 }
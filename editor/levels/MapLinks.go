@@ -0,0 +1,134 @@
+package levels
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/inkyblackness/hacked/editor/render"
+	"github.com/inkyblackness/hacked/ui/opengl"
+)
+
+var mapLinksVertexShaderSource = `
+#version 150
+precision mediump float;
+
+in vec3 vertexPosition;
+
+uniform mat4 viewMatrix;
+uniform mat4 projectionMatrix;
+
+void main(void) {
+	gl_Position = projectionMatrix * viewMatrix * vec4(vertexPosition, 1.0);
+}
+`
+
+var mapLinksFragmentShaderSource = `
+#version 150
+precision mediump float;
+
+uniform vec4 inColor;
+out vec4 fragColor;
+
+void main(void) {
+	fragColor = inColor;
+}
+`
+
+// MapLink is a directed line between two positions on the map.
+type MapLink struct {
+	From MapPosition
+	To   MapPosition
+}
+
+// MapLinks draws arrows between positions of the map.
+type MapLinks struct {
+	context *render.Context
+
+	program                 uint32
+	vao                     *opengl.VertexArrayObject
+	vertexPositionBuffer    uint32
+	vertexPositionAttrib    int32
+	viewMatrixUniform       opengl.Matrix4Uniform
+	projectionMatrixUniform opengl.Matrix4Uniform
+	inColorUniform          opengl.Vector4Uniform
+}
+
+// NewMapLinks returns a new instance of MapLinks.
+func NewMapLinks(context *render.Context) *MapLinks {
+	gl := context.OpenGL
+	program, programErr := opengl.LinkNewStandardProgram(gl, mapLinksVertexShaderSource, mapLinksFragmentShaderSource)
+
+	if programErr != nil {
+		panic(fmt.Errorf("MapLinks shader failed: %v", programErr))
+	}
+	links := &MapLinks{
+		context: context,
+		program: program,
+
+		vao:                     opengl.NewVertexArrayObject(gl, program),
+		vertexPositionBuffer:    gl.GenBuffers(1)[0],
+		vertexPositionAttrib:    gl.GetAttribLocation(program, "vertexPosition"),
+		viewMatrixUniform:       opengl.Matrix4Uniform(gl.GetUniformLocation(program, "viewMatrix")),
+		projectionMatrixUniform: opengl.Matrix4Uniform(gl.GetUniformLocation(program, "projectionMatrix")),
+		inColorUniform:          opengl.Vector4Uniform(gl.GetUniformLocation(program, "inColor"))}
+
+	links.vao.WithSetter(func(gl opengl.OpenGL) {
+		gl.EnableVertexAttribArray(uint32(links.vertexPositionAttrib))
+		gl.BindBuffer(opengl.ARRAY_BUFFER, links.vertexPositionBuffer)
+		gl.VertexAttribOffset(uint32(links.vertexPositionAttrib), 3, opengl.FLOAT, false, 0, 0)
+		gl.BindBuffer(opengl.ARRAY_BUFFER, 0)
+	})
+
+	return links
+}
+
+// Dispose releases all resources.
+func (links *MapLinks) Dispose() {
+	gl := links.context.OpenGL
+
+	links.vao.Dispose()
+	gl.DeleteBuffers([]uint32{links.vertexPositionBuffer})
+	gl.DeleteProgram(links.program)
+}
+
+// Render draws the given links as arrows, with heads of given length at their destination.
+func (links *MapLinks) Render(list []MapLink, headLength float32, color [4]float32) {
+	vertices := mapLinkVertices(list, headLength)
+	if len(vertices) == 0 {
+		return
+	}
+	gl := links.context.OpenGL
+
+	links.vao.OnShader(func() {
+		links.viewMatrixUniform.Set(gl, links.context.ViewMatrix)
+		links.projectionMatrixUniform.Set(gl, &links.context.ProjectionMatrix)
+		links.inColorUniform.Set(gl, &color)
+
+		gl.BindBuffer(opengl.ARRAY_BUFFER, links.vertexPositionBuffer)
+		gl.BufferData(opengl.ARRAY_BUFFER, len(vertices)*4, vertices, opengl.STATIC_DRAW)
+		gl.DrawArrays(opengl.LINES, 0, int32(len(vertices)/3))
+		gl.BindBuffer(opengl.ARRAY_BUFFER, 0)
+	})
+}
+
+func mapLinkVertices(list []MapLink, headLength float32) []float32 {
+	headAngle := float64(math.Pi / 7)
+	vertices := make([]float32, 0, len(list)*3*2*3)
+	for _, link := range list {
+		fromX, fromY := float32(link.From.X), float32(link.From.Y)
+		toX, toY := float32(link.To.X), float32(link.To.Y)
+		dirX, dirY := float64(fromX-toX), float64(fromY-toY)
+		length := math.Sqrt(dirX*dirX + dirY*dirY)
+		if length < 1 {
+			continue
+		}
+		angle := math.Atan2(dirY, dirX)
+		vertices = append(vertices, fromX, fromY, 0.0, toX, toY, 0.0)
+		for _, side := range []float64{-headAngle, headAngle} {
+			headX := toX + headLength*float32(math.Cos(angle+side))
+			headY := toY + headLength*float32(math.Sin(angle+side))
+			vertices = append(vertices, toX, toY, 0.0, headX, headY, 0.0)
+		}
+	}
+	return vertices
+}
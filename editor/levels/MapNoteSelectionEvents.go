@@ -0,0 +1,6 @@
+package levels
+
+// MapNoteSelectionSetEvent notifies about the currently selected map note.
+type MapNoteSelectionSetEvent struct {
+	index int
+}
@@ -0,0 +1,65 @@
+package levels
+
+import (
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+)
+
+// mapObjectDrag keeps track of objects being dragged on the map.
+// All objects are moved by the same offset, which is determined by the snapped position of the anchor.
+type mapObjectDrag struct {
+	objects []level.ObjectID
+	starts  []MapPosition
+	anchor  MapPosition
+	target  MapPosition
+	active  bool
+	moved   bool
+}
+
+func (drag *mapObjectDrag) start(lvl *level.Level, objects []level.ObjectID, anchor MapPosition) {
+	drag.objects = nil
+	drag.starts = nil
+	for _, id := range objects {
+		obj := lvl.Object(id)
+		if (obj != nil) && (obj.InUse != 0) {
+			drag.objects = append(drag.objects, id)
+			drag.starts = append(drag.starts, MapPosition{X: obj.X, Y: obj.Y})
+		}
+	}
+	drag.anchor = anchor
+	drag.target = anchor
+	drag.active = len(drag.objects) > 0
+	drag.moved = false
+}
+
+func (drag *mapObjectDrag) update(pos MapPosition, snap MapSnap) {
+	target := snap.Apply(pos)
+	if target != drag.target {
+		drag.target = target
+		drag.moved = true
+	}
+}
+
+func (drag *mapObjectDrag) stop() {
+	drag.active = false
+}
+
+// positions returns the new positions of the dragged objects.
+// Objects that would end up outside the map are kept at the border.
+func (drag mapObjectDrag) positions() []MapPosition {
+	const limit = 64<<8 - 1
+	clamp := func(value int) level.Coordinate {
+		if value < 0 {
+			return 0
+		} else if value > limit {
+			return limit
+		}
+		return level.Coordinate(value)
+	}
+	offsetX := int(drag.target.X) - int(drag.anchor.X)
+	offsetY := int(drag.target.Y) - int(drag.anchor.Y)
+	result := make([]MapPosition, len(drag.starts))
+	for index, start := range drag.starts {
+		result[index] = MapPosition{X: clamp(int(start.X) + offsetX), Y: clamp(int(start.Y) + offsetY)}
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+package levels
+
+import (
+	"math"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+)
+
+// rotationSnapIncrement is the step size of the rotation handle, which is 45 degrees.
+const rotationSnapIncrement = level.RotationUnit(0x20)
+
+// mapObjectRotation keeps track of the interactive rotation of objects.
+// The heading is determined by the direction from the pivot to the handle.
+type mapObjectRotation struct {
+	objects []level.ObjectID
+	pivot   MapPosition
+	heading level.RotationUnit
+	active  bool
+	changed bool
+}
+
+func (rotation *mapObjectRotation) start(objects []level.ObjectID, pivot MapPosition, heading level.RotationUnit) {
+	rotation.objects = objects
+	rotation.pivot = pivot
+	rotation.heading = heading
+	rotation.active = true
+	rotation.changed = false
+}
+
+func (rotation *mapObjectRotation) update(handle MapPosition) {
+	deltaX := float64(handle.X) - float64(rotation.pivot.X)
+	deltaY := float64(handle.Y) - float64(rotation.pivot.Y)
+	if math.Hypot(deltaX, deltaY) < float64(fineCoordinatesPerTileSide/8) {
+		return
+	}
+	heading := headingTowards(deltaX, deltaY).SnappedTo(rotationSnapIncrement)
+	if heading != rotation.heading {
+		rotation.heading = heading
+		rotation.changed = true
+	}
+}
+
+func (rotation *mapObjectRotation) stop() {
+	rotation.active = false
+}
+
+// headingTowards returns the heading for a direction. Headings increase clockwise, with 0 pointing north.
+func headingTowards(deltaX, deltaY float64) level.RotationUnit {
+	return level.RotationUnitFromDegrees(math.Atan2(deltaX, deltaY) * 180.0 / math.Pi)
+}
+
+// facingLink returns a link from the given position in the direction of the heading.
+func facingLink(pos MapPosition, heading level.RotationUnit, length float32) MapLink {
+	radians := heading.ToDegrees() * math.Pi / 180.0
+	clamp := func(value float64) level.Coordinate {
+		return level.Coordinate(math.Max(0, math.Min(value, float64(64*fineCoordinatesPerTileSide-1))))
+	}
+	return MapLink{
+		From: pos,
+		To: MapPosition{
+			X: clamp(float64(pos.X) + math.Sin(radians)*float64(length)),
+			Y: clamp(float64(pos.Y) + math.Cos(radians)*float64(length)),
+		},
+	}
+}
@@ -0,0 +1,103 @@
+package levels
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/imgui-go"
+)
+
+// rulerSightHeight is the height above the floor, in tiles, from which the line of sight is checked.
+const rulerSightHeight = 0.75
+
+// mapRuler measures the distance between two points on the map.
+type mapRuler struct {
+	from  MapPosition
+	to    MapPosition
+	valid bool
+}
+
+func (ruler *mapRuler) start(pos MapPosition) {
+	ruler.from = pos
+	ruler.to = pos
+	ruler.valid = true
+}
+
+func coordinateInTiles(coord level.Coordinate) float32 {
+	return float32(coord.Tile()) + float32(coord.Fine())/fineCoordinatesPerTileSide
+}
+
+// markers returns positions along the measured line, to be highlighted.
+func (ruler mapRuler) markers() []MapPosition {
+	fromX, fromY := float32(ruler.from.X), float32(ruler.from.Y)
+	deltaX, deltaY := float32(ruler.to.X)-fromX, float32(ruler.to.Y)-fromY
+	steps := int(math.Hypot(float64(deltaX), float64(deltaY))/float64(fineCoordinatesPerTileSide/4)) + 1
+	markers := make([]MapPosition, 0, steps+1)
+	for step := 0; step <= steps; step++ {
+		fraction := float32(step) / float32(steps)
+		markers = append(markers, MapPosition{
+			X: level.Coordinate(fromX + deltaX*fraction),
+			Y: level.Coordinate(fromY + deltaY*fraction),
+		})
+	}
+	return markers
+}
+
+// sightHeight returns the height from which the line of sight is checked at given position.
+func sightHeight(lvl *level.Level, x, y float32) (float32, bool) {
+	floor, floorOpen := lvl.FloorHeightAt(x, y)
+	ceiling, ceilingOpen := lvl.CeilingHeightAt(x, y)
+	if !floorOpen || !ceilingOpen {
+		return 0, false
+	}
+	return floor + float32(math.Min(rulerSightHeight, float64(ceiling-floor)/2)), true
+}
+
+func (ruler mapRuler) renderOverlay(lvl *level.Level, pos imgui.Vec2, guiScale float32) {
+	imgui.SetNextWindowPosV(pos, imgui.ConditionAlways, imgui.Vec2{X: 1.0, Y: 1.0})
+	imgui.SetNextWindowSize(imgui.Vec2{X: 220 * guiScale, Y: 0})
+	imgui.SetNextWindowBgAlpha(0.3)
+	if imgui.BeginV("Ruler", nil, imgui.WindowFlagsNoMove|imgui.WindowFlagsNoTitleBar|imgui.WindowFlagsNoResize|imgui.WindowFlagsAlwaysAutoResize|
+		imgui.WindowFlagsNoSavedSettings|imgui.WindowFlagsNoFocusOnAppearing|imgui.WindowFlagsNoNav) {
+		if !ruler.valid {
+			imgui.Text("Ruler: drag to measure")
+		} else {
+			fromX, fromY := coordinateInTiles(ruler.from.X), coordinateInTiles(ruler.from.Y)
+			toX, toY := coordinateInTiles(ruler.to.X), coordinateInTiles(ruler.to.Y)
+			deltaX, deltaY := toX-fromX, toY-fromY
+			distance := float32(math.Hypot(float64(deltaX), float64(deltaY)))
+			imgui.Text(fmt.Sprintf("From: T %2d/%2d F %3d/%3d", ruler.from.X.Tile(), ruler.from.Y.Tile(), ruler.from.X.Fine(), ruler.from.Y.Fine()))
+			imgui.Text(fmt.Sprintf("To:   T %2d/%2d F %3d/%3d", ruler.to.X.Tile(), ruler.to.Y.Tile(), ruler.to.X.Fine(), ruler.to.Y.Fine()))
+			imgui.Text(fmt.Sprintf("DX: %2.3f DY: %2.3f", deltaX, deltaY))
+			imgui.Text(fmt.Sprintf("Distance: %2.3f tiles = %d fine", distance, int(distance*fineCoordinatesPerTileSide)))
+
+			fromFloor, fromOpen := lvl.FloorHeightAt(fromX, fromY)
+			toFloor, toOpen := lvl.FloorHeightAt(toX, toY)
+			if fromOpen && toOpen {
+				_, _, heightShift := lvl.Size()
+				heightDelta := toFloor - fromFloor
+				objectHeightDelta := int(heightShift.ValueToObjectHeight(float32(math.Abs(float64(heightDelta)))))
+				if heightDelta < 0 {
+					objectHeightDelta = -objectHeightDelta
+				}
+				imgui.Text(fmt.Sprintf("Floor: %2.3f -> %2.3f", fromFloor, toFloor))
+				imgui.Text(fmt.Sprintf("Height: %2.3f tiles = %d units", heightDelta, objectHeightDelta))
+			} else {
+				imgui.Text("Height: --")
+			}
+
+			fromZ, fromSight := sightHeight(lvl, fromX, fromY)
+			toZ, toSight := sightHeight(lvl, toX, toY)
+			sightText := "--"
+			if fromSight && toSight {
+				sightText = "Blocked"
+				if lvl.LineOfSight(fromX, fromY, fromZ, toX, toY, toZ) {
+					sightText = "Clear"
+				}
+			}
+			imgui.Text("Line of Sight: " + sightText)
+		}
+		imgui.End()
+	}
+}
@@ -0,0 +1,58 @@
+package levels
+
+import (
+	"fmt"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+)
+
+// MapSnap is an enumeration of how dragged objects are aligned on the map.
+type MapSnap int
+
+// MapSnap constants
+const (
+	MapSnapNone       MapSnap = 0
+	MapSnapQuarter    MapSnap = 1
+	MapSnapTileCenter MapSnap = 2
+)
+
+// String returns a textual representation.
+func (snap MapSnap) String() string {
+	switch snap {
+	case MapSnapNone:
+		return "None"
+	case MapSnapQuarter:
+		return "Quarter Tile"
+	case MapSnapTileCenter:
+		return "Tile Center"
+	default:
+		return fmt.Sprintf("Unknown%d", int(snap))
+	}
+}
+
+// MapSnaps returns all MapSnap constants.
+func MapSnaps() []MapSnap {
+	return []MapSnap{MapSnapNone, MapSnapQuarter, MapSnapTileCenter}
+}
+
+// Apply returns the position aligned according to the snapping.
+func (snap MapSnap) Apply(pos MapPosition) MapPosition {
+	switch snap {
+	case MapSnapQuarter:
+		return MapPosition{X: snapCoordinateToQuarter(pos.X), Y: snapCoordinateToQuarter(pos.Y)}
+	case MapSnapTileCenter:
+		return tileCenter(pos)
+	default:
+		return pos
+	}
+}
+
+func snapCoordinateToQuarter(coord level.Coordinate) level.Coordinate {
+	const quarter = 0x40
+	const limit = 63<<8 | 0xC0
+	snapped := ((int(coord) + quarter/2) / quarter) * quarter
+	if snapped > limit {
+		snapped = limit
+	}
+	return level.Coordinate(snapped)
+}
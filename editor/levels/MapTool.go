@@ -0,0 +1,52 @@
+package levels
+
+import "fmt"
+
+// MapTool is an enumeration of what the primary mouse button does in the map display.
+type MapTool int
+
+// MapTool constants
+const (
+	MapToolSelect    MapTool = 0
+	MapToolBrush     MapTool = 1
+	MapToolRectangle MapTool = 2
+	MapToolFloodFill MapTool = 3
+	MapToolRuler     MapTool = 4
+	MapToolMove      MapTool = 5
+	MapToolRotate    MapTool = 6
+	MapToolPatrol    MapTool = 7
+)
+
+// String returns a textual representation.
+func (tool MapTool) String() string {
+	switch tool {
+	case MapToolSelect:
+		return "Select"
+	case MapToolBrush:
+		return "Brush"
+	case MapToolRectangle:
+		return "Rectangle Fill"
+	case MapToolFloodFill:
+		return "Flood Fill"
+	case MapToolRuler:
+		return "Ruler"
+	case MapToolMove:
+		return "Move Objects"
+	case MapToolRotate:
+		return "Rotate Objects"
+	case MapToolPatrol:
+		return "Critter Patrol"
+	default:
+		return fmt.Sprintf("Unknown%d", int(tool))
+	}
+}
+
+// MapTools returns all MapTool constants.
+func MapTools() []MapTool {
+	return []MapTool{MapToolSelect, MapToolBrush, MapToolRectangle, MapToolFloodFill, MapToolRuler, MapToolMove, MapToolRotate, MapToolPatrol}
+}
+
+// IsPainting returns true for tools that paint tiles.
+func (tool MapTool) IsPainting() bool {
+	return (tool == MapToolBrush) || (tool == MapToolRectangle) || (tool == MapToolFloodFill)
+}
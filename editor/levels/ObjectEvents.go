@@ -1,6 +1,29 @@
 package levels
 
+import (
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+)
+
 // ObjectRequestCreateEvent for requesting a new object at given position.
 type ObjectRequestCreateEvent struct {
 	Pos MapPosition
 }
+
+// ObjectMoveRequestEvent for requesting to move objects to new positions.
+// Each entry of Objects is moved to the position of the same index in Positions.
+type ObjectMoveRequestEvent struct {
+	Objects   []level.ObjectID
+	Positions []MapPosition
+}
+
+// ObjectRotateRequestEvent for requesting to set the heading of objects.
+type ObjectRotateRequestEvent struct {
+	Objects []level.ObjectID
+	Heading level.RotationUnit
+}
+
+// CritterPatrolRequestEvent for requesting to set the patrol tile of a critter to the tile of given position.
+type CritterPatrolRequestEvent struct {
+	Critter level.ObjectID
+	Pos     MapPosition
+}
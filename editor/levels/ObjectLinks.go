@@ -0,0 +1,52 @@
+package levels
+
+import (
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+)
+
+// ObjectLinkFilter describes which references between objects shall be shown.
+type ObjectLinkFilter struct {
+	// Enabled is set if references shall be shown at all.
+	Enabled bool
+	// HiddenClasses marks the classes of which no references shall be shown.
+	// A reference is shown if the referring object is of a visible class.
+	HiddenClasses [object.ClassCount]bool
+}
+
+// IsClassVisible returns true if references of given class shall be shown.
+func (filter ObjectLinkFilter) IsClassVisible(class object.Class) bool {
+	return filter.Enabled && (int(class) < len(filter.HiddenClasses)) && !filter.HiddenClasses[class]
+}
+
+type objectLink struct {
+	from level.ObjectID
+	to   level.ObjectID
+	key  string
+}
+
+func objectLinks(lvl *level.Level, filter ObjectLinkFilter) []objectLink {
+	var links []objectLink
+	if !filter.Enabled {
+		return links
+	}
+	interpreterFactory := lvlobj.ForRealWorld
+	if lvl.IsCyberspace() {
+		interpreterFactory = lvlobj.ForCyberspace
+	}
+	lvl.ForEachObject(func(id level.ObjectID, entry level.ObjectMasterEntry) {
+		if !filter.IsClassVisible(entry.Class) {
+			return
+		}
+		data := lvl.ObjectClassData(id)
+		for _, ref := range lvlobj.ObjectReferences(interpreterFactory(entry.Triple(), data)) {
+			target := lvl.Object(level.ObjectID(ref.ID))
+			if (target == nil) || (target.InUse == 0) || (level.ObjectID(ref.ID) == id) {
+				continue
+			}
+			links = append(links, objectLink{from: id, to: level.ObjectID(ref.ID), key: ref.Key})
+		}
+	})
+	return links
+}
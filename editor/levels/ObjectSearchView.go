@@ -0,0 +1,241 @@
+package levels
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/inkyblackness/hacked/editor/event"
+	"github.com/inkyblackness/hacked/editor/model"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/hacked/ss1/content/interpreters"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+	"github.com/inkyblackness/hacked/ss1/content/text"
+	"github.com/inkyblackness/hacked/ui/gui"
+	"github.com/inkyblackness/imgui-go"
+)
+
+const objectSearchResultLimit = 200
+
+// ObjectSearchView lists the objects of a level that match a set of criteria.
+type ObjectSearchView struct {
+	mod       *model.Mod
+	textCache *text.Cache
+
+	guiScale      float32
+	eventListener event.Listener
+
+	model objectSearchViewModel
+}
+
+// NewObjectSearchView returns a new instance.
+func NewObjectSearchView(mod *model.Mod, guiScale float32, textCache *text.Cache, eventListener event.Listener) *ObjectSearchView {
+	return &ObjectSearchView{
+		mod:       mod,
+		textCache: textCache,
+
+		guiScale:      guiScale,
+		eventListener: eventListener,
+
+		model: freshObjectSearchViewModel(),
+	}
+}
+
+// WindowOpen returns the flag address, to be used with the main menu.
+func (view *ObjectSearchView) WindowOpen() *bool {
+	return &view.model.windowOpen
+}
+
+// Render renders the view.
+func (view *ObjectSearchView) Render(lvl *level.Level) {
+	if !view.model.windowOpen {
+		return
+	}
+	imgui.SetNextWindowSizeV(imgui.Vec2{X: 400 * view.guiScale, Y: 500 * view.guiScale}, imgui.ConditionOnce)
+	if imgui.BeginV("Level Object Search", view.WindowOpen(), imgui.WindowFlagsHorizontalScrollbar) {
+		imgui.PushItemWidth(-150 * view.guiScale)
+		view.renderCriteria(lvl)
+		imgui.Separator()
+		view.renderResults(lvl)
+		imgui.PopItemWidth()
+	}
+	imgui.End()
+}
+
+func (view *ObjectSearchView) renderCriteria(lvl *level.Level) {
+	query := &view.model.query
+
+	classString := func(class int) string {
+		if class < 0 {
+			return "(any)"
+		}
+		return fmt.Sprintf("%2d: %v", class, object.Class(class))
+	}
+	if imgui.BeginCombo("Class", classString(query.Class)) {
+		if imgui.SelectableV(classString(-1), query.Class < 0, 0, imgui.Vec2{}) {
+			query.Class = -1
+			query.AnyType = true
+			query.PropertyKey = ""
+		}
+		for _, class := range object.Classes() {
+			if imgui.SelectableV(classString(int(class)), int(class) == query.Class, 0, imgui.Vec2{}) {
+				query.Class = int(class)
+				query.AnyType = true
+				query.PropertyKey = ""
+			}
+		}
+		imgui.EndCombo()
+	}
+	if query.Class >= 0 {
+		typeString := func(anyType bool, triple object.Triple) string {
+			if anyType {
+				return "(any)"
+			}
+			return objectTripleName(view.mod, view.textCache, triple)
+		}
+		if imgui.BeginCombo("Type", typeString(query.AnyType, query.Triple)) {
+			if imgui.SelectableV(typeString(true, query.Triple), query.AnyType, 0, imgui.Vec2{}) {
+				query.AnyType = true
+			}
+			for _, triple := range view.mod.ObjectProperties().TriplesInClass(object.Class(query.Class)) {
+				if imgui.SelectableV(typeString(false, triple), !query.AnyType && (triple == query.Triple), 0, imgui.Vec2{}) {
+					query.AnyType = false
+					query.Triple = triple
+				}
+			}
+			imgui.EndCombo()
+		}
+	}
+
+	columns, rows, _ := lvl.Size()
+	gui.StepSliderIntV("Min Tile X", &query.MinTileX, 0, columns-1, "%d")
+	gui.StepSliderIntV("Max Tile X", &query.MaxTileX, 0, columns-1, "%d")
+	gui.StepSliderIntV("Min Tile Y", &query.MinTileY, 0, rows-1, "%d")
+	gui.StepSliderIntV("Max Tile Y", &query.MaxTileY, 0, rows-1, "%d")
+	gui.StepSliderIntV("Min Hitpoints", &query.MinHitpoints, -0x8000, 0x7FFF, "%d")
+	gui.StepSliderIntV("Max Hitpoints", &query.MaxHitpoints, -0x8000, 0x7FFF, "%d")
+
+	view.renderPropertyCriteria(lvl)
+
+	if imgui.Button("Reset Criteria") {
+		view.model.query = lvlobj.DefaultObjectQuery()
+	}
+}
+
+func (view *ObjectSearchView) renderPropertyCriteria(lvl *level.Level) {
+	query := &view.model.query
+	baseQuery := *query
+	baseQuery.PropertyKey = ""
+	candidates := lvlobj.FindObjects(lvl, baseQuery)
+	interpreterFactory := lvlobj.ForRealWorld
+	if lvl.IsCyberspace() {
+		interpreterFactory = lvlobj.ForCyberspace
+	}
+	instanceOf := func(id level.ObjectID) *interpreters.Instance {
+		return interpreterFactory(lvl.Object(id).Triple(), lvl.ObjectClassData(id))
+	}
+
+	keySet := make(map[string]bool)
+	for _, id := range candidates {
+		for _, key := range lvlobj.PropertyKeys(instanceOf(id)) {
+			keySet[key] = true
+		}
+	}
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	keyString := func(key string) string {
+		if len(key) == 0 {
+			return "(any)"
+		}
+		return key
+	}
+	if imgui.BeginCombo("Property", keyString(query.PropertyKey)) {
+		if imgui.SelectableV(keyString(""), len(query.PropertyKey) == 0, 0, imgui.Vec2{}) {
+			query.PropertyKey = ""
+		}
+		for _, key := range keys {
+			if imgui.SelectableV(key, key == query.PropertyKey, 0, imgui.Vec2{}) {
+				query.PropertyKey = key
+			}
+		}
+		imgui.EndCombo()
+	}
+	if len(query.PropertyKey) == 0 {
+		return
+	}
+
+	valueNames := make(map[uint32]string)
+	for _, id := range candidates {
+		inst := instanceOf(id)
+		value, available := lvlobj.PropertyValue(inst, query.PropertyKey)
+		if available {
+			valueNames[value] = propertyValueName(inst, query.PropertyKey, value)
+		}
+	}
+	options := make([]uint32, 0, len(valueNames))
+	for value := range valueNames {
+		options = append(options, value)
+	}
+	sort.Slice(options, func(a, b int) bool { return options[a] < options[b] })
+	valueString := func(value uint32) string {
+		name, known := valueNames[value]
+		if !known {
+			return fmt.Sprintf("%d", value)
+		}
+		return name
+	}
+	if imgui.BeginCombo("Value", valueString(query.PropertyValue)) {
+		for _, value := range options {
+			if imgui.SelectableV(valueString(value), value == query.PropertyValue, 0, imgui.Vec2{}) {
+				query.PropertyValue = value
+			}
+		}
+		imgui.EndCombo()
+	}
+}
+
+func propertyValueName(inst *interpreters.Instance, key string, value uint32) string {
+	subKeys := strings.Split(key, ".")
+	valueIndex := len(subKeys) - 1
+	for subIndex := 0; subIndex < valueIndex; subIndex++ {
+		inst = inst.Refined(subKeys[subIndex])
+	}
+	name := ""
+	simplifier := interpreters.NewSimplifier(func(minValue, maxValue int64, formatter interpreters.RawValueFormatter) {})
+	simplifier.SetEnumValueHandler(func(values map[uint32]string) {
+		name = values[value]
+	})
+	inst.Describe(subKeys[valueIndex], simplifier)
+	if len(name) > 0 {
+		return fmt.Sprintf("%d: %s", value, name)
+	}
+	return fmt.Sprintf("%d", value)
+}
+
+func (view *ObjectSearchView) renderResults(lvl *level.Level) {
+	found := lvlobj.FindObjects(lvl, view.model.query)
+	imgui.Text(fmt.Sprintf("%d objects found", len(found)))
+	if len(found) > 0 {
+		imgui.SameLine()
+		if imgui.Button("Select All") {
+			view.eventListener.Event(ObjectSelectionSetEvent{objects: found})
+		}
+	}
+	for index, id := range found {
+		if index >= objectSearchResultLimit {
+			imgui.Text(fmt.Sprintf("... and %d more", len(found)-index))
+			break
+		}
+		obj := lvl.Object(id)
+		label := fmt.Sprintf("%3d: %s (%d/%d)", int(id), objectTripleName(view.mod, view.textCache, obj.Triple()),
+			obj.X.Tile(), obj.Y.Tile())
+		if imgui.Selectable(label) {
+			view.eventListener.Event(ObjectSelectionSetEvent{objects: []level.ObjectID{id}})
+		}
+	}
+}
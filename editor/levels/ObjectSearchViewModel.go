@@ -0,0 +1,15 @@
+package levels
+
+import "github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+
+type objectSearchViewModel struct {
+	query lvlobj.ObjectQuery
+
+	windowOpen bool
+}
+
+func freshObjectSearchViewModel() objectSearchViewModel {
+	return objectSearchViewModel{
+		query: lvlobj.DefaultObjectQuery(),
+	}
+}
@@ -55,6 +55,11 @@ func NewObjectsView(mod *model.Mod, guiScale float32, textCache *text.Cache, tex
 	return view
 }
 
+// LinkFilter returns the current setting which references between objects shall be shown on the map.
+func (view *ObjectsView) LinkFilter() ObjectLinkFilter {
+	return view.model.linkFilter
+}
+
 // WindowOpen returns the flag address, to be used with the main menu.
 func (view *ObjectsView) WindowOpen() *bool {
 	return &view.model.windowOpen
@@ -260,10 +265,59 @@ func (view *ObjectsView) renderContent(lvl *level.Level, readOnly bool) {
 		view.renderBlockPuzzleControl(lvl, readOnly)
 		imgui.TreePop()
 	}
+	if container := view.selectedContainer(lvl); container != 0 {
+		if imgui.TreeNodeV("Container Contents", imgui.TreeNodeFlagsDefaultOpen|imgui.TreeNodeFlagsFramed) {
+			view.renderContainerContents(lvl, readOnly, container)
+			imgui.TreePop()
+		}
+	}
+	if imgui.TreeNodeV("Object Links", imgui.TreeNodeFlagsFramed) {
+		view.renderObjectLinks(lvl)
+		imgui.TreePop()
+	}
+	if imgui.TreeNodeV("Prefabs", imgui.TreeNodeFlagsFramed) {
+		view.renderPrefabs(lvl, readOnly)
+		imgui.TreePop()
+	}
+	if imgui.TreeNodeV("Reference Check", imgui.TreeNodeFlagsFramed) {
+		view.renderReferenceCheck(lvl, readOnly)
+		imgui.TreePop()
+	}
 
 	imgui.PopItemWidth()
 }
 
+func (view *ObjectsView) renderObjectLinks(lvl *level.Level) {
+	filter := &view.model.linkFilter
+	imgui.Checkbox("Show on Map", &filter.Enabled)
+	if filter.Enabled {
+		for _, class := range object.Classes() {
+			visible := !filter.HiddenClasses[class]
+			if imgui.Checkbox(fmt.Sprintf("%2d: %v", int(class), class), &visible) {
+				filter.HiddenClasses[class] = !visible
+			}
+		}
+	}
+	imgui.Separator()
+
+	allClasses := ObjectLinkFilter{Enabled: true}
+	for _, link := range objectLinks(lvl, allClasses) {
+		outgoing := view.model.selectedObjects.contains(link.from)
+		incoming := view.model.selectedObjects.contains(link.to)
+		if !outgoing && !incoming {
+			continue
+		}
+		other := link.to
+		if !outgoing {
+			other = link.from
+		}
+		label := fmt.Sprintf("%3d -> %3d: %s", int(link.from), int(link.to), link.key)
+		if imgui.Selectable(label) {
+			view.eventListener.Event(ObjectSelectionSetEvent{objects: []level.ObjectID{other}})
+		}
+	}
+}
+
 func (view *ObjectsView) renderProperties(lvl *level.Level, readOnly bool,
 	dataRetriever func(level.ObjectID, *level.ObjectMasterEntry) []byte,
 	interpreterFactory lvlobj.InterpreterFactory) {
@@ -346,7 +400,7 @@ func (view *ObjectsView) renderPropertyControl(lvl *level.Level, readOnly bool,
 	keys := strings.Split(fullKey, ".")
 	key := keys[len(keys)-1]
 	label := key + "###" + fullKey
-	_, _, levelHeight := lvl.Size()
+	columns, rows, levelHeight := lvl.Size()
 	tileHeightFormatter := tileHeightFormatterFor(levelHeight)
 	objectHeightFormatter := objectHeightFormatterFor(levelHeight)
 	moveTileHeightFormatter := moveTileHeightFormatterFor(levelHeight)
@@ -689,6 +743,20 @@ func (view *ObjectsView) renderPropertyControl(lvl *level.Level, readOnly bool,
 			})
 	})
 
+	addTileCoordinate := func(tileCount int) func() {
+		return func() {
+			values.RenderUnifiedSliderInt(readOnly, multiple, label, unifier,
+				func(u values.Unifier) int { return int(u.Unified().(int32)) },
+				func(value int) string { return "%d" },
+				0, tileCount-1,
+				func(newValue int) {
+					updater(func(oldValue uint32) uint32 { return uint32(newValue) })
+				})
+		}
+	}
+	simplifier.SetSpecialHandler("TileX", addTileCoordinate(columns))
+	simplifier.SetSpecialHandler("TileY", addTileCoordinate(rows))
+
 	simplifier.SetSpecialHandler("TileType", func() {
 		values.RenderUnifiedCombo(readOnly, multiple, label, unifier,
 			func(u values.Unifier) int { return int(u.Unified().(int32)) },
@@ -895,8 +963,14 @@ func (view *ObjectsView) requestPropertiesChange(lvl *level.Level,
 }
 
 // RequestCreateObject requests to create a new object of the currently selected type.
+// Should the placement of a prefab be active, the prefab is instantiated instead.
 func (view *ObjectsView) RequestCreateObject(lvl *level.Level, pos MapPosition) {
-	if view.editingAllowed(lvl.ID()) {
+	if !view.editingAllowed(lvl.ID()) {
+		return
+	}
+	if view.model.placePrefab && (view.model.prefabIndex >= 0) {
+		view.requestPlacePrefab(lvl, pos)
+	} else {
 		view.requestCreateObject(lvl, view.model.newObjectTriple, pos)
 	}
 }
@@ -906,13 +980,75 @@ func (view *ObjectsView) requestCreateObject(lvl *level.Level, triple object.Tri
 	if err != nil {
 		return
 	}
-	const DefaultHeight = 1.0 / float32(0xbd00)
-	const PhysicsScale = 96.0
-	objHeight := DefaultHeight
 	obj := lvl.Object(id)
 	prop, err := view.mod.ObjectProperties().ForObject(triple)
 	if err == nil {
 		obj.Hitpoints = prop.Common.Hitpoints
+	}
+	obj.Subclass = triple.Subclass
+	obj.Type = triple.Type
+	view.placeObject(lvl, obj, pos)
+	lvl.UpdateObjectLocation(id)
+	view.patchLevel(lvl, []level.ObjectID{id}, view.model.selectedObjects.list)
+}
+
+// RequestMoveObjects requests to move the given objects to the given positions.
+// The objects are put on the floor at their new positions.
+func (view *ObjectsView) RequestMoveObjects(lvl *level.Level, objectIDs []level.ObjectID, positions []MapPosition) {
+	if !view.editingAllowed(lvl.ID()) || (len(objectIDs) != len(positions)) {
+		return
+	}
+	var moved []level.ObjectID
+	for index, id := range objectIDs {
+		obj := lvl.Object(id)
+		if (obj == nil) || (obj.InUse == 0) {
+			continue
+		}
+		view.placeObject(lvl, obj, positions[index])
+		lvl.UpdateObjectLocation(id)
+		moved = append(moved, id)
+	}
+	if len(moved) > 0 {
+		view.patchLevel(lvl, moved, moved)
+	}
+}
+
+// RequestRotateObjects requests to set the heading of the given objects.
+func (view *ObjectsView) RequestRotateObjects(lvl *level.Level, objectIDs []level.ObjectID, heading level.RotationUnit) {
+	if !view.editingAllowed(lvl.ID()) {
+		return
+	}
+	var rotated []level.ObjectID
+	for _, id := range objectIDs {
+		obj := lvl.Object(id)
+		if (obj == nil) || (obj.InUse == 0) {
+			continue
+		}
+		obj.ZRotation = heading
+		rotated = append(rotated, id)
+	}
+	if len(rotated) > 0 {
+		view.patchLevel(lvl, rotated, rotated)
+	}
+}
+
+// RequestSetCritterPatrol requests to set the patrol tile of a critter to the tile of given position.
+func (view *ObjectsView) RequestSetCritterPatrol(lvl *level.Level, critter level.ObjectID, pos MapPosition) {
+	if !view.editingAllowed(lvl.ID()) {
+		return
+	}
+	if lvlobj.SetCritterPatrolTile(lvl, critter, int(pos.X.Tile()), int(pos.Y.Tile())) {
+		view.patchLevel(lvl, []level.ObjectID{critter}, []level.ObjectID{critter})
+	}
+}
+
+// placeObject sets the position of the object and puts it on the floor of the tile.
+func (view *ObjectsView) placeObject(lvl *level.Level, obj *level.ObjectMasterEntry, pos MapPosition) {
+	const DefaultHeight = 1.0 / float32(0xbd00)
+	const PhysicsScale = 96.0
+	objHeight := DefaultHeight
+	prop, err := view.mod.ObjectProperties().ForObject(obj.Triple())
+	if err == nil {
 		if (prop.Common.RenderType == object.RenderTypeTextPoly) || (prop.Common.RenderType == object.RenderTypeSpecial) {
 			objHeight = 0
 		} else if prop.Common.PhysicsZ != 0 {
@@ -929,10 +1065,6 @@ func (view *ObjectsView) requestCreateObject(lvl *level.Level, triple object.Tri
 		floorHeight := view.floorHeightAtFine(tile, pos, height)
 		obj.Z = height.ValueToObjectHeight(floorHeight + objHeight)
 	}
-	obj.Subclass = triple.Subclass
-	obj.Type = triple.Type
-	lvl.UpdateObjectLocation(id)
-	view.patchLevel(lvl, []level.ObjectID{id}, view.model.selectedObjects.list)
 }
 
 func (view *ObjectsView) floorHeightAtFine(tile *level.TileMapEntry, pos MapPosition, height level.HeightShift) float32 {
@@ -1012,11 +1144,15 @@ func (view *ObjectsView) setSelectedObjects(objectIDs []level.ObjectID) {
 }
 
 func (view *ObjectsView) tripleName(triple object.Triple) string {
+	return objectTripleName(view.mod, view.textCache, triple)
+}
+
+func objectTripleName(mod *model.Mod, textCache *text.Cache, triple object.Triple) string {
 	suffix := "???"
-	linearIndex := view.mod.ObjectProperties().TripleIndex(triple)
+	linearIndex := mod.ObjectProperties().TripleIndex(triple)
 	if linearIndex >= 0 {
 		key := resource.KeyOf(ids.ObjectLongNames, resource.LangDefault, linearIndex)
-		objName, err := view.textCache.Text(key)
+		objName, err := textCache.Text(key)
 		if err == nil {
 			suffix = objName
 		}
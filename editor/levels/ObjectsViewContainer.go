@@ -0,0 +1,179 @@
+package levels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/hacked/ui/gui"
+	"github.com/inkyblackness/imgui-go"
+)
+
+const containerNestingLimit = 4
+
+func (view *ObjectsView) renderContainerContents(lvl *level.Level, readOnly bool, container level.ObjectID) {
+	if len(view.model.selectedObjects.list) > 1 {
+		imgui.Text(fmt.Sprintf("Container: %3d %s", int(container), view.tripleName(lvl.Object(container).Triple())))
+	}
+	contents := lvlobj.ContainerContents(lvl, container)
+	for slot, id := range contents {
+		imgui.PushID(fmt.Sprintf("slot%d", slot))
+		if id == 0 {
+			imgui.Text(fmt.Sprintf("Slot %d: (empty)", slot+1))
+			if !readOnly {
+				imgui.SameLine()
+				if imgui.Button("Create") {
+					view.requestCreateInContainer(lvl, container, slot)
+				}
+			}
+		} else {
+			view.renderContainedObject(lvl, slot, id, 0)
+			if !readOnly {
+				if imgui.Button("Drop") {
+					view.requestDropFromContainer(lvl, container, slot)
+				}
+				imgui.SameLine()
+				if imgui.Button("Move") {
+					view.requestMoveToContainer(lvl, id, level.ObjectID(view.model.containerTarget))
+				}
+			}
+		}
+		imgui.PopID()
+	}
+	if !readOnly {
+		imgui.Separator()
+		gui.StepSliderIntV("Move Target Container", &view.model.containerTarget, 1, int(lvl.ObjectLimit()), "%d")
+		if len(view.model.selectedObjects.list) > 1 {
+			if imgui.Button("Put Other Selected Into Container") {
+				view.requestPutSelectedIntoContainer(lvl, container)
+			}
+		}
+	}
+	if len(view.model.containerMessage) > 0 {
+		imgui.Text(view.model.containerMessage)
+	}
+}
+
+// selectedContainer returns the first selected object that is a container, or zero if there is none.
+func (view *ObjectsView) selectedContainer(lvl *level.Level) level.ObjectID {
+	for _, id := range view.model.selectedObjects.list {
+		if lvlobj.IsContainer(lvl, id) {
+			return id
+		}
+	}
+	return 0
+}
+
+func (view *ObjectsView) renderContainedObject(lvl *level.Level, slot int, id level.ObjectID, depth int) {
+	obj := lvl.Object(id)
+	name := "(not in use)"
+	if (obj != nil) && (obj.InUse != 0) {
+		name = view.tripleName(obj.Triple())
+	}
+	indent := strings.Repeat("  ", depth)
+	label := fmt.Sprintf("%sSlot %d: %3d %s", indent, slot+1, int(id), name)
+	if imgui.Selectable(label) {
+		view.setSelectedObjects([]level.ObjectID{id})
+	}
+	if depth < containerNestingLimit {
+		for nestedSlot, nestedID := range lvlobj.ContainerContents(lvl, id) {
+			if nestedID != 0 {
+				view.renderContainedObject(lvl, nestedSlot, nestedID, depth+1)
+			}
+		}
+	}
+}
+
+func (view *ObjectsView) requestCreateInContainer(lvl *level.Level, container level.ObjectID, slot int) {
+	id, err := lvlobj.NewContainedObject(lvl, container, slot, view.model.newObjectTriple)
+	if err != nil {
+		view.model.containerMessage = fmt.Sprintf("Could not create object: %v", err)
+		return
+	}
+	view.model.containerMessage = ""
+	obj := lvl.Object(id)
+	prop, err := view.mod.ObjectProperties().ForObject(obj.Triple())
+	if err == nil {
+		obj.Hitpoints = prop.Common.Hitpoints
+	}
+	view.patchLevel(lvl, view.model.selectedObjects.list, view.model.selectedObjects.list)
+}
+
+func (view *ObjectsView) requestDropFromContainer(lvl *level.Level, container level.ObjectID, slot int) {
+	holder := lvl.Object(container)
+	contents := lvlobj.ContainerContents(lvl, container)
+	if (holder == nil) || (slot >= len(contents)) {
+		return
+	}
+	id := contents[slot]
+	obj := lvl.Object(id)
+	if (obj != nil) && (obj.InUse != 0) {
+		obj.X = holder.X
+		obj.Y = holder.Y
+		obj.Z = holder.Z
+		lvl.UpdateObjectLocation(id)
+	}
+	lvlobj.SetContainerContent(lvl, container, slot, 0)
+	view.model.containerMessage = ""
+	view.patchLevel(lvl, []level.ObjectID{id}, view.model.selectedObjects.list)
+}
+
+func (view *ObjectsView) requestMoveToContainer(lvl *level.Level, id level.ObjectID, target level.ObjectID) {
+	if !view.putIntoContainer(lvl, id, target) {
+		return
+	}
+	view.patchLevel(lvl, []level.ObjectID{target}, view.model.selectedObjects.list)
+}
+
+func (view *ObjectsView) requestPutSelectedIntoContainer(lvl *level.Level, container level.ObjectID) {
+	changed := false
+	for _, id := range view.model.selectedObjects.list {
+		if id == container {
+			continue
+		}
+		if !view.putIntoContainer(lvl, id, container) {
+			break
+		}
+		changed = true
+	}
+	if changed {
+		view.patchLevel(lvl, []level.ObjectID{container}, view.model.selectedObjects.list)
+	}
+}
+
+// putIntoContainer stores the given object in the first free slot of the target container.
+// The object is taken out of any container it is currently in, or removed from the world.
+func (view *ObjectsView) putIntoContainer(lvl *level.Level, id level.ObjectID, target level.ObjectID) bool {
+	contents := lvlobj.ContainerContents(lvl, target)
+	if contents == nil {
+		view.model.containerMessage = fmt.Sprintf("Object %d is not a container", int(target))
+		return false
+	}
+	if lvlobj.IsWithin(lvl, target, id) {
+		view.model.containerMessage = fmt.Sprintf("Can not put object %d into container %d, which is within it", int(id), int(target))
+		return false
+	}
+	freeSlot := -1
+	for slot, content := range contents {
+		if content == id {
+			view.model.containerMessage = ""
+			return false
+		}
+		if (content == 0) && (freeSlot < 0) {
+			freeSlot = slot
+		}
+	}
+	if freeSlot < 0 {
+		view.model.containerMessage = fmt.Sprintf("Container %d is full", int(target))
+		return false
+	}
+	if holder, slot := lvlobj.ContainerOf(lvl, id); holder != 0 {
+		lvlobj.SetContainerContent(lvl, holder, slot, 0)
+	} else {
+		lvl.ClearObjectLocation(id)
+	}
+	lvlobj.SetContainerContent(lvl, target, freeSlot, id)
+	view.model.containerMessage = ""
+	return true
+}
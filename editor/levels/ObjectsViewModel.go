@@ -1,16 +1,40 @@
 package levels
 
-import "github.com/inkyblackness/hacked/ss1/content/object"
+import (
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+)
 
 type objectsViewModel struct {
 	selectedObjects objectIDs
 
 	newObjectTriple object.Triple
 
+	linkFilter ObjectLinkFilter
+
+	danglingIndex int
+	retargetID    int
+
+	containerTarget  int
+	containerMessage string
+
+	prefabLibrary lvlobj.PrefabLibrary
+	prefabsLoaded bool
+	prefabIndex   int
+	placePrefab   bool
+	prefabMessage string
+
 	restoreFocus bool
 	windowOpen   bool
 }
 
 func freshObjectsViewModel() objectsViewModel {
-	return objectsViewModel{}
+	return objectsViewModel{
+		danglingIndex: -1,
+		retargetID:    1,
+
+		containerTarget: 1,
+
+		prefabIndex: -1,
+	}
 }
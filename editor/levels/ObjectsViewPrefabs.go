@@ -0,0 +1,145 @@
+package levels
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/imgui-go"
+)
+
+const prefabLibraryFilename = "prefabs.json"
+
+func (view *ObjectsView) renderPrefabs(lvl *level.Level, readOnly bool) {
+	if !view.model.prefabsLoaded {
+		view.loadPrefabLibrary()
+	}
+	lib := &view.model.prefabLibrary
+	if view.model.prefabIndex >= len(lib.Prefabs) {
+		view.model.prefabIndex = -1
+	}
+	for index, prefab := range lib.Prefabs {
+		label := fmt.Sprintf("%s (%d objects)", prefab.Name, len(prefab.Objects))
+		if prefab.Cyberspace != lvl.IsCyberspace() {
+			label += " - other world"
+		}
+		if imgui.SelectableV(label, index == view.model.prefabIndex, 0, imgui.Vec2{}) {
+			view.model.prefabIndex = index
+		}
+	}
+	if len(lib.Prefabs) == 0 {
+		imgui.Text("No prefabs in library.")
+	}
+	imgui.Separator()
+	if len(view.model.selectedObjects.list) > 0 {
+		if imgui.Button("Save Selected as Prefab") {
+			view.savePrefab(lvl)
+		}
+	}
+	if view.model.prefabIndex >= 0 {
+		if imgui.Button("Delete Prefab") {
+			view.deletePrefab(view.model.prefabIndex)
+		}
+		if !readOnly {
+			imgui.Checkbox("Place prefab instead of new object", &view.model.placePrefab)
+		}
+	}
+	if imgui.Button("Reload Library") {
+		view.loadPrefabLibrary()
+	}
+	if len(view.model.prefabMessage) > 0 {
+		imgui.Text(view.model.prefabMessage)
+	}
+}
+
+// prefabLibraryPath returns the file of the library, which is stored with the mod.
+// Returns an empty string if the mod has not been saved yet.
+func (view *ObjectsView) prefabLibraryPath() string {
+	if len(view.mod.Path()) == 0 {
+		return ""
+	}
+	return filepath.Join(view.mod.Path(), prefabLibraryFilename)
+}
+
+func (view *ObjectsView) loadPrefabLibrary() {
+	view.model.prefabsLoaded = true
+	view.model.prefabIndex = -1
+	view.model.prefabLibrary = lvlobj.PrefabLibrary{}
+	filename := view.prefabLibraryPath()
+	if len(filename) == 0 {
+		view.model.prefabMessage = "Save the mod to keep a prefab library with it."
+		return
+	}
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		view.model.prefabMessage = ""
+		return
+	}
+	if err == nil {
+		view.model.prefabLibrary, err = lvlobj.PrefabLibraryFromJSON(data)
+	}
+	if err != nil {
+		view.model.prefabMessage = fmt.Sprintf("Could not load library: %v", err)
+		return
+	}
+	view.model.prefabMessage = ""
+}
+
+func (view *ObjectsView) storePrefabLibrary() bool {
+	filename := view.prefabLibraryPath()
+	if len(filename) == 0 {
+		view.model.prefabMessage = "Can not save library: the mod has no location yet. Save the mod first."
+		return false
+	}
+	err := ioutil.WriteFile(filename, view.model.prefabLibrary.JSON(), 0640)
+	if err != nil {
+		view.model.prefabMessage = fmt.Sprintf("Could not save library: %v", err)
+		return false
+	}
+	view.model.prefabMessage = "Library saved to " + filename
+	return true
+}
+
+func (view *ObjectsView) savePrefab(lvl *level.Level) {
+	ids := view.model.selectedObjects.list
+	first := lvl.Object(ids[0])
+	if first == nil {
+		return
+	}
+	name := view.tripleName(first.Triple())
+	if len(ids) > 1 {
+		name += fmt.Sprintf(" + %d", len(ids)-1)
+	}
+	lib := &view.model.prefabLibrary
+	lib.Prefabs = append(lib.Prefabs, lvlobj.NewPrefab(lvl, name, ids))
+	if !view.storePrefabLibrary() {
+		lib.Prefabs = lib.Prefabs[:len(lib.Prefabs)-1]
+		return
+	}
+	view.model.prefabIndex = len(lib.Prefabs) - 1
+}
+
+func (view *ObjectsView) deletePrefab(index int) {
+	lib := &view.model.prefabLibrary
+	prefabs := lib.Prefabs
+	lib.Prefabs = append(append([]lvlobj.Prefab{}, prefabs[:index]...), prefabs[index+1:]...)
+	if !view.storePrefabLibrary() {
+		lib.Prefabs = prefabs
+		return
+	}
+	view.model.prefabIndex = -1
+}
+
+func (view *ObjectsView) requestPlacePrefab(lvl *level.Level, pos MapPosition) {
+	prefab := view.model.prefabLibrary.Prefabs[view.model.prefabIndex]
+	ids, err := prefab.Instantiate(lvl, int(pos.X.Tile()), int(pos.Y.Tile()))
+	if err != nil {
+		view.model.prefabMessage = fmt.Sprintf("Could not place prefab: %v", err)
+		return
+	}
+	view.model.prefabMessage = ""
+	view.patchLevel(lvl, ids, view.model.selectedObjects.list)
+}
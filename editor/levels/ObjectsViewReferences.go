@@ -0,0 +1,59 @@
+package levels
+
+import (
+	"fmt"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/hacked/ui/gui"
+	"github.com/inkyblackness/imgui-go"
+)
+
+func (view *ObjectsView) renderReferenceCheck(lvl *level.Level, readOnly bool) {
+	dangling := lvlobj.DanglingReferences(lvl)
+	if len(dangling) == 0 {
+		imgui.Text("No dangling references found.")
+		return
+	}
+	if view.model.danglingIndex >= len(dangling) {
+		view.model.danglingIndex = -1
+	}
+	for index, ref := range dangling {
+		label := fmt.Sprintf("%3d: %s -> %3d (%v)", int(ref.Source), ref.Key, ref.ID, ref.Reason)
+		if imgui.SelectableV(label, index == view.model.danglingIndex, 0, imgui.Vec2{}) {
+			view.model.danglingIndex = index
+			view.setSelectedObjects([]level.ObjectID{ref.Source})
+		}
+	}
+	if readOnly {
+		return
+	}
+	imgui.Separator()
+	gui.StepSliderIntV("Retarget ID", &view.model.retargetID, 1, int(lvl.ObjectLimit()), "%d")
+	hasSelection := view.model.danglingIndex >= 0
+	if hasSelection {
+		if imgui.Button("Retarget Selected") {
+			view.requestSetReferences(lvl, dangling[view.model.danglingIndex:view.model.danglingIndex+1], level.ObjectID(view.model.retargetID))
+		}
+		imgui.SameLine()
+		if imgui.Button("Clear Selected") {
+			view.requestSetReferences(lvl, dangling[view.model.danglingIndex:view.model.danglingIndex+1], 0)
+		}
+		imgui.SameLine()
+	}
+	if imgui.Button("Clear All") {
+		view.requestSetReferences(lvl, dangling, 0)
+	}
+}
+
+func (view *ObjectsView) requestSetReferences(lvl *level.Level, references []lvlobj.DanglingReference, target level.ObjectID) {
+	var sources []level.ObjectID
+	for _, ref := range references {
+		lvlobj.SetObjectReference(lvl, ref.Source, ref.Key, target)
+		if len(sources) == 0 || sources[len(sources)-1] != ref.Source {
+			sources = append(sources, ref.Source)
+		}
+	}
+	view.model.danglingIndex = -1
+	view.patchLevel(lvl, sources, sources)
+}
@@ -0,0 +1,216 @@
+package levels
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+
+	"github.com/inkyblackness/hacked/editor/event"
+	"github.com/inkyblackness/hacked/editor/graphics"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlrender"
+	"github.com/inkyblackness/hacked/ss1/content/bitmap"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ui/gui"
+	"github.com/inkyblackness/hacked/ui/opengl"
+	"github.com/inkyblackness/imgui-go"
+)
+
+const (
+	overviewColumns       = 4
+	overviewPixelsPerTile = 4
+	overviewCellSize      = 64 * overviewPixelsPerTile
+	overviewPadding       = 16
+)
+
+var (
+	overviewBackgroundColor = color.RGBA{A: 0xFF}
+	overviewLinkColor       = color.RGBA{R: 0xFF, G: 0xD0, B: 0x00, A: 0xFF}
+	overviewActiveColor     = color.RGBA{R: 0x20, G: 0xC0, B: 0x40, A: 0xFF}
+)
+
+// OverviewView shows thumbnails of all levels, together with the connections between them.
+type OverviewView struct {
+	gl            opengl.OpenGL
+	guiScale      float32
+	eventListener event.Listener
+
+	texture *graphics.ImageTexture
+
+	model overviewViewModel
+}
+
+// NewOverviewView returns a new instance.
+func NewOverviewView(gl opengl.OpenGL, guiScale float32, eventListener event.Listener) *OverviewView {
+	return &OverviewView{
+		gl:            gl,
+		guiScale:      guiScale,
+		eventListener: eventListener,
+		model:         freshOverviewViewModel(),
+	}
+}
+
+// WindowOpen returns the flag address, to be used with the main menu.
+func (view *OverviewView) WindowOpen() *bool {
+	return &view.model.windowOpen
+}
+
+// InvalidateResources marks the overview outdated, as any of the resources could be part of it.
+func (view *OverviewView) InvalidateResources(modifiedIDs []resource.ID) {
+	if len(modifiedIDs) > 0 {
+		view.model.outdated = true
+	}
+}
+
+// Render renders the view.
+func (view *OverviewView) Render(levels []*level.Level, activeLevelID int) {
+	if !view.model.windowOpen {
+		return
+	}
+	if view.model.activeLevelID != activeLevelID {
+		view.model.activeLevelID = activeLevelID
+		view.model.outdated = true
+	}
+	imgui.SetNextWindowSizeV(imgui.Vec2{X: 600 * view.guiScale, Y: 700 * view.guiScale}, imgui.ConditionOnce)
+	if imgui.BeginV("Level Overview", view.WindowOpen(), 0) {
+		view.renderContent(levels)
+	}
+	imgui.End()
+}
+
+func (view *OverviewView) renderContent(levels []*level.Level) {
+	if view.model.outdated || (view.texture == nil) {
+		view.updateTexture(levels)
+	}
+	if view.texture != nil {
+		width, height := view.texture.Size()
+		imgui.ImageV(gui.TextureIDForSimpleTexture(view.texture.Handle()),
+			imgui.Vec2{X: width * view.guiScale / 2, Y: height * view.guiScale / 2},
+			imgui.Vec2{}, imgui.Vec2{X: 1, Y: 1},
+			imgui.Vec4{X: 1, Y: 1, Z: 1, W: 1}, imgui.Vec4{})
+	}
+	for index, lvl := range levels {
+		if (index % overviewColumns) != 0 {
+			imgui.SameLine()
+		}
+		if imgui.ButtonV(fmt.Sprintf("Level %2d", lvl.ID()), imgui.Vec2{X: 80 * view.guiScale, Y: 0}) {
+			view.openLevel(lvl.ID())
+		}
+	}
+	imgui.Separator()
+	imgui.Text("Connections")
+	for _, link := range view.model.links {
+		label := fmt.Sprintf("Level %2d -> Level %2d (%d objects)", link.from, link.to, link.count)
+		if imgui.SelectableV(label, link.from == view.model.activeLevelID, 0, imgui.Vec2{}) {
+			view.openLevel(link.from)
+		}
+	}
+}
+
+func (view *OverviewView) openLevel(id int) {
+	view.eventListener.Event(LevelSelectionSetEvent{id: id})
+}
+
+func (view *OverviewView) updateTexture(levels []*level.Level) {
+	view.model.outdated = false
+	view.model.links = levelLinks(levels)
+	if view.texture != nil {
+		view.texture.Dispose()
+	}
+	view.texture = graphics.NewImageTexture(view.gl, view.renderImage(levels))
+}
+
+func levelLinks(levels []*level.Level) []levelLink {
+	counts := make(map[[2]int]int)
+	for _, lvl := range levels {
+		interpreterFactory := lvlobj.ForRealWorld
+		if lvl.IsCyberspace() {
+			interpreterFactory = lvlobj.ForCyberspace
+		}
+		lvl.ForEachObject(func(id level.ObjectID, entry level.ObjectMasterEntry) {
+			data := lvl.ObjectClassData(id)
+			for _, destination := range lvlobj.LevelDestinations(interpreterFactory(entry.Triple(), data)) {
+				if (destination != lvl.ID()) && (destination >= 0) && (destination < len(levels)) {
+					counts[[2]int{lvl.ID(), destination}]++
+				}
+			}
+		})
+	}
+	links := make([]levelLink, 0, len(counts))
+	for key, count := range counts {
+		links = append(links, levelLink{from: key[0], to: key[1], count: count})
+	}
+	sort.Slice(links, func(a, b int) bool {
+		if links[a].from != links[b].from {
+			return links[a].from < links[b].from
+		}
+		return links[a].to < links[b].to
+	})
+	return links
+}
+
+func overviewCellOrigin(id int) image.Point {
+	return image.Point{
+		X: overviewPadding + (id%overviewColumns)*(overviewCellSize+overviewPadding),
+		Y: overviewPadding + (id/overviewColumns)*(overviewCellSize+overviewPadding),
+	}
+}
+
+func (view *OverviewView) renderImage(levels []*level.Level) *image.RGBA {
+	rows := (len(levels) + overviewColumns - 1) / overviewColumns
+	size := image.Point{
+		X: overviewPadding + overviewColumns*(overviewCellSize+overviewPadding),
+		Y: overviewPadding + rows*(overviewCellSize+overviewPadding),
+	}
+	img := image.NewRGBA(image.Rectangle{Max: size})
+	draw.Draw(img, img.Bounds(), image.NewUniform(overviewBackgroundColor), image.Point{}, draw.Src)
+
+	renderer := lvlrender.NewMapRenderer(bitmap.Palette{}, nil)
+	for _, lvl := range levels {
+		origin := overviewCellOrigin(lvl.ID())
+		if lvl.ID() == view.model.activeLevelID {
+			frame := image.Rectangle{Min: origin, Max: origin.Add(image.Point{X: overviewCellSize, Y: overviewCellSize})}
+			draw.Draw(img, frame.Inset(-4), image.NewUniform(overviewActiveColor), image.Point{}, draw.Src)
+		}
+		thumbnail := renderer.Render(lvl, lvlrender.MapImageOptions{PixelsPerTile: overviewPixelsPerTile})
+		cell := image.Rectangle{Min: origin, Max: origin.Add(image.Point{X: overviewCellSize, Y: overviewCellSize})}
+		draw.Draw(img, cell, thumbnail, image.Point{}, draw.Src)
+	}
+	half := overviewCellSize / 2
+	for _, link := range view.model.links {
+		from := overviewCellOrigin(link.from).Add(image.Point{X: half, Y: half})
+		to := overviewCellOrigin(link.to).Add(image.Point{X: half, Y: half})
+		drawLine(img, from, to, overviewLinkColor)
+		marker := image.Rectangle{Min: to, Max: to}.Inset(-6)
+		draw.Draw(img, marker, image.NewUniform(overviewLinkColor), image.Point{}, draw.Src)
+	}
+	return img
+}
+
+func drawLine(img *image.RGBA, from, to image.Point, col color.RGBA) {
+	deltaX, deltaY := to.X-from.X, to.Y-from.Y
+	steps := deltaX
+	if steps < 0 {
+		steps = -steps
+	}
+	if (deltaY > steps) || (-deltaY > steps) {
+		steps = deltaY
+		if steps < 0 {
+			steps = -steps
+		}
+	}
+	for step := 0; step <= steps; step++ {
+		x, y := from.X, from.Y
+		if steps > 0 {
+			x += deltaX * step / steps
+			y += deltaY * step / steps
+		}
+		for offset := -1; offset <= 1; offset++ {
+			img.SetRGBA(x+offset, y, col)
+			img.SetRGBA(x, y+offset, col)
+		}
+	}
+}
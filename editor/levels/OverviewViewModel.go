@@ -0,0 +1,22 @@
+package levels
+
+type levelLink struct {
+	from  int
+	to    int
+	count int
+}
+
+type overviewViewModel struct {
+	links         []levelLink
+	activeLevelID int
+	outdated      bool
+
+	windowOpen bool
+}
+
+func freshOverviewViewModel() overviewViewModel {
+	return overviewViewModel{
+		activeLevelID: -1,
+		outdated:      true,
+	}
+}
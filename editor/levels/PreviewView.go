@@ -0,0 +1,164 @@
+package levels
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+
+	"github.com/inkyblackness/hacked/editor/event"
+	"github.com/inkyblackness/hacked/editor/graphics"
+	"github.com/inkyblackness/hacked/editor/model"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlrender"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ui/gui"
+	"github.com/inkyblackness/hacked/ui/opengl"
+	"github.com/inkyblackness/imgui-go"
+)
+
+const (
+	previewWidth        = 320
+	previewHeight       = 240
+	previewExportWidth  = 1280
+	previewExportHeight = 960
+)
+
+// PreviewView shows a software-rendered first-person view of a level.
+type PreviewView struct {
+	mod      *model.Mod
+	gl       opengl.OpenGL
+	guiScale float32
+
+	texture *graphics.ImageTexture
+
+	model previewViewModel
+}
+
+// NewPreviewView returns a new instance.
+func NewPreviewView(mod *model.Mod, gl opengl.OpenGL, guiScale float32, eventRegistry event.Registry) *PreviewView {
+	view := &PreviewView{
+		mod:      mod,
+		gl:       gl,
+		guiScale: guiScale,
+		model:    freshPreviewViewModel(),
+	}
+	view.model.selectedTiles.registerAt(eventRegistry)
+	return view
+}
+
+// WindowOpen returns the flag address, to be used with the main menu.
+func (view *PreviewView) WindowOpen() *bool {
+	return &view.model.windowOpen
+}
+
+// InvalidateResources marks the preview outdated, as any of the resources could be part of it.
+func (view *PreviewView) InvalidateResources(modifiedIDs []resource.ID) {
+	if len(modifiedIDs) > 0 {
+		view.model.outdated = true
+	}
+}
+
+// Render renders the view.
+func (view *PreviewView) Render(lvl *level.Level, paletteTexture *graphics.PaletteTexture) {
+	if !view.model.windowOpen {
+		return
+	}
+	if view.model.levelID != lvl.ID() {
+		view.model.levelID = lvl.ID()
+		view.model.cameraValid = false
+		view.model.outdated = true
+	}
+	imgui.SetNextWindowSizeV(imgui.Vec2{X: 360 * view.guiScale, Y: 480 * view.guiScale}, imgui.ConditionOnce)
+	if imgui.BeginV("Level Preview", view.WindowOpen(), 0) {
+		view.renderContent(lvl, paletteTexture)
+	}
+	imgui.End()
+}
+
+func (view *PreviewView) renderContent(lvl *level.Level, paletteTexture *graphics.PaletteTexture) {
+	if len(view.model.selectedTiles.list) > 0 {
+		if imgui.Button("Place at Selected Tile") {
+			pos := view.model.selectedTiles.list[0]
+			heading := view.model.camera.Heading
+			view.model.camera = lvlrender.CameraAt(lvl, int(pos.X.Tile()), int(pos.Y.Tile()), heading)
+			view.model.cameraValid = true
+			view.model.outdated = true
+		}
+	} else if !view.model.cameraValid {
+		imgui.Text("Select a tile to place the camera.")
+	}
+	if !view.model.cameraValid {
+		return
+	}
+
+	imgui.PushItemWidth(-150 * view.guiScale)
+	view.renderAngleSlider("Heading", &view.model.camera.Heading, 0, 359)
+	view.renderAngleSlider("Pitch", &view.model.camera.Pitch, -60, 60)
+	view.renderAngleSlider("Field of View", &view.model.camera.FieldOfView, 30, 120)
+	imgui.PopItemWidth()
+
+	if imgui.Button("Refresh") {
+		view.model.outdated = true
+	}
+	imgui.SameLine()
+	if imgui.Button("Export PNG") {
+		view.exportImage(lvl, paletteTexture)
+	}
+	if len(view.model.message) > 0 {
+		imgui.Text(view.model.message)
+	}
+
+	if view.model.outdated || (view.texture == nil) {
+		view.updateTexture(lvl, paletteTexture)
+	}
+	if view.texture != nil {
+		width, height := view.texture.Size()
+		imgui.ImageV(gui.TextureIDForSimpleTexture(view.texture.Handle()),
+			imgui.Vec2{X: width * view.guiScale, Y: height * view.guiScale},
+			imgui.Vec2{}, imgui.Vec2{X: 1, Y: 1},
+			imgui.Vec4{X: 1, Y: 1, Z: 1, W: 1}, imgui.Vec4{})
+	}
+}
+
+func (view *PreviewView) renderAngleSlider(label string, value *float32, min, max int) {
+	intValue := int(*value)
+	if gui.StepSliderIntV(label, &intValue, min, max, "%d°") {
+		*value = float32(intValue)
+		view.model.outdated = true
+	}
+}
+
+func (view *PreviewView) renderImage(lvl *level.Level, paletteTexture *graphics.PaletteTexture, width, height int) *image.RGBA {
+	renderer := lvlrender.NewFirstPersonRenderer(paletteTexture.Palette(), lvlrender.TexturesFrom(view.mod))
+	return renderer.Render(lvl, view.model.camera, width, height)
+}
+
+func (view *PreviewView) updateTexture(lvl *level.Level, paletteTexture *graphics.PaletteTexture) {
+	view.model.outdated = false
+	if view.texture != nil {
+		view.texture.Dispose()
+		view.texture = nil
+	}
+	if paletteTexture == nil {
+		return
+	}
+	view.texture = graphics.NewImageTexture(view.gl, view.renderImage(lvl, paletteTexture, previewWidth, previewHeight))
+}
+
+func (view *PreviewView) exportImage(lvl *level.Level, paletteTexture *graphics.PaletteTexture) {
+	if paletteTexture == nil {
+		view.model.message = "No palette available."
+		return
+	}
+	filename, err := filepath.Abs(fmt.Sprintf("level%02d-preview.png", lvl.ID()))
+	if err != nil {
+		view.model.message = fmt.Sprintf("Export failed: %v", err)
+		return
+	}
+	err = lvlrender.SavePNG(filename, view.renderImage(lvl, paletteTexture, previewExportWidth, previewExportHeight))
+	if err != nil {
+		view.model.message = fmt.Sprintf("Export failed: %v", err)
+		return
+	}
+	view.model.message = "Exported to " + filename
+}
@@ -0,0 +1,22 @@
+package levels
+
+import "github.com/inkyblackness/hacked/ss1/content/archive/level/lvlrender"
+
+type previewViewModel struct {
+	selectedTiles tileCoordinates
+
+	camera      lvlrender.Camera
+	levelID     int
+	cameraValid bool
+	outdated    bool
+	message     string
+
+	windowOpen bool
+}
+
+func freshPreviewViewModel() previewViewModel {
+	return previewViewModel{
+		levelID:  -1,
+		outdated: true,
+	}
+}
@@ -3,7 +3,7 @@ package levels
 import (
 	"fmt"
 
-	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlrender"
 )
 
 // TextureDisplay is an enumeration which texture to display in a 2D map view.
@@ -35,30 +35,12 @@ func TextureDisplays() []TextureDisplay {
 	return []TextureDisplay{TextureDisplayFloor, TextureDisplayWall, TextureDisplayCeiling}
 }
 
-// TextureDisplayFunc is one that resolves texture properties.
-type TextureDisplayFunc func(*level.TileMapEntry) (atlasIndex int, textureRotations int)
-
-// FloorTexture returns the properties for the floor.
-func FloorTexture(tile *level.TileMapEntry) (atlasIndex int, textureRotations int) {
-	return tile.TextureInfo.FloorTextureIndex(), tile.Floor.TextureRotations()
-}
-
-// CeilingTexture returns the properties for the ceiling.
-func CeilingTexture(tile *level.TileMapEntry) (atlasIndex int, textureRotations int) {
-	return tile.TextureInfo.CeilingTextureIndex(), tile.Ceiling.TextureRotations()
-}
-
-// WallTexture returns the properties for the floor.
-func WallTexture(tile *level.TileMapEntry) (atlasIndex int, textureRotations int) {
-	return tile.TextureInfo.WallTextureIndex(), 0
-}
-
 // Func returns the display func for the current display setting.
-func (display TextureDisplay) Func() TextureDisplayFunc {
+func (display TextureDisplay) Func() lvlrender.TileTextureFunc {
 	if display == TextureDisplayFloor {
-		return FloorTexture
+		return lvlrender.FloorTexture
 	} else if display == TextureDisplayCeiling {
-		return CeilingTexture
+		return lvlrender.CeilingTexture
 	}
-	return WallTexture
+	return lvlrender.WallTexture
 }
@@ -0,0 +1,7 @@
+package levels
+
+// TilePaintRequestEvent for requesting to paint the given tiles with the current template.
+// A single event is sent per completed stroke of a painting map tool.
+type TilePaintRequestEvent struct {
+	Tiles []MapPosition
+}
@@ -2,9 +2,11 @@ package levels
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/inkyblackness/hacked/editor/cmd"
 	"github.com/inkyblackness/hacked/editor/event"
+	"github.com/inkyblackness/hacked/editor/external"
 	"github.com/inkyblackness/hacked/editor/graphics"
 	"github.com/inkyblackness/hacked/editor/model"
 	"github.com/inkyblackness/hacked/editor/render"
@@ -12,9 +14,13 @@ import (
 	"github.com/inkyblackness/hacked/ss1/content/archive"
 	"github.com/inkyblackness/hacked/ss1/content/archive/level"
 	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlids"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlrender"
+	"github.com/inkyblackness/hacked/ss1/content/bitmap"
 	"github.com/inkyblackness/hacked/ss1/content/text"
 	"github.com/inkyblackness/hacked/ss1/resource"
 	"github.com/inkyblackness/hacked/ss1/world/ids"
+	"github.com/inkyblackness/hacked/ui/gui"
 	"github.com/inkyblackness/imgui-go"
 )
 
@@ -23,6 +29,9 @@ type TilesView struct {
 	mod          *model.Mod
 	textCache    *text.Cache
 	textureCache *graphics.TextureCache
+	clipboard    external.Clipboard
+
+	modalStateMachine gui.ModalStateMachine
 
 	guiScale      float32
 	commander     cmd.Commander
@@ -33,11 +42,15 @@ type TilesView struct {
 
 // NewTilesView returns a new instance.
 func NewTilesView(mod *model.Mod, guiScale float32, textCache *text.Cache, textureCache *graphics.TextureCache,
+	clipboard external.Clipboard, modalStateMachine gui.ModalStateMachine,
 	commander cmd.Commander, eventListener event.Listener, eventRegistry event.Registry) *TilesView {
 	view := &TilesView{
 		mod:          mod,
 		textCache:    textCache,
 		textureCache: textureCache,
+		clipboard:    clipboard,
+
+		modalStateMachine: modalStateMachine,
 
 		guiScale:      guiScale,
 		commander:     commander,
@@ -66,6 +79,47 @@ func (view TilesView) ColorDisplay(lvl *level.Level) ColorDisplay {
 	return view.model.shadowDisplay
 }
 
+// InvalidateResources marks the shadows of the lighting preview outdated, as any of the resources could affect them.
+func (view *TilesView) InvalidateResources(modifiedIDs []resource.ID) {
+	if len(modifiedIDs) > 0 {
+		view.model.shadowPreview.valid = false
+	}
+}
+
+// ColorQuery returns the query for coloring the tiles in the map display.
+// While the lighting preview is active, the computed shadows are shown instead of the current ones.
+// The shadows are only computed again if the level or the lighting parameters change.
+func (view *TilesView) ColorQuery(lvl *level.Level, palette bitmap.Palette) ColorQuery {
+	if view.model.lightingPreview && !lvl.IsCyberspace() {
+		param := view.model.lightingParameters
+		if !view.model.shadowPreview.isFor(lvl, param) {
+			view.model.shadowPreview = shadowPreviewCache{
+				valid:     true,
+				lvl:       lvl,
+				ambient:   param.Ambient,
+				intensity: param.Intensity,
+				radius:    param.Radius,
+				shadows:   lvl.ComputeShadows(param),
+			}
+		}
+		shadows := view.model.shadowPreview.shadows
+		return func(x, y int) [4]float32 {
+			return [4]float32{0.0, 0.0, 0.0, float32(shadows.Shadow(x, y)) / float32(level.ShadowMax)}
+		}
+	}
+	return view.ColorDisplay(lvl).QueryFor(lvl, palette)
+}
+
+// MapTool returns the currently selected tool for the map display.
+func (view TilesView) MapTool() MapTool {
+	return view.model.mapTool
+}
+
+// MapSnap returns the current setting how dragged objects are aligned.
+func (view TilesView) MapSnap() MapSnap {
+	return view.model.mapSnap
+}
+
 // Render renders the view.
 func (view *TilesView) Render(lvl *level.Level) {
 	if view.model.restoreFocus {
@@ -152,6 +206,8 @@ func (view *TilesView) renderContent(lvl *level.Level, readOnly bool) {
 	_, _, levelHeight := lvl.Size()
 	tileHeightFormatter := tileHeightFormatterFor(levelHeight)
 
+	view.renderMapTool(lvl, readOnly)
+
 	tileTypes := level.TileTypes()
 	values.RenderUnifiedCombo(readOnly, multiple, "Tile Type", tileTypeUnifier,
 		func(u values.Unifier) int { return int(u.Unified().(level.TileType)) },
@@ -316,6 +372,15 @@ func (view *TilesView) renderContent(lvl *level.Level, readOnly bool) {
 			func(newValue int) {
 				view.requestWallTextureOffset(lvl, view.model.selectedTiles.list, level.TileHeightUnit(newValue))
 			})
+		if !readOnly {
+			if imgui.Button("Align Walls") {
+				view.requestAlignWalls(lvl, view.model.selectedTiles.list)
+			}
+			if imgui.IsItemHovered() {
+				imgui.SetTooltip("Set wall texture offsets of connected tiles to line up with the first selected tile.\n" +
+					"A single tile is aligned to its first connected neighbor.")
+			}
+		}
 
 		values.RenderUnifiedCheckboxCombo(readOnly, multiple, "Use Adjacent Wall Texture", useAdjacentWallTextureUnifier,
 			func(newValue bool) {
@@ -358,6 +423,7 @@ func (view *TilesView) renderContent(lvl *level.Level, readOnly bool) {
 			func(newValue int) {
 				view.requestCeilingLight(lvl, view.model.selectedTiles.list, newValue)
 			})
+		view.renderLighting(lvl, readOnly)
 
 		imgui.Separator()
 
@@ -378,6 +444,158 @@ func (view *TilesView) renderContent(lvl *level.Level, readOnly bool) {
 	imgui.PopItemWidth()
 }
 
+func (view *TilesView) renderMapTool(lvl *level.Level, readOnly bool) {
+	if imgui.BeginCombo("Map Tool", view.model.mapTool.String()) {
+		for _, tool := range MapTools() {
+			if imgui.SelectableV(tool.String(), tool == view.model.mapTool, 0, imgui.Vec2{}) {
+				view.model.mapTool = tool
+			}
+		}
+		imgui.EndCombo()
+	}
+	if view.model.mapTool == MapToolMove {
+		if imgui.BeginCombo("Object Snapping", view.model.mapSnap.String()) {
+			for _, snap := range MapSnaps() {
+				if imgui.SelectableV(snap.String(), snap == view.model.mapSnap, 0, imgui.Vec2{}) {
+					view.model.mapSnap = snap
+				}
+			}
+			imgui.EndCombo()
+		}
+	}
+	if view.model.mapTool == MapToolPatrol {
+		imgui.Text("Select a critter, then click to place its patrol tile.")
+		imgui.Text("Drag the patrol tile to move it.")
+		imgui.Text("Critters patrol between their position and their patrol tile.")
+	}
+	templateInfo := "(none)"
+	if view.model.paintTemplateSet {
+		template := &view.model.paintTemplate
+		templateInfo = fmt.Sprintf("%v, F %d, C %d", template.Type,
+			template.Floor.AbsoluteHeight(), template.Ceiling.AbsoluteHeight())
+	}
+	imgui.LabelText("Paint Template", templateInfo)
+	if len(view.model.selectedTiles.list) > 0 {
+		if imgui.Button("Use First Selected Tile as Template") {
+			pos := view.model.selectedTiles.list[0]
+			tile := lvl.Tile(int(pos.X.Tile()), int(pos.Y.Tile()))
+			if tile != nil {
+				view.model.paintTemplate = *tile
+				view.model.paintTemplateSet = true
+			}
+		}
+	}
+	if readOnly && view.model.mapTool.IsPainting() {
+		imgui.Text("Level is read-only, painting disabled.")
+	} else if view.model.mapTool.IsPainting() && !view.model.paintTemplateSet {
+		imgui.Text("No paint template set, the first painted tile is used.")
+	}
+	if len(view.model.selectedTiles.list) > 0 {
+		if imgui.Button("Region -> Clip") {
+			view.copyRegionToClipboard(lvl)
+		}
+		if !readOnly {
+			imgui.SameLine()
+			if imgui.Button("Region <- Clip") {
+				view.requestPasteRegionFromClipboard(lvl)
+			}
+			if imgui.BeginCombo("Transform Region", "") {
+				for _, transform := range level.RegionTransforms() {
+					if imgui.Selectable(transform.String()) {
+						view.requestTransformRegion(lvl, transform)
+					}
+				}
+				imgui.EndCombo()
+			}
+		}
+	}
+	if len(view.model.regionMessage) > 0 {
+		imgui.Text(view.model.regionMessage)
+	}
+	if len(view.model.exportMessage) > 0 {
+		imgui.Text(view.model.exportMessage)
+	}
+	imgui.Separator()
+	view.renderLayoutImport(lvl, readOnly)
+}
+
+func selectionBounds(positions []MapPosition) (fromX, fromY, toX, toY int) {
+	for index, pos := range positions {
+		x, y := int(pos.X.Tile()), int(pos.Y.Tile())
+		if (index == 0) || (x < fromX) {
+			fromX = x
+		}
+		if (index == 0) || (y < fromY) {
+			fromY = y
+		}
+		if (index == 0) || (x > toX) {
+			toX = x
+		}
+		if (index == 0) || (y > toY) {
+			toY = y
+		}
+	}
+	return
+}
+
+func (view *TilesView) copyRegionToClipboard(lvl *level.Level) {
+	fromX, fromY, toX, toY := selectionBounds(view.model.selectedTiles.list)
+	region := lvl.CopyRegion(fromX, fromY, toX-fromX+1, toY-fromY+1)
+	view.clipboard.SetString(region.Text())
+	view.model.regionMessage = fmt.Sprintf("Copied %dx%d tiles, %d objects.", region.Width, region.Height, len(region.Objects))
+}
+
+// ExportMapImage writes an image of the whole level into the current directory,
+// using the current texture and color display settings of the view.
+func (view *TilesView) ExportMapImage(lvl *level.Level, palette bitmap.Palette, pixelsPerTile int) {
+	view.model.restoreFocus = true
+	options := lvlrender.MapImageOptions{
+		PixelsPerTile: pixelsPerTile,
+		TextureFunc:   view.model.textureDisplay.Func(),
+		ColorFunc:     lvlrender.TileColorFunc(view.ColorDisplay(lvl).QueryFor(lvl, palette)),
+		ObjectIcons:   lvlrender.ObjectIconsFrom(view.mod, view.mod.ObjectProperties()),
+	}
+	renderer := lvlrender.NewMapRenderer(palette, lvlrender.TexturesFrom(view.mod))
+	filename, err := filepath.Abs(fmt.Sprintf("level%02d-map.png", lvl.ID()))
+	if err == nil {
+		err = lvlrender.SavePNG(filename, renderer.Render(lvl, options))
+	}
+	if err != nil {
+		view.model.exportMessage = fmt.Sprintf("Export failed: %v", err)
+		return
+	}
+	view.model.exportMessage = "Exported to " + filename
+}
+
+func (view *TilesView) requestPasteRegionFromClipboard(lvl *level.Level) {
+	value, err := view.clipboard.String()
+	if err != nil {
+		view.model.regionMessage = "Clipboard not available."
+		return
+	}
+	region, err := level.RegionFromText(value)
+	if err != nil {
+		view.model.regionMessage = "Clipboard does not contain a region."
+		return
+	}
+	toX, toY, _, _ := selectionBounds(view.model.selectedTiles.list)
+	_, err = lvlobj.PasteRegion(lvl, region, toX, toY)
+	if err != nil {
+		view.model.regionMessage = fmt.Sprintf("Can not paste: %v", err)
+		return
+	}
+	var positions []MapPosition
+	for y := 0; y < region.Height; y++ {
+		for x := 0; x < region.Width; x++ {
+			if lvl.Tile(toX+x, toY+y) != nil {
+				positions = append(positions, MapPosition{X: level.CoordinateAt(byte(toX+x), 128), Y: level.CoordinateAt(byte(toY+y), 128)})
+			}
+		}
+	}
+	view.model.regionMessage = ""
+	view.patchLevel(lvl, positions)
+}
+
 func (view *TilesView) renderTextureSelector(readOnly, multiple bool, label string, unifier values.Unifier,
 	atlas level.TextureAtlas, minIndex, maxIndex int, changeHandler func(int)) {
 	selectedIndex := -1
@@ -420,6 +638,67 @@ func (view *TilesView) editingAllowed(id int) bool {
 	return moddedLevel && !isSavegame
 }
 
+// RequestPaintTiles applies the current paint template to the given tiles.
+// All tiles are changed with one command, so that a painting stroke can be undone at once.
+// Without a template, the tile at the start of the stroke becomes the template.
+func (view *TilesView) RequestPaintTiles(lvl *level.Level, positions []MapPosition) {
+	if !view.editingAllowed(lvl.ID()) || (len(positions) == 0) {
+		return
+	}
+	if !view.model.paintTemplateSet {
+		tile := lvl.Tile(int(positions[0].X.Tile()), int(positions[0].Y.Tile()))
+		if tile == nil {
+			return
+		}
+		view.model.paintTemplate = *tile
+		view.model.paintTemplateSet = true
+	}
+	validPositions := make([]MapPosition, 0, len(positions))
+	for _, pos := range positions {
+		if lvl.Tile(int(pos.X.Tile()), int(pos.Y.Tile())) != nil {
+			validPositions = append(validPositions, pos)
+		}
+	}
+	if len(validPositions) == 0 {
+		return
+	}
+	template := view.model.paintTemplate
+	isCyberspace := lvl.IsCyberspace()
+	view.changeTiles(lvl, validPositions, func(tile *level.TileMapEntry) {
+		tile.Type = template.Type
+		tile.Floor = template.Floor
+		tile.Ceiling = template.Ceiling
+		tile.SlopeHeight = template.SlopeHeight
+		tile.TextureInfo = template.TextureInfo
+		tile.Flags = tile.Flags.WithSlopeControl(template.Flags.SlopeControl())
+		if !isCyberspace {
+			templateFlags := template.Flags.ForRealWorld()
+			tile.Flags = tile.Flags.ForRealWorld().
+				WithWallTextureOffset(templateFlags.WallTextureOffset()).
+				WithUseAdjacentWallTexture(templateFlags.UseAdjacentWallTexture()).
+				WithWallTexturePattern(templateFlags.WallTexturePattern()).
+				AsTileFlag()
+		}
+	})
+}
+
+func (view *TilesView) requestTransformRegion(lvl *level.Level, transform level.RegionTransform) {
+	fromX, fromY, toX, toY := selectionBounds(view.model.selectedTiles.list)
+	err := lvl.TransformRegion(fromX, fromY, toX-fromX+1, toY-fromY+1, transform)
+	if err != nil {
+		view.model.regionMessage = fmt.Sprintf("Can not transform: %v", err)
+		return
+	}
+	var positions []MapPosition
+	for y := fromY; y <= toY; y++ {
+		for x := fromX; x <= toX; x++ {
+			positions = append(positions, MapPosition{X: level.CoordinateAt(byte(x), 128), Y: level.CoordinateAt(byte(y), 128)})
+		}
+	}
+	view.model.regionMessage = ""
+	view.patchLevel(lvl, positions)
+}
+
 func (view *TilesView) requestSetTileType(lvl *level.Level, positions []MapPosition, tileType level.TileType) {
 	view.changeTiles(lvl, positions, func(tile *level.TileMapEntry) {
 		tile.Type = tileType
@@ -492,6 +771,15 @@ func (view *TilesView) requestWallTextureOffset(lvl *level.Level, positions []Ma
 	})
 }
 
+func (view *TilesView) requestAlignWalls(lvl *level.Level, positions []MapPosition) {
+	tiles := make([]level.TilePosition, 0, len(positions))
+	for _, pos := range positions {
+		tiles = append(tiles, level.TilePosition{X: int(pos.X.Tile()), Y: int(pos.Y.Tile())})
+	}
+	lvl.AlignWallTextures(tiles)
+	view.patchLevel(lvl, positions)
+}
+
 func (view *TilesView) requestUseAdjacentWallTexture(lvl *level.Level, positions []MapPosition, value bool) {
 	view.changeTiles(lvl, positions, func(tile *level.TileMapEntry) {
 		tile.Flags = tile.Flags.ForRealWorld().WithUseAdjacentWallTexture(value).AsTileFlag()
@@ -504,6 +792,32 @@ func (view *TilesView) requestWallTexturePattern(lvl *level.Level, positions []M
 	})
 }
 
+func (view *TilesView) renderLighting(lvl *level.Level, readOnly bool) {
+	if !imgui.TreeNode("Automatic Lighting") {
+		return
+	}
+	param := &view.model.lightingParameters
+	gui.StepSliderIntV("Ambient Light", &param.Ambient, 0, level.ShadowMax, "%d")
+	gui.StepSliderIntV("Source Intensity", &param.Intensity, 0, level.ShadowMax, "%d")
+	radius := int(param.Radius)
+	if gui.StepSliderIntV("Source Radius", &radius, 1, 32, "%d tiles") {
+		param.Radius = float32(radius)
+	}
+	imgui.Checkbox("Preview in Map", &view.model.lightingPreview)
+	if !readOnly {
+		if imgui.Button("Apply Lighting") {
+			view.requestApplyLighting(lvl)
+		}
+	}
+	imgui.TreePop()
+}
+
+func (view *TilesView) requestApplyLighting(lvl *level.Level) {
+	lvl.ApplyShadows(lvl.ComputeShadows(view.model.lightingParameters))
+	view.model.lightingPreview = false
+	view.patchLevel(lvl, view.model.selectedTiles.list)
+}
+
 func (view *TilesView) requestFloorLight(lvl *level.Level, positions []MapPosition, value int) {
 	view.changeTiles(lvl, positions, func(tile *level.TileMapEntry) {
 		tile.Flags = tile.Flags.ForRealWorld().WithFloorShadow(15 - value).AsTileFlag()
@@ -563,7 +877,10 @@ func (view *TilesView) changeTiles(lvl *level.Level, positions []MapPosition, mo
 		tile := lvl.Tile(int(pos.X.Tile()), int(pos.Y.Tile()))
 		modifier(tile)
 	}
+	view.patchLevel(lvl, positions)
+}
 
+func (view *TilesView) patchLevel(lvl *level.Level, positions []MapPosition) {
 	command := patchLevelDataCommand{
 		restoreState: func(bool) {
 			view.model.restoreFocus = true
@@ -0,0 +1,134 @@
+package levels
+
+import (
+	"fmt"
+	"image"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/inkyblackness/hacked/editor/external"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ui/gui"
+	"github.com/inkyblackness/imgui-go"
+)
+
+func (view *TilesView) renderLayoutImport(lvl *level.Level, readOnly bool) {
+	if !imgui.TreeNode("Layout Import") {
+		return
+	}
+	mapping := view.model.layoutMapping
+	if imgui.BeginCombo("Mapping Key", view.model.layoutMappingKey) {
+		for _, key := range mapping.Keys() {
+			if imgui.SelectableV(key, key == view.model.layoutMappingKey, 0, imgui.Vec2{}) {
+				view.model.layoutMappingKey = key
+			}
+		}
+		imgui.EndCombo()
+	}
+	if entry, existing := mapping[view.model.layoutMappingKey]; existing {
+		if imgui.BeginCombo("Mapped Type", entry.Type.String()) {
+			for _, tileType := range level.TileTypes() {
+				if imgui.SelectableV(tileType.String(), tileType == entry.Type, 0, imgui.Vec2{}) {
+					entry.Type = tileType
+				}
+			}
+			imgui.EndCombo()
+		}
+		floorHeight := int(entry.FloorHeight)
+		if gui.StepSliderIntV("Mapped Floor Height", &floorHeight, int(level.TileHeightUnitMin), int(level.TileHeightUnitMax)-1, "%d") {
+			entry.FloorHeight = level.TileHeightUnit(floorHeight)
+		}
+		ceilingHeight := int(entry.CeilingHeight)
+		if gui.StepSliderIntV("Mapped Ceiling Height", &ceilingHeight, int(level.TileHeightUnitMin)+1, int(level.TileHeightUnitMax), "%d") {
+			entry.CeilingHeight = level.TileHeightUnit(ceilingHeight)
+		}
+		gui.StepSliderIntV("Mapped Texture Index", &entry.TextureIndex, -1, level.FloorCeilingTextureLimit-1, "%d")
+		mapping[view.model.layoutMappingKey] = entry
+	}
+	if imgui.Button("Mapping -> Clip") {
+		view.clipboard.SetString(mapping.Text())
+	}
+	imgui.SameLine()
+	if imgui.Button("Mapping <- Clip") {
+		view.pasteLayoutMappingFromClipboard()
+	}
+	imgui.SameLine()
+	if imgui.Button("Reset Mapping") {
+		view.model.layoutMapping = level.DefaultLayoutMapping()
+	}
+	if !readOnly {
+		if imgui.Button("Import Layout...") {
+			view.requestImportLayout(lvl)
+		}
+	}
+	if len(view.model.layoutMessage) > 0 {
+		imgui.Text(view.model.layoutMessage)
+	}
+	imgui.TreePop()
+}
+
+func (view *TilesView) pasteLayoutMappingFromClipboard() {
+	value, err := view.clipboard.String()
+	if err != nil {
+		view.model.layoutMessage = "Clipboard not available."
+		return
+	}
+	mapping, err := level.LayoutMappingFromText(value)
+	if err != nil {
+		view.model.layoutMessage = fmt.Sprintf("Clipboard does not contain a mapping: %v", err)
+		return
+	}
+	view.model.layoutMapping = mapping
+	view.model.layoutMessage = fmt.Sprintf("Mapping with %d keys set.", len(mapping))
+}
+
+func (view *TilesView) requestImportLayout(lvl *level.Level) {
+	info := "File should be either a PNG/GIF image, a CSV grid (.csv), or a text grid.\n" +
+		"The layout must cover the whole map, the top of the layout is north."
+	var fileHandler func(string)
+
+	fileHandler = func(filename string) {
+		layout, err := layoutFromFile(filename)
+		if err != nil {
+			external.Import(view.modalStateMachine, "Could not read file.\n"+info, fileHandler, true)
+			return
+		}
+		modified, err := lvl.ApplyLayout(layout, view.model.layoutMapping)
+		if err != nil {
+			view.model.layoutMessage = fmt.Sprintf("Can not apply layout: %v", err)
+			return
+		}
+		view.model.layoutMessage = fmt.Sprintf("Applied %dx%d layout, %d tiles modified.", layout.Width, layout.Height, modified)
+		if modified > 0 {
+			view.patchLevel(lvl, view.model.selectedTiles.list)
+		}
+	}
+
+	external.Import(view.modalStateMachine, info, fileHandler, false)
+}
+
+func layoutFromFile(filename string) (level.Layout, error) {
+	extension := strings.ToLower(filepath.Ext(filename))
+	if (extension == ".png") || (extension == ".gif") {
+		reader, err := os.Open(filename)
+		if err != nil {
+			return level.Layout{}, err
+		}
+		defer func() { _ = reader.Close() }()
+		img, _, err := image.Decode(reader)
+		if err != nil {
+			return level.Layout{}, err
+		}
+		return level.LayoutFromImage(img), nil
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return level.Layout{}, err
+	}
+	if extension == ".csv" {
+		return level.LayoutFromCSV(string(data)), nil
+	}
+	return level.LayoutFromText(string(data)), nil
+}
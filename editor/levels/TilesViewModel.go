@@ -1,19 +1,56 @@
 package levels
 
+import "github.com/inkyblackness/hacked/ss1/content/archive/level"
+
 type tilesViewModel struct {
 	selectedTiles     tileCoordinates
 	textureDisplay    TextureDisplay
 	shadowDisplay     ColorDisplay
 	cyberColorDisplay ColorDisplay
 
+	mapTool          MapTool
+	mapSnap          MapSnap
+	paintTemplate    level.TileMapEntry
+	paintTemplateSet bool
+
+	regionMessage string
+	exportMessage string
+
+	layoutMapping    level.LayoutMapping
+	layoutMappingKey string
+	layoutMessage    string
+
+	lightingParameters level.LightingParameters
+	lightingPreview    bool
+	shadowPreview      shadowPreviewCache
+
 	restoreFocus bool
 	windowOpen   bool
 }
 
 func freshTilesViewModel() tilesViewModel {
 	return tilesViewModel{
-		textureDisplay:    TextureDisplayFloor,
-		shadowDisplay:     ColorDisplayNone,
-		cyberColorDisplay: ColorDisplayNone,
+		textureDisplay:     TextureDisplayFloor,
+		shadowDisplay:      ColorDisplayNone,
+		cyberColorDisplay:  ColorDisplayNone,
+		mapTool:            MapToolSelect,
+		layoutMapping:      level.DefaultLayoutMapping(),
+		lightingParameters: level.DefaultLightingParameters(),
 	}
 }
+
+// shadowPreviewCache keeps the shadows computed for the lighting preview,
+// together with the state they were computed for.
+type shadowPreviewCache struct {
+	valid     bool
+	lvl       *level.Level
+	ambient   int
+	intensity int
+	radius    float32
+	shadows   level.ShadowMap
+}
+
+func (cache shadowPreviewCache) isFor(lvl *level.Level, param level.LightingParameters) bool {
+	return cache.valid && (cache.lvl == lvl) &&
+		(cache.ambient == param.Ambient) && (cache.intensity == param.Intensity) && (cache.radius == param.Radius)
+}
@@ -0,0 +1,74 @@
+package project
+
+import (
+	"fmt"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlids"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+	"github.com/inkyblackness/imgui-go"
+)
+
+type importLevelsSelectionState struct {
+	view     *View
+	savegame resource.Provider
+	levels   []int
+	selected map[int]bool
+}
+
+func (state *importLevelsSelectionState) Render() {
+	if imgui.BeginPopupModalV("Import levels from savegame", nil,
+		imgui.WindowFlagsNoResize|imgui.WindowFlagsNoMove|imgui.WindowFlagsNoSavedSettings|imgui.WindowFlagsAlwaysAutoResize) {
+
+		imgui.Text(`Select the levels to import into the mod.
+Their current state in the savegame becomes the starting
+state of the mod. Existing levels in the mod are replaced.
+`)
+		imgui.BeginChildV("Levels", imgui.Vec2{X: 300 * state.view.guiScale, Y: 200 * state.view.guiScale}, true, 0)
+		for _, id := range state.levels {
+			if imgui.SelectableV(fmt.Sprintf("Level %d", id), state.selected[id], 0, imgui.Vec2{}) {
+				state.selected[id] = !state.selected[id]
+			}
+		}
+		imgui.EndChild()
+		imgui.Separator()
+		if imgui.Button("Import") {
+			state.view.requestImportLevels(state.savegame, state.selectedLevels())
+			state.view.fileState = &idlePopupState{}
+			imgui.CloseCurrentPopup()
+		}
+		imgui.SameLine()
+		if imgui.Button("Cancel") {
+			state.view.fileState = &idlePopupState{}
+			imgui.CloseCurrentPopup()
+		}
+		imgui.EndPopup()
+	} else {
+		state.view.fileState = &idlePopupState{}
+	}
+}
+
+func (state *importLevelsSelectionState) HandleFiles(names []string) {
+}
+
+func (state *importLevelsSelectionState) selectedLevels() []int {
+	var result []int
+	for _, id := range state.levels {
+		if state.selected[id] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+func levelsInSavegame(savegame resource.Provider) []int {
+	var result []int
+	for id := 0; id < archive.MaxLevels; id++ {
+		_, err := savegame.Resource(ids.LevelResourcesStart.Plus(lvlids.PerLevel*id + lvlids.Information))
+		if err == nil {
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,18 @@
+package project
+
+import "github.com/inkyblackness/imgui-go"
+
+type importLevelsStartState struct {
+	view *View
+}
+
+func (state importLevelsStartState) Render() {
+	imgui.OpenPopup("Import levels from savegame")
+	state.view.fileState = &importLevelsWaitingState{
+		view: state.view,
+	}
+	state.view.fileState.Render()
+}
+
+func (state importLevelsStartState) HandleFiles(names []string) {
+}
@@ -0,0 +1,65 @@
+package project
+
+import (
+	"time"
+
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/imgui-go"
+)
+
+type importLevelsWaitingState struct {
+	view        *View
+	failureTime time.Time
+}
+
+func (state *importLevelsWaitingState) Render() {
+	if imgui.BeginPopupModalV("Import levels from savegame", nil,
+		imgui.WindowFlagsNoResize|imgui.WindowFlagsNoMove|imgui.WindowFlagsNoSavedSettings|imgui.WindowFlagsAlwaysAutoResize) {
+
+		imgui.Text("Waiting for savegame file.")
+		if !state.failureTime.IsZero() {
+			imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{X: 1, Y: 0, Z: 0, W: 1})
+			imgui.Text("Previous attempt failed, no savegame detected.\nPlease check and try again.")
+			imgui.PopStyleColor()
+			if time.Since(state.failureTime).Seconds() > 5 {
+				state.failureTime = time.Time{}
+			}
+		}
+		imgui.Text(`From your file browser drag'n'drop the savegame file
+you want to take levels from into the editor window.
+Typically, these are the "savgam??.dat" files in the
+"data" directory of the game.
+`)
+		imgui.Separator()
+		if imgui.Button("Cancel") {
+			state.view.fileState = &idlePopupState{}
+			imgui.CloseCurrentPopup()
+		}
+		imgui.EndPopup()
+	} else {
+		state.view.fileState = &idlePopupState{}
+	}
+}
+
+func (state *importLevelsWaitingState) HandleFiles(names []string) {
+	staging := fileStaging{
+		resources: make(map[string]resource.Provider),
+		savegames: make(map[string]resource.Provider),
+	}
+
+	if len(names) == 1 {
+		staging.stage(names[0], true)
+	}
+	if len(staging.savegames) == 1 {
+		for _, savegame := range staging.savegames {
+			state.view.fileState = &importLevelsSelectionState{
+				view:     state.view,
+				savegame: savegame,
+				levels:   levelsInSavegame(savegame),
+				selected: make(map[int]bool),
+			}
+		}
+	} else {
+		state.failureTime = time.Now()
+	}
+}
@@ -2,13 +2,20 @@ package project
 
 import (
 	"fmt"
+	"io/ioutil"
 	"math"
 	"time"
 
 	"github.com/inkyblackness/hacked/editor/cmd"
 	"github.com/inkyblackness/hacked/editor/model"
+	"github.com/inkyblackness/hacked/ss1/content/archive"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlids"
 	"github.com/inkyblackness/hacked/ss1/content/object"
+	"github.com/inkyblackness/hacked/ss1/content/text"
+	"github.com/inkyblackness/hacked/ss1/resource"
 	"github.com/inkyblackness/hacked/ss1/world"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
 	"github.com/inkyblackness/imgui-go"
 )
 
@@ -127,6 +134,13 @@ func (view *View) renderContent() {
 	if imgui.ButtonV("Remove", imgui.Vec2{X: -1, Y: 0}) {
 		view.requestRemoveManifestEntry()
 	}
+	imgui.Separator()
+	if imgui.ButtonV("Levels...", imgui.Vec2{X: -1, Y: 0}) {
+		view.startImportingLevels()
+	}
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Import levels from a savegame as starting state of the mod.")
+	}
 	imgui.EndGroup()
 }
 
@@ -160,6 +174,12 @@ func (view *View) startAddingManifestEntry() {
 	}
 }
 
+func (view *View) startImportingLevels() {
+	view.fileState = &importLevelsStartState{
+		view: view,
+	}
+}
+
 func (view *View) requestMoveManifestEntryUp() {
 	manifest := view.mod.World()
 	entries := manifest.EntryCount()
@@ -235,3 +255,74 @@ func (view *View) requestSaveMod(modPath string) {
 		view.mod.MarkSave()
 	}
 }
+
+func (view *View) requestImportLevels(savegame resource.Provider, levelIDs []int) {
+	if len(levelIDs) == 0 {
+		return
+	}
+	command := cmd.SetResourcesCommand{
+		OldData: make(map[resource.ID][]byte),
+		NewData: make(map[resource.ID][]byte),
+		RestoreState: func(bool) {
+			view.model.restoreFocus = true
+		},
+	}
+
+	if len(view.mod.ModifiedBlocks(resource.LangAny, ids.GameState)) == 0 {
+		if oldName := view.mod.ModifiedBlock(resource.LangAny, ids.ArchiveName, 0); len(oldName) > 0 {
+			command.OldData[ids.ArchiveName] = oldName
+		}
+		command.NewData[ids.ArchiveName] = text.DefaultCodepage().Encode("Starting Game | by InkyBlackness HackEd")
+		command.NewData[ids.GameState] = make([]byte, archive.GameStateSize)
+	}
+
+	localizer := savegameLocalizer{savegame: savegame}
+	for _, id := range levelIDs {
+		lvl := level.NewLevel(ids.LevelResourcesStart, id, localizer)
+		levelData := lvl.EncodeStartingState()
+		levelIDBegin := ids.LevelResourcesStart.Plus(lvlids.PerLevel * id)
+		for offset := lvlids.FirstUsed; offset < lvlids.PerLevel; offset++ {
+			resourceID := levelIDBegin.Plus(offset)
+			oldData := view.mod.ModifiedBlock(resource.LangAny, resourceID, 0)
+			if len(oldData) > 0 {
+				command.OldData[resourceID] = oldData
+			}
+			newData := levelData[offset]
+			if len(newData) == 0 {
+				newData = savegameBlock(savegame, resourceID)
+			}
+			if len(newData) > 0 {
+				command.NewData[resourceID] = newData
+			}
+		}
+	}
+
+	view.commander.Queue(command)
+}
+
+type savegameLocalizer struct {
+	savegame resource.Provider
+}
+
+func (localizer savegameLocalizer) LocalizedResources(lang resource.Language) resource.Selector {
+	return resource.Selector{
+		Lang: lang,
+		From: resource.LocalizedResourcesList{{ID: "savegame", Language: resource.LangAny, Provider: localizer.savegame}},
+	}
+}
+
+func savegameBlock(savegame resource.Provider, id resource.ID) []byte {
+	res, err := savegame.Resource(id)
+	if (err != nil) || (res.BlockCount() != 1) {
+		return nil
+	}
+	reader, err := res.Block(0)
+	if err != nil {
+		return nil
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil
+	}
+	return data
+}
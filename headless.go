@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlrender"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/resource/lgres"
+	"github.com/inkyblackness/hacked/ss1/world"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+)
+
+// loadManifest creates a manifest with one entry per path of the given list.
+// A path can either be a single resource file, or a directory of which all resource files are taken.
+// Later paths take precedence over earlier ones.
+func loadManifest(pathList string) (*world.Manifest, error) {
+	manifest := world.NewManifest(func([]resource.ID, []resource.ID) {})
+	for _, path := range filepath.SplitList(pathList) {
+		entry, err := loadManifestEntry(path)
+		if err != nil {
+			return nil, err
+		}
+		err = manifest.InsertEntry(manifest.EntryCount(), entry)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return manifest, nil
+}
+
+func loadManifestEntry(path string) (*world.ManifestEntry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	filenames := []string{path}
+	if info.IsDir() {
+		dirEntries, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		filenames = nil
+		for _, dirEntry := range dirEntries {
+			if !dirEntry.IsDir() {
+				filenames = append(filenames, filepath.Join(path, dirEntry.Name()))
+			}
+		}
+	}
+	entry := &world.ManifestEntry{ID: path}
+	for _, filename := range filenames {
+		fileData, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		reader, err := lgres.ReaderFrom(bytes.NewReader(fileData))
+		if err != nil {
+			continue
+		}
+		baseName := filepath.Base(filename)
+		entry.Resources = append(entry.Resources, resource.LocalizedResources{
+			ID:       baseName,
+			Language: ids.LocalizeFilename(baseName),
+			Provider: reader,
+		})
+	}
+	return entry, nil
+}
+
+func parsePair(value string, separator string) (int, int, error) {
+	var first, second int
+	parts := strings.Split(value, separator)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid value <%s>, expected two numbers separated by '%s'", value, separator)
+	}
+	_, err := fmt.Sscanf(parts[0]+" "+parts[1], "%d %d", &first, &second)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid value <%s>: %v", value, err)
+	}
+	return first, second, nil
+}
+
+type previewParameters struct {
+	dataPaths string
+	levelID   int
+	at        string
+	heading   float64
+	pitch     float64
+	size      string
+	filename  string
+}
+
+func exportPreview(param previewParameters) error {
+	manifest, err := loadManifest(param.dataPaths)
+	if err != nil {
+		return err
+	}
+	tileX, tileY, err := parsePair(param.at, ",")
+	if err != nil {
+		return err
+	}
+	width, height, err := parsePair(param.size, "x")
+	if err != nil {
+		return err
+	}
+	if (width <= 0) || (height <= 0) {
+		return fmt.Errorf("invalid size <%s>, width and height must be positive", param.size)
+	}
+	palette, err := lvlrender.PaletteFrom(manifest, 0)
+	if err != nil {
+		return fmt.Errorf("no game palette: %v", err)
+	}
+	lvl := level.NewLevel(ids.LevelResourcesStart, param.levelID, manifest)
+	cam := lvlrender.CameraAt(lvl, tileX, tileY, float32(param.heading))
+	cam.Pitch = float32(param.pitch)
+	renderer := lvlrender.NewFirstPersonRenderer(palette, lvlrender.TexturesFrom(manifest))
+	return lvlrender.SavePNG(param.filename, renderer.Render(lvl, cam, width, height))
+}
+
+type mapParameters struct {
+	dataPaths     string
+	levelID       int
+	pixelsPerTile int
+	texture       string
+	color         string
+	filename      string
+}
+
+func exportMap(param mapParameters) error {
+	manifest, err := loadManifest(param.dataPaths)
+	if err != nil {
+		return err
+	}
+	palette, err := lvlrender.PaletteFrom(manifest, 0)
+	if err != nil {
+		return fmt.Errorf("no game palette: %v", err)
+	}
+	lvl := level.NewLevel(ids.LevelResourcesStart, param.levelID, manifest)
+	options := lvlrender.MapImageOptions{
+		PixelsPerTile: param.pixelsPerTile,
+		ObjectIcons:   lvlrender.ObjectIconsFrom(manifest, manifest.ObjectProperties()),
+	}
+	textureFunc, known := lvlrender.TileTextureNamed(param.texture)
+	if !known {
+		return fmt.Errorf("unknown texture display <%s>", param.texture)
+	}
+	options.TextureFunc = textureFunc
+	colorFunc, known := lvlrender.TileColorsNamed(param.color, lvl, palette)
+	if !known {
+		return fmt.Errorf("unknown color display <%s>", param.color)
+	}
+	options.ColorFunc = colorFunc
+	renderer := lvlrender.NewMapRenderer(palette, lvlrender.TexturesFrom(manifest))
+	return lvlrender.SavePNG(param.filename, renderer.Render(lvl, options))
+}
@@ -20,7 +20,49 @@ func main() {
 	scale := flag.Float64("scale", 1.0, "factor for scaling the UI (0.5 .. 10.0). 1080p displays should use default. 4K most likely 2.0.")
 	fontFile := flag.String("fontfile", "", "Path to font file (.TTF) to use instead of the default font. Useful for HiDPI displays.")
 	fontSize := flag.Float64("fontsize", 0.0, "Size of the font to use. If not specified, a default height will be used.")
+	dataPaths := flag.String("data", "", "List of resource directories or files to load for headless exports, separated by the system path list separator.")
+	levelID := flag.Int("level", 1, "Level to use for headless exports.")
+	previewFile := flag.String("preview", "", "Headless: render a first-person preview of the level into given PNG file and exit.")
+	previewAt := flag.String("at", "32,32", "Headless: tile position of the preview camera, as \"x,y\".")
+	previewHeading := flag.Float64("heading", 0.0, "Headless: heading of the preview camera, in degrees. 0 faces north.")
+	previewPitch := flag.Float64("pitch", 0.0, "Headless: pitch of the preview camera, in degrees.")
+	previewSize := flag.String("size", "1280x960", "Headless: size of the preview image, as \"widthxheight\".")
+	mapFile := flag.String("map", "", "Headless: render a map of the level into given PNG file and exit.")
+	mapPixelsPerTile := flag.Int("ppt", 32, "Headless: pixels per tile of the map image.")
+	mapTexture := flag.String("maptexture", "floor", "Headless: textures to show on the map: floor, wall, ceiling, or none.")
+	mapColor := flag.String("mapcolor", "none", "Headless: colors to show on the map: none, floor, or ceiling.")
 	flag.Parse()
+	if len(*mapFile) > 0 {
+		err := exportMap(mapParameters{
+			dataPaths:     *dataPaths,
+			levelID:       *levelID,
+			pixelsPerTile: *mapPixelsPerTile,
+			texture:       *mapTexture,
+			color:         *mapColor,
+			filename:      *mapFile,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to export map: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(*previewFile) > 0 {
+		err := exportPreview(previewParameters{
+			dataPaths: *dataPaths,
+			levelID:   *levelID,
+			at:        *previewAt,
+			heading:   *previewHeading,
+			pitch:     *previewPitch,
+			size:      *previewSize,
+			filename:  *previewFile,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to export preview: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 	var app editor.Application
 	app.FontFile = *fontFile
 	app.FontSize = float32(*fontSize)
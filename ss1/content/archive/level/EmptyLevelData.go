@@ -53,7 +53,7 @@ func EmptyLevelData(param EmptyLevelParameters) [lvlids.PerLevel][]byte {
 	levelData[lvlids.Unknown49] = encode(UnknownL49{})
 	levelData[lvlids.Unknown50] = encode(UnknownL50{})
 
-	levelData[lvlids.LoopConfiguration] = encode(make([]byte, LoopConfigEntrySize*LoopConfigEntryCount))
+	levelData[lvlids.LoopConfiguration] = encode(make([]LoopConfigEntry, LoopConfigEntryCount))
 
 	levelData[lvlids.Unknown52] = encode(UnknownL52{})
 	levelData[lvlids.HeightSemaphores] = encode(HeightSemaphores{})
@@ -1,4 +1,65 @@
 package level
 
-// HeightSemaphores is a placeholder for storing height transitions.
-type HeightSemaphores [0x40]byte
+const (
+	// HeightSemaphoreCount describes how many height semaphores a level has.
+	HeightSemaphoreCount = 16
+)
+
+// HeightSemaphore locks the height of a tile while its floor or ceiling is moving.
+type HeightSemaphore struct {
+	// X is the tile column of the affected tile.
+	X byte
+	// Y is the tile row of the affected tile.
+	Y byte
+	// Control contains the floor/ceiling flag and the usage count.
+	Control byte
+	// Unknown03 is the last byte of the entry. Its meaning is not known.
+	Unknown03 byte
+}
+
+// IsInUse returns true if the semaphore is held.
+func (sem HeightSemaphore) IsInUse() bool {
+	return sem.UseCount() > 0
+}
+
+// IsFloor returns true if the semaphore is for the floor, false for the ceiling.
+func (sem HeightSemaphore) IsFloor() bool {
+	return (sem.Control & 0x01) != 0
+}
+
+// WithFloor returns a semaphore with the floor flag set as given.
+func (sem HeightSemaphore) WithFloor(value bool) HeightSemaphore {
+	sem.Control &^= 0x01
+	if value {
+		sem.Control |= 0x01
+	}
+	return sem
+}
+
+// UseCount returns how often the semaphore is held. Range: [0..127].
+func (sem HeightSemaphore) UseCount() int {
+	return int(sem.Control >> 1)
+}
+
+// WithUseCount returns a semaphore with the given usage count. Values beyond allowed range are ignored.
+func (sem HeightSemaphore) WithUseCount(value int) HeightSemaphore {
+	if (value < 0) || (value > 127) {
+		return sem
+	}
+	sem.Control = (sem.Control & 0x01) | byte(value<<1)
+	return sem
+}
+
+// HeightSemaphores is the table of height transitions currently in progress.
+type HeightSemaphores [HeightSemaphoreCount]HeightSemaphore
+
+// At returns the indices of the semaphores in use that lock the given tile.
+func (sems *HeightSemaphores) At(x, y byte) []int {
+	var result []int
+	for index, sem := range sems {
+		if sem.IsInUse() && (sem.X == x) && (sem.Y == y) {
+			result = append(result, index)
+		}
+	}
+	return result
+}
@@ -0,0 +1,42 @@
+package level_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeightSemaphoresSerializedSize(t *testing.T) {
+	var sems level.HeightSemaphores
+	size := binary.Size(&sems)
+	assert.Equal(t, 0x40, size, "Size mismatch")
+}
+
+func TestHeightSemaphoreControl(t *testing.T) {
+	sem := level.HeightSemaphore{Control: 0x07}
+	assert.True(t, sem.IsFloor(), "floor expected")
+	assert.Equal(t, 3, sem.UseCount(), "use count mismatch")
+	assert.True(t, sem.IsInUse(), "in use expected")
+
+	sem = sem.WithFloor(false)
+	assert.Equal(t, byte(0x06), sem.Control, "floor flag not cleared")
+	sem = sem.WithUseCount(0)
+	assert.Equal(t, byte(0x00), sem.Control, "use count not cleared")
+	assert.False(t, sem.IsInUse(), "not in use expected")
+	sem = sem.WithUseCount(128)
+	assert.Equal(t, byte(0x00), sem.Control, "invalid count should be ignored")
+}
+
+func TestHeightSemaphoresAt(t *testing.T) {
+	var sems level.HeightSemaphores
+	sems[1] = level.HeightSemaphore{X: 2, Y: 3}.WithUseCount(1)
+	sems[4] = level.HeightSemaphore{X: 2, Y: 3}.WithUseCount(2).WithFloor(true)
+	sems[5] = level.HeightSemaphore{X: 2, Y: 3}
+	sems[6] = level.HeightSemaphore{X: 3, Y: 2}.WithUseCount(1)
+
+	assert.Equal(t, []int{1, 4}, sems.At(2, 3), "only semaphores in use expected")
+	assert.Empty(t, sems.At(0, 0), "no semaphores expected")
+}
@@ -0,0 +1,269 @@
+package level
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LayoutTile describes how a tile is set up when a layout is applied.
+type LayoutTile struct {
+	Type          TileType
+	FloorHeight   TileHeightUnit
+	CeilingHeight TileHeightUnit
+	// TextureIndex is the index into the texture atlas, used for floor, wall, and ceiling.
+	// A negative value keeps the current textures.
+	TextureIndex int
+}
+
+// Validate returns an error if the tile can not be applied.
+// The floor must be below the ceiling, and the texture index must refer to a floor and ceiling texture.
+func (tile LayoutTile) Validate() error {
+	if (tile.FloorHeight < TileHeightUnitMin) || (tile.CeilingHeight > TileHeightUnitMax) {
+		return errors.New("height out of range")
+	}
+	if tile.FloorHeight >= tile.CeilingHeight {
+		return errors.New("floor must be below ceiling")
+	}
+	if (tile.TextureIndex < -1) || (tile.TextureIndex >= FloorCeilingTextureLimit) {
+		return fmt.Errorf("texture index out of range [-1..%d]", FloorCeilingTextureLimit-1)
+	}
+	return nil
+}
+
+// LayoutMapping maps the keys of a layout to the tiles they describe.
+// Keys are single characters for text grids, cell contents for CSV grids,
+// and colors in the form "#RRGGBB" for images.
+type LayoutMapping map[string]LayoutTile
+
+// DefaultLayoutMapping returns a mapping for simple text grids and black-and-white images.
+func DefaultLayoutMapping() LayoutMapping {
+	return LayoutMapping{
+		"#":       {Type: TileTypeSolid, CeilingHeight: TileHeightUnitMax, TextureIndex: -1},
+		".":       {Type: TileTypeOpen, FloorHeight: 0, CeilingHeight: 16, TextureIndex: 0},
+		"+":       {Type: TileTypeOpen, FloorHeight: 4, CeilingHeight: 16, TextureIndex: 0},
+		"#000000": {Type: TileTypeSolid, CeilingHeight: TileHeightUnitMax, TextureIndex: -1},
+		"#FFFFFF": {Type: TileTypeOpen, FloorHeight: 0, CeilingHeight: 16, TextureIndex: 0},
+		"#808080": {Type: TileTypeOpen, FloorHeight: 4, CeilingHeight: 16, TextureIndex: 0},
+	}
+}
+
+// LayoutMappingFromText parses a mapping that was serialized with Text().
+// Each non-empty line has the form "<key> <type> <floor height> <ceiling height> <texture index>".
+func LayoutMappingFromText(text string) (LayoutMapping, error) {
+	mapping := make(LayoutMapping)
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("line %d: expected five fields, got %d", lineNumber, len(fields))
+		}
+		tileType, known := tileTypeNamed(fields[1])
+		if !known {
+			return nil, fmt.Errorf("line %d: unknown tile type <%s>", lineNumber, fields[1])
+		}
+		var values [3]int
+		for i := range values {
+			value, err := strconv.Atoi(fields[2+i])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+			}
+			values[i] = value
+		}
+		if (values[0] < int(TileHeightUnitMin)) || (values[1] > int(TileHeightUnitMax)) {
+			return nil, fmt.Errorf("line %d: height out of range", lineNumber)
+		}
+		tile := LayoutTile{
+			Type:          tileType,
+			FloorHeight:   TileHeightUnit(values[0]),
+			CeilingHeight: TileHeightUnit(values[1]),
+			TextureIndex:  values[2],
+		}
+		err := tile.Validate()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		mapping[normalizedLayoutKey(fields[0])] = tile
+	}
+	return mapping, scanner.Err()
+}
+
+// normalizedLayoutKey returns color keys in upper case, leaving all other keys as they are.
+func normalizedLayoutKey(key string) string {
+	if (len(key) == 7) && strings.HasPrefix(key, "#") {
+		return strings.ToUpper(key)
+	}
+	return key
+}
+
+func tileTypeNamed(name string) (TileType, bool) {
+	for _, tileType := range TileTypes() {
+		if strings.EqualFold(tileType.String(), name) {
+			return tileType, true
+		}
+	}
+	return TileTypeSolid, false
+}
+
+// Keys returns the keys of the mapping in sorted order.
+func (mapping LayoutMapping) Keys() []string {
+	keys := make([]string, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Text serializes the mapping into a textual form, one entry per line.
+func (mapping LayoutMapping) Text() string {
+	var builder strings.Builder
+	for _, key := range mapping.Keys() {
+		tile := mapping[key]
+		builder.WriteString(fmt.Sprintf("%s %v %d %d %d\n", key, tile.Type, tile.FloorHeight, tile.CeilingHeight, tile.TextureIndex))
+	}
+	return builder.String()
+}
+
+// Layout is a rectangular grid of keys, to be resolved with a LayoutMapping.
+type Layout struct {
+	Width  int
+	Height int
+	// Keys are stored row by row, starting with the lowest Y coordinate.
+	// An empty key leaves the corresponding tile untouched.
+	Keys []string
+}
+
+// Key returns the key at given position. Returns an empty string if out of range.
+func (layout Layout) Key(x, y int) string {
+	if (x < 0) || (x >= layout.Width) || (y < 0) || (y >= layout.Height) {
+		return ""
+	}
+	return layout.Keys[y*layout.Width+x]
+}
+
+// LayoutFromText parses a text grid, with each character being a key.
+// The first line describes the northern-most row.
+func LayoutFromText(text string) Layout {
+	return layoutFromLines(text, func(line string) []string {
+		var cells []string
+		for _, char := range line {
+			cells = append(cells, string(char))
+		}
+		return cells
+	})
+}
+
+// LayoutFromCSV parses a grid of comma-separated values, with each cell being a key.
+// The first line describes the northern-most row.
+func LayoutFromCSV(text string) Layout {
+	return layoutFromLines(text, func(line string) []string {
+		var cells []string
+		for _, cell := range strings.Split(line, ",") {
+			cells = append(cells, normalizedLayoutKey(strings.TrimSpace(cell)))
+		}
+		return cells
+	})
+}
+
+func layoutFromLines(text string, cellsOf func(line string) []string) Layout {
+	var rows [][]string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r", ""), "\n") {
+		rows = append(rows, cellsOf(line))
+	}
+	for (len(rows) > 0) && (strings.TrimSpace(strings.Join(rows[len(rows)-1], "")) == "") {
+		rows = rows[:len(rows)-1]
+	}
+	var layout Layout
+	for _, row := range rows {
+		if len(row) > layout.Width {
+			layout.Width = len(row)
+		}
+	}
+	layout.Height = len(rows)
+	layout.Keys = make([]string, layout.Width*layout.Height)
+	for index, row := range rows {
+		y := layout.Height - 1 - index
+		copy(layout.Keys[y*layout.Width:], row)
+	}
+	return layout
+}
+
+// LayoutFromImage creates a layout from the colors of an image, one pixel per tile.
+// The top of the image describes the northern-most row. Transparent pixels leave their tiles untouched.
+func LayoutFromImage(img image.Image) Layout {
+	bounds := img.Bounds()
+	layout := Layout{
+		Width:  bounds.Dx(),
+		Height: bounds.Dy(),
+		Keys:   make([]string, bounds.Dx()*bounds.Dy()),
+	}
+	for y := 0; y < layout.Height; y++ {
+		for x := 0; x < layout.Width; x++ {
+			r, g, b, a := img.At(bounds.Min.X+x, bounds.Max.Y-1-y).RGBA()
+			if a == 0 {
+				continue
+			}
+			layout.Keys[y*layout.Width+x] = fmt.Sprintf("#%02X%02X%02X", r>>8, g>>8, b>>8)
+		}
+	}
+	return layout
+}
+
+// ApplyLayout sets up the tiles of the level according to the given layout.
+// The layout must have the size of the map. Tiles with keys that are not part of the mapping are ignored.
+// Returns the number of modified tiles.
+// An error is returned if the layout does not fit or a used mapping entry is invalid;
+// the level is not modified in this case.
+func (lvl *Level) ApplyLayout(layout Layout, mapping LayoutMapping) (int, error) {
+	columns, rows, _ := lvl.Size()
+	if (layout.Width != columns) || (layout.Height != rows) {
+		return 0, fmt.Errorf("layout is %dx%d, map requires %dx%d", layout.Width, layout.Height, columns, rows)
+	}
+	atlasSize := len(lvl.TextureAtlas())
+	for _, key := range layout.Keys {
+		layoutTile, mapped := mapping[key]
+		if !mapped {
+			continue
+		}
+		err := layoutTile.Validate()
+		if err != nil {
+			return 0, fmt.Errorf("mapping <%s>: %v", key, err)
+		}
+		if !lvl.IsCyberspace() && (layoutTile.TextureIndex >= atlasSize) {
+			return 0, fmt.Errorf("mapping <%s>: texture index %d beyond texture atlas size %d", key, layoutTile.TextureIndex, atlasSize)
+		}
+	}
+	modified := 0
+	for y := 0; y < layout.Height; y++ {
+		for x := 0; x < layout.Width; x++ {
+			layoutTile, mapped := mapping[layout.Key(x, y)]
+			tile := lvl.Tile(x, y)
+			if !mapped || (tile == nil) {
+				continue
+			}
+			tile.Type = layoutTile.Type
+			tile.SlopeHeight = 0
+			tile.Flags = tile.Flags.WithSlopeControl(TileSlopeControlCeilingInverted)
+			tile.Floor = tile.Floor.WithAbsoluteHeight(layoutTile.FloorHeight)
+			tile.Ceiling = tile.Ceiling.WithAbsoluteHeight(layoutTile.CeilingHeight)
+			if (layoutTile.TextureIndex >= 0) && !lvl.IsCyberspace() {
+				tile.TextureInfo = tile.TextureInfo.
+					WithFloorTextureIndex(layoutTile.TextureIndex).
+					WithWallTextureIndex(layoutTile.TextureIndex).
+					WithCeilingTextureIndex(layoutTile.TextureIndex)
+			}
+			modified++
+		}
+	}
+	return modified, nil
+}
@@ -0,0 +1,119 @@
+package level_test
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvltest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayoutMappingTextRoundTrip(t *testing.T) {
+	mapping := level.DefaultLayoutMapping()
+
+	decoded, err := level.LayoutMappingFromText(mapping.Text())
+	require.Nil(t, err, "no error expected")
+	assert.Equal(t, mapping, decoded)
+}
+
+func TestLayoutMappingFromTextRejectsInvalidLines(t *testing.T) {
+	for _, text := range []string{"x Open 0 16", "x Unknown 0 16 0", "x Open 0 0 0", "x Open a 16 0",
+		"x Open 8 4 0", "x Open 0 16 32", "x Open 0 16 -2"} {
+		_, err := level.LayoutMappingFromText(text)
+		assert.NotNil(t, err, "error expected for <%s>", text)
+	}
+}
+
+func TestLayoutFromTextStartsWithNorth(t *testing.T) {
+	layout := level.LayoutFromText("#.\n.+\n")
+
+	assert.Equal(t, 2, layout.Width)
+	assert.Equal(t, 2, layout.Height)
+	assert.Equal(t, "#", layout.Key(0, 1))
+	assert.Equal(t, ".", layout.Key(1, 1))
+	assert.Equal(t, ".", layout.Key(0, 0))
+	assert.Equal(t, "+", layout.Key(1, 0))
+}
+
+func TestLayoutFromCSV(t *testing.T) {
+	layout := level.LayoutFromCSV("wall, floor\r\nfloor\r\n")
+
+	assert.Equal(t, 2, layout.Width)
+	assert.Equal(t, 2, layout.Height)
+	assert.Equal(t, "wall", layout.Key(0, 1))
+	assert.Equal(t, "floor", layout.Key(1, 1))
+	assert.Equal(t, "floor", layout.Key(0, 0))
+	assert.Equal(t, "", layout.Key(1, 0))
+}
+
+func TestLayoutFromTextKeepsCommas(t *testing.T) {
+	layout := level.LayoutFromText(".,#\n")
+
+	assert.Equal(t, 3, layout.Width)
+	assert.Equal(t, ",", layout.Key(1, 0))
+}
+
+func TestLayoutFromImageUsesColorKeys(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF})
+
+	layout := level.LayoutFromImage(img)
+
+	assert.Equal(t, "#FFFFFF", layout.Key(0, 0))
+	assert.Equal(t, "", layout.Key(1, 0), "transparent pixels should be ignored")
+}
+
+func mapLayout(rows ...string) level.Layout {
+	lines := make([]string, 64)
+	for index := range lines {
+		line := strings.Repeat("?", 64)
+		if index < len(rows) {
+			line = rows[index] + line[len(rows[index]):]
+		}
+		lines[len(lines)-1-index] = line
+	}
+	return level.LayoutFromText(strings.Join(lines, "\n"))
+}
+
+func TestLevelApplyLayout(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	lvl.Tile(1, 0).Flags = lvl.Tile(1, 0).Flags.WithSlopeControl(level.TileSlopeControlFloorFlat)
+	layout := mapLayout("#+#", "..?")
+
+	modified, err := lvl.ApplyLayout(layout, level.DefaultLayoutMapping())
+	require.Nil(t, err, "no error expected")
+
+	assert.Equal(t, 5, modified)
+	assert.Equal(t, level.TileTypeOpen, lvl.Tile(0, 1).Type)
+	assert.Equal(t, level.TileTypeSolid, lvl.Tile(2, 1).Type, "unmapped key should keep tile")
+	tile := lvl.Tile(1, 0)
+	assert.Equal(t, level.TileTypeOpen, tile.Type)
+	assert.Equal(t, level.TileHeightUnit(4), tile.Floor.AbsoluteHeight())
+	assert.Equal(t, level.TileHeightUnit(16), tile.Ceiling.AbsoluteHeight())
+	assert.Equal(t, 0, tile.TextureInfo.FloorTextureIndex())
+	assert.Equal(t, level.TileSlopeControlCeilingInverted, tile.Flags.SlopeControl(), "slope control should be reset")
+}
+
+func TestLevelApplyLayoutRejectsLayoutNotCoveringMap(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+
+	_, err := lvl.ApplyLayout(level.LayoutFromText("..\n..\n"), level.DefaultLayoutMapping())
+	assert.NotNil(t, err, "error expected")
+	assert.Equal(t, level.TileTypeSolid, lvl.Tile(0, 0).Type, "tile should not be modified")
+}
+
+func TestLevelApplyLayoutRejectsInvalidMapping(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	mapping := level.LayoutMapping{
+		".": {Type: level.TileTypeOpen, FloorHeight: 16, CeilingHeight: 8, TextureIndex: 0},
+	}
+
+	_, err := lvl.ApplyLayout(mapLayout("."), mapping)
+	assert.NotNil(t, err, "error expected")
+	assert.Equal(t, level.TileTypeSolid, lvl.Tile(0, 0).Type, "tile should not be modified")
+}
@@ -34,6 +34,10 @@ type Level struct {
 	surveillanceSources    [SurveillanceObjectCount]ObjectID
 	surveillanceSurrogates [SurveillanceObjectCount]ObjectID
 	parameters             Parameters
+	mapNotes               MapNotes
+	loopConfig             []LoopConfigEntry
+	schedules              ScheduleEvents
+	heightSemaphores       HeightSemaphores
 }
 
 // NewLevel returns a new instance.
@@ -59,6 +63,10 @@ func NewLevel(resourceBase resource.ID, id int, localizer resource.Localizer) *L
 	lvl.reloadSurveillanceSources()
 	lvl.reloadSurveillanceSurrogates()
 	lvl.reloadParameters()
+	lvl.reloadMapNotes()
+	lvl.reloadLoopConfig()
+	lvl.reloadSchedules()
+	lvl.reloadHeightSemaphores()
 
 	return lvl
 }
@@ -126,6 +134,93 @@ func (lvl *Level) Parameters() *Parameters {
 	return &lvl.parameters
 }
 
+// HeightSemaphores returns the table of height semaphores.
+func (lvl *Level) HeightSemaphores() *HeightSemaphores {
+	return &lvl.heightSemaphores
+}
+
+// MapNotes returns the notes of the automap.
+func (lvl *Level) MapNotes() MapNotes {
+	return lvl.mapNotes
+}
+
+// SetMapNotes sets the notes of the automap.
+func (lvl *Level) SetMapNotes(notes MapNotes) {
+	lvl.mapNotes = notes
+}
+
+// LoopConfig returns the list of loop configuration entries.
+func (lvl *Level) LoopConfig() []LoopConfigEntry {
+	return lvl.loopConfig
+}
+
+// InvalidLoopConfigEntries returns the indices of all used loop configuration entries
+// that refer to objects which are not in use.
+func (lvl *Level) InvalidLoopConfigEntries() []int {
+	var result []int
+	for index, entry := range lvl.loopConfig {
+		if !entry.IsInUse() {
+			continue
+		}
+		obj := lvl.Object(entry.ObjectID)
+		if (obj == nil) || (obj.InUse == 0) {
+			result = append(result, index)
+		}
+	}
+	return result
+}
+
+// Schedules returns the currently active events of the scheduler.
+func (lvl *Level) Schedules() ScheduleEvents {
+	count := int(lvl.baseInfo.Scheduler.ScheduleCount)
+	if (count < 0) || (count > len(lvl.schedules)) {
+		count = len(lvl.schedules)
+	}
+	return append(ScheduleEvents{}, lvl.schedules[:count]...)
+}
+
+// ScheduleCapacity returns the maximum amount of events the level can store.
+func (lvl *Level) ScheduleCapacity() int {
+	return len(lvl.schedules)
+}
+
+// SetScheduleCapacity resizes the schedule table so that it can hold the given amount of events.
+// Returns an error if more events are active than the new capacity allows. In this case the level is not modified.
+func (lvl *Level) SetScheduleCapacity(capacity int) error {
+	if lvl.baseInfo.Scheduler.ElementSize != ScheduleEventSize {
+		return errors.New("unsupported schedule table")
+	}
+	if capacity < 1 {
+		return errors.New("invalid schedule capacity")
+	}
+	events := lvl.Schedules()
+	if len(events) > capacity {
+		return errors.New("too many schedule events")
+	}
+	schedules := make(ScheduleEvents, capacity)
+	copy(schedules, events)
+	lvl.schedules = schedules
+	lvl.baseInfo.Scheduler.Size = int32(capacity)
+	return nil
+}
+
+// SetSchedules sets the active events of the scheduler, ordered by time.
+// The scheduler information is updated accordingly.
+// An error is returned if the events exceed the capacity.
+func (lvl *Level) SetSchedules(events ScheduleEvents) error {
+	if len(events) > len(lvl.schedules) {
+		return errors.New("too many schedule events")
+	}
+	sorted := append(ScheduleEvents{}, events...)
+	sorted.SortByTime()
+	copy(lvl.schedules, sorted)
+	for index := len(sorted); index < len(lvl.schedules); index++ {
+		lvl.schedules[index] = ScheduleEvent{}
+	}
+	lvl.baseInfo.Scheduler.ScheduleCount = int32(len(sorted))
+	return nil
+}
+
 // TextureAtlas returns the atlas for textures.
 func (lvl *Level) TextureAtlas() TextureAtlas {
 	return lvl.textureAtlas
@@ -179,6 +274,153 @@ func (lvl *Level) ObjectClassStats(class object.Class) (active, limit int) {
 	return active, size - 1
 }
 
+// SetObjectLimit resizes the master table so that the given value is the highest possible object ID.
+// Values beyond DefaultObjectLimit require an engine that supports larger tables.
+// Returns an error if an object in use has an ID beyond the new limit. In this case the level is not modified.
+func (lvl *Level) SetObjectLimit(limit ObjectID) error {
+	if limit < 1 {
+		return errors.New("invalid object limit")
+	}
+	table, err := lvl.objectMasterTable.Resized(int(limit) + 1)
+	if err != nil {
+		return err
+	}
+	lvl.objectMasterTable = table
+	return nil
+}
+
+// SetObjectClassLimit resizes the table of given class so that it can hold the given amount of objects.
+// Values beyond the default entry count of the class require an engine that supports larger tables.
+// Entries of objects beyond the new limit are moved and the objects are updated to refer to the new entries.
+// Returns an error if more objects are in use than the new limit allows. In this case the level is not modified.
+func (lvl *Level) SetObjectClassLimit(class object.Class, limit int) error {
+	if int(class) >= len(lvl.objectClassTables) {
+		return errors.New("invalid class specified")
+	}
+	if limit < 1 {
+		return errors.New("invalid class limit")
+	}
+	table, moved, err := lvl.objectClassTables[class].Resized(limit+1, ObjectClassInfoFor(class).DataSize)
+	if err != nil {
+		return err
+	}
+	for _, newIndex := range moved {
+		obj := lvl.Object(table[newIndex].ObjectID)
+		if obj != nil {
+			obj.ClassTableIndex = int16(newIndex)
+		}
+	}
+	lvl.objectClassTables[class] = table
+	return nil
+}
+
+// CompactObjects renumbers all objects in use to the lowest identifiers, keeping their relative order.
+// The class tables are compacted the same way, and the cross-reference table is rebuilt.
+// References held by the level itself, such as the surveillance objects and the loop configuration,
+// are updated. References to objects that are not in use are cleared.
+// References within the class data of objects are not modified, as the level does not know their layout.
+// Class table entries that do not belong to an object in use are released, as their object can not be renumbered.
+//
+// Returns the mapping from the previous to the new identifier for all objects in use,
+// and the number of released class table entries.
+func (lvl *Level) CompactObjects() (map[ObjectID]ObjectID, int) {
+	tilesPerObject := make(map[ObjectID][]ObjectCrossReferenceEntry)
+	for _, id := range lvl.objectMasterTable.UsedIDs() {
+		tilesPerObject[id] = lvl.crossReferencesOf(id)
+	}
+	released := lvl.releaseOrphanedClassEntries()
+
+	masterTable, mapping := lvl.objectMasterTable.Compacted()
+	for class, classTable := range lvl.objectClassTables {
+		compacted, _ := classTable.Compacted(ObjectClassInfoFor(object.Class(class)).DataSize)
+		for _, index := range compacted.UsedIndices() {
+			entry := &compacted[index]
+			newID, inUse := mapping[entry.ObjectID]
+			if inUse && (masterTable[newID].Class == object.Class(class)) {
+				entry.ObjectID = newID
+				masterTable[newID].ClassTableIndex = int16(index)
+			}
+		}
+		lvl.objectClassTables[class] = compacted
+	}
+	lvl.objectMasterTable = masterTable
+
+	lvl.objectCrossRefTable.Reset()
+	for _, row := range lvl.tileMap {
+		for x := range row {
+			row[x].FirstObjectIndex = 0
+		}
+	}
+	oldIDs := make([]ObjectID, len(mapping)+1)
+	for oldID, newID := range mapping {
+		oldIDs[newID] = oldID
+	}
+	for newID := ObjectID(1); int(newID) < len(oldIDs); newID++ {
+		obj := &lvl.objectMasterTable[newID]
+		obj.CrossReferenceTableIndex = 0
+		refs := tilesPerObject[oldIDs[newID]]
+		// Adding a reference makes it the head of the chain, so add them in reverse to keep the order.
+		for index := len(refs) - 1; index >= 0; index-- {
+			lvl.addCrossReferenceTo(newID, obj, refs[index].TileX, refs[index].TileY)
+		}
+	}
+
+	remap := func(id ObjectID) ObjectID {
+		return mapping[id]
+	}
+	for index := 0; index < SurveillanceObjectCount; index++ {
+		lvl.surveillanceSources[index] = remap(lvl.surveillanceSources[index])
+		lvl.surveillanceSurrogates[index] = remap(lvl.surveillanceSurrogates[index])
+	}
+	for index := range lvl.loopConfig {
+		lvl.loopConfig[index].ObjectID = remap(lvl.loopConfig[index].ObjectID)
+	}
+
+	return mapping, released
+}
+
+// CompactionMapping returns the mapping that CompactObjects() would apply, without modifying the level.
+func (lvl *Level) CompactionMapping() map[ObjectID]ObjectID {
+	_, mapping := lvl.objectMasterTable.Compacted()
+	return mapping
+}
+
+// releaseOrphanedClassEntries frees all used class table entries that are not referenced by their object.
+// Returns the number of released entries.
+func (lvl *Level) releaseOrphanedClassEntries() int {
+	released := 0
+	for class, classTable := range lvl.objectClassTables {
+		for _, index := range classTable.UsedIndices() {
+			obj := lvl.Object(classTable[index].ObjectID)
+			if (obj == nil) || (obj.InUse == 0) || (obj.Class != object.Class(class)) || (int(obj.ClassTableIndex) != index) {
+				classTable.Release(index)
+				released++
+			}
+		}
+	}
+	return released
+}
+
+// crossReferencesOf returns the cross-reference entries of the identified object, in order of their chain.
+func (lvl *Level) crossReferencesOf(id ObjectID) []ObjectCrossReferenceEntry {
+	var refs []ObjectCrossReferenceEntry
+	obj := lvl.Object(id)
+	if obj == nil {
+		return refs
+	}
+	start := int(obj.CrossReferenceTableIndex)
+	index := start
+	for (index > 0) && (index < len(lvl.objectCrossRefTable)) && (len(refs) < len(lvl.objectCrossRefTable)) {
+		entry := lvl.objectCrossRefTable[index]
+		refs = append(refs, entry)
+		index = int(entry.NextTileForObj)
+		if index == start {
+			break
+		}
+	}
+	return refs
+}
+
 // ForEachObject iterates over all active objects and calls the given handler.
 func (lvl *Level) ForEachObject(handler func(ObjectID, ObjectMasterEntry)) {
 	tableSize := len(lvl.objectMasterTable)
@@ -230,6 +472,22 @@ func (lvl *Level) UpdateObjectLocation(id ObjectID) {
 	lvl.addCrossReferenceTo(id, obj, int16(obj.X.Tile()), int16(obj.Y.Tile()))
 }
 
+// ClearObjectLocation removes all references between the identified object and the tiles.
+// The object remains in use, yet has no place in the world. This is the case for objects within containers.
+func (lvl *Level) ClearObjectLocation(id ObjectID) {
+	obj := lvl.Object(id)
+	if (obj == nil) || (obj.InUse == 0) {
+		return
+	}
+	lvl.removeCrossReferences(int(obj.CrossReferenceTableIndex), func(entry ObjectCrossReferenceEntry) int { return int(entry.NextTileForObj) })
+}
+
+// IsObjectPlaced returns true if the identified object has a place in the world.
+func (lvl *Level) IsObjectPlaced(id ObjectID) bool {
+	obj := lvl.Object(id)
+	return (obj != nil) && (obj.InUse != 0) && (obj.CrossReferenceTableIndex != 0)
+}
+
 // DelObject removes the identified object from the level.
 func (lvl *Level) DelObject(id ObjectID) {
 	obj := lvl.Object(id)
@@ -354,6 +612,7 @@ func (lvl *Level) EncodeState() [lvlids.PerLevel][]byte {
 
 	levelData[lvlids.TextureAtlas] = encode(lvl.textureAtlas)
 	levelData[lvlids.TileMap] = encode(lvl.tileMap)
+	levelData[lvlids.Schedules] = encode(lvl.schedules)
 	levelData[lvlids.ObjectMasterTable] = encode(lvl.objectMasterTable)
 	levelData[lvlids.ObjectCrossRefTable] = encode(lvl.objectCrossRefTable)
 	for class := 0; class < len(lvl.objectClassTables); class++ {
@@ -364,6 +623,33 @@ func (lvl *Level) EncodeState() [lvlids.PerLevel][]byte {
 	levelData[lvlids.SurveillanceSources] = encode(&lvl.surveillanceSources)
 	levelData[lvlids.SurveillanceSurrogates] = encode(&lvl.surveillanceSurrogates)
 	levelData[lvlids.Parameters] = encode(&lvl.parameters)
+	mapNotesData, mapNotesPointer := lvl.mapNotes.Encode()
+	levelData[lvlids.MapNotes] = mapNotesData
+	levelData[lvlids.MapNotesPointer] = encode(mapNotesPointer)
+	levelData[lvlids.LoopConfiguration] = encode(lvl.loopConfig)
+	levelData[lvlids.HeightSemaphores] = encode(&lvl.heightSemaphores)
+
+	return levelData
+}
+
+// EncodeStartingState returns the data of the level as it should be stored as starting state of a new game.
+// In contrast to EncodeState(), runtime-only information is reset: Tiles are no longer marked as visited,
+// and the savefile version is set to the default value.
+func (lvl *Level) EncodeStartingState() [lvlids.PerLevel][]byte {
+	levelData := lvl.EncodeState()
+
+	if !lvl.IsCyberspace() {
+		tileMap := NewTileMap(0, len(lvl.tileMap))
+		for y, row := range lvl.tileMap {
+			tileMap[y] = make([]TileMapEntry, len(row))
+			for x, tile := range row {
+				tile.Flags = tile.Flags.ForRealWorld().WithTileVisited(false).AsTileFlag()
+				tileMap[y][x] = tile
+			}
+		}
+		levelData[lvlids.TileMap] = encode(tileMap)
+	}
+	levelData[lvlids.SavefileVersion] = encode(savefileVersionValue)
 
 	return levelData
 }
@@ -372,6 +658,8 @@ func (lvl *Level) onLevelResourceDataChanged(id int) {
 	switch id {
 	case lvlids.Information:
 		lvl.reloadBaseInfo()
+	case lvlids.Schedules:
+		lvl.reloadSchedules()
 	case lvlids.TextureAtlas:
 		lvl.reloadTextureAtlas()
 	case lvlids.TileMap:
@@ -388,6 +676,12 @@ func (lvl *Level) onLevelResourceDataChanged(id int) {
 		lvl.reloadSurveillanceSurrogates()
 	case lvlids.Parameters:
 		lvl.reloadParameters()
+	case lvlids.MapNotes, lvlids.MapNotesPointer:
+		lvl.reloadMapNotes()
+	case lvlids.LoopConfiguration:
+		lvl.reloadLoopConfig()
+	case lvlids.HeightSemaphores:
+		lvl.reloadHeightSemaphores()
 	}
 	if (id >= lvlids.ObjectClassTablesStart) && (id < (lvlids.ObjectClassTablesStart + len(lvl.objectClassTables))) {
 		lvl.reloadObjectClassTable(object.Class(id - lvlids.ObjectClassTablesStart))
@@ -550,6 +844,75 @@ func (lvl *Level) reloadParameters() {
 	}
 }
 
+func (lvl *Level) reloadMapNotes() {
+	lvl.mapNotes = MapNotes{}
+	var pointer MapNotesPointer
+	reader, err := lvl.reader(lvlids.MapNotesPointer)
+	if err == nil {
+		err = binary.Read(reader, binary.LittleEndian, &pointer)
+	}
+	if err != nil {
+		return
+	}
+	reader, err = lvl.reader(lvlids.MapNotes)
+	if err != nil {
+		return
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return
+	}
+	lvl.mapNotes = DecodeMapNotes(data, pointer)
+}
+
+func (lvl *Level) reloadLoopConfig() {
+	reader, err := lvl.reader(lvlids.LoopConfiguration)
+	if err != nil {
+		lvl.loopConfig = nil
+		return
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		lvl.loopConfig = nil
+		return
+	}
+	lvl.loopConfig = make([]LoopConfigEntry, len(data)/LoopConfigEntrySize)
+	err = binary.Read(bytes.NewReader(data), binary.LittleEndian, lvl.loopConfig)
+	if err != nil {
+		lvl.loopConfig = nil
+	}
+}
+
+func (lvl *Level) reloadSchedules() {
+	lvl.schedules = nil
+	if lvl.baseInfo.Scheduler.ElementSize != ScheduleEventSize {
+		return
+	}
+	reader, err := lvl.reader(lvlids.Schedules)
+	if err != nil {
+		return
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return
+	}
+	schedules := make(ScheduleEvents, len(data)/ScheduleEventSize)
+	err = binary.Read(bytes.NewReader(data), binary.LittleEndian, schedules)
+	if err == nil {
+		lvl.schedules = schedules
+	}
+}
+
+func (lvl *Level) reloadHeightSemaphores() {
+	reader, err := lvl.reader(lvlids.HeightSemaphores)
+	if err == nil {
+		err = binary.Read(reader, binary.LittleEndian, &lvl.heightSemaphores)
+	}
+	if err != nil {
+		lvl.heightSemaphores = HeightSemaphores{}
+	}
+}
+
 func (lvl *Level) clearTileMap() {
 	for _, row := range lvl.tileMap {
 		for i := 0; i < len(row); i++ {
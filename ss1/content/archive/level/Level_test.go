@@ -0,0 +1,291 @@
+package level_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlids"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvltest"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+	"github.com/inkyblackness/hacked/ss1/resource"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type emptyLocalizer struct{}
+
+func (localizer emptyLocalizer) LocalizedResources(lang resource.Language) resource.Selector {
+	return resource.Selector{Lang: lang, From: resource.LocalizedResourcesList{}}
+}
+
+func TestLevelMapNotesRoundTrip(t *testing.T) {
+	data := level.EmptyLevelData(level.EmptyLevelParameters{MapModifier: func(level.TileMap) {}})
+	notesData := make([]byte, level.MapNotesSize)
+	copy(notesData, []byte{10, 20, 'D', 'o', 'o', 'r', 0x00, 11, 21, 'K', 'e', 'y', 0x00})
+	data[lvlids.MapNotes] = notesData
+	data[lvlids.MapNotesPointer] = []byte{13, 0x00, 0x00, 0x00}
+	lvl := lvltest.NewLevel(data)
+	require.Equal(t, level.MapNotes{{X: 10, Y: 20, Text: "Door"}, {X: 11, Y: 21, Text: "Key"}}, lvl.MapNotes())
+
+	encoded := lvl.EncodeState()
+	assert.Equal(t, notesData, encoded[lvlids.MapNotes], "notes data mismatch")
+	assert.Equal(t, data[lvlids.MapNotesPointer], encoded[lvlids.MapNotesPointer], "pointer mismatch")
+
+	lvl.SetMapNotes(level.MapNotes{{X: 11, Y: 21, Text: "Key"}, {X: 1, Y: 2, Text: "Exit"}})
+	reloaded := lvltest.NewLevel(lvl.EncodeState())
+	assert.Equal(t, lvl.MapNotes(), reloaded.MapNotes(), "notes should survive encoding")
+}
+
+func TestLevelInvalidLoopConfigEntries(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	id, err := lvl.NewObject(object.ClassGun)
+	require.Nil(t, err, "no error expected creating object")
+
+	entries := lvl.LoopConfig()
+	require.Equal(t, level.LoopConfigEntryCount, len(entries), "entries expected")
+	entries[0].ObjectID = id
+	entries[1].ObjectID = id + 1
+	entries[3].ObjectID = level.ObjectID(5000)
+
+	assert.Equal(t, []int{1, 3}, lvl.InvalidLoopConfigEntries())
+}
+
+func TestLevelEncodeStartingStateResetsTileVisitedFlags(t *testing.T) {
+	lvl := level.NewLevel(resource.ID(4000), 1, emptyLocalizer{})
+	tile := lvl.Tile(1, 0)
+	require.NotNil(t, tile, "tile expected")
+	tile.Flags = level.TileFlag(0).ForRealWorld().WithTileVisited(true).WithFloorShadow(3).AsTileFlag()
+
+	data := lvl.EncodeStartingState()
+
+	var tileMap [2]level.TileMapEntry
+	err := binary.Read(bytes.NewReader(data[lvlids.TileMap]), binary.LittleEndian, &tileMap)
+	require.Nil(t, err, "no error expected decoding tile map")
+	assert.False(t, tileMap[1].Flags.ForRealWorld().TileVisited(), "tile should not be visited")
+	assert.Equal(t, 3, tileMap[1].Flags.ForRealWorld().FloorShadow(), "other flags should be kept")
+	assert.True(t, lvl.Tile(1, 0).Flags.ForRealWorld().TileVisited(), "level itself should not be modified")
+}
+
+func TestLevelEncodeStartingStateProvidesSavefileVersion(t *testing.T) {
+	lvl := level.NewLevel(resource.ID(4000), 1, emptyLocalizer{})
+
+	data := lvl.EncodeStartingState()
+
+	var version int32
+	err := binary.Read(bytes.NewReader(data[lvlids.SavefileVersion]), binary.LittleEndian, &version)
+	require.Nil(t, err, "no error expected decoding version")
+	assert.Equal(t, level.SavefileVersion, version)
+}
+
+func TestLevelSetSchedulesUpdatesSchedulerInfo(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	require.Equal(t, 1, lvl.ScheduleCapacity(), "capacity of empty level expected")
+
+	err := lvl.SetSchedules(level.ScheduleEvents{{Timestamp: 10, Type: 2}})
+	require.Nil(t, err, "no error expected")
+
+	data := lvl.EncodeState()
+	var info level.BaseInfo
+	err = binary.Read(bytes.NewReader(data[lvlids.Information]), binary.LittleEndian, &info)
+	require.Nil(t, err, "no error expected decoding info")
+	assert.Equal(t, int32(1), info.Scheduler.ScheduleCount, "count mismatch")
+	assert.Equal(t, []byte{10, 0, 2, 0, 0, 0, 0, 0}, data[lvlids.Schedules], "data mismatch")
+	assert.Equal(t, level.ScheduleEvents{{Timestamp: 10, Type: 2}}, lvl.Schedules(), "events mismatch")
+}
+
+func TestLevelSetSchedulesFailsBeyondCapacity(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+
+	err := lvl.SetSchedules(level.ScheduleEvents{{Timestamp: 10}, {Timestamp: 20}})
+
+	assert.NotNil(t, err, "error expected")
+	assert.Equal(t, 0, len(lvl.Schedules()), "no events expected")
+}
+
+func TestLevelSetScheduleCapacity(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	err := lvl.SetScheduleCapacity(3)
+	require.Nil(t, err, "no error expected resizing")
+	err = lvl.SetSchedules(level.ScheduleEvents{{Timestamp: 30}, {Timestamp: 10}, {Timestamp: 20}})
+	require.Nil(t, err, "no error expected setting events")
+
+	data := lvl.EncodeState()
+	var info level.BaseInfo
+	err = binary.Read(bytes.NewReader(data[lvlids.Information]), binary.LittleEndian, &info)
+	require.Nil(t, err, "no error expected decoding info")
+	assert.Equal(t, int32(3), info.Scheduler.Size, "size mismatch")
+	assert.Equal(t, 3*level.ScheduleEventSize, len(data[lvlids.Schedules]), "data length mismatch")
+
+	err = lvl.SetScheduleCapacity(2)
+	assert.NotNil(t, err, "error expected shrinking below active events")
+	assert.Equal(t, 3, lvl.ScheduleCapacity(), "capacity should be unchanged")
+}
+
+func TestLevelClearObjectLocationKeepsObjectInUse(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	id, err := lvl.NewObject(object.ClassSmallStuff)
+	require.Nil(t, err, "no error expected creating object")
+	obj := lvl.Object(id)
+	obj.X = level.CoordinateAt(3, 0x80)
+	obj.Y = level.CoordinateAt(4, 0x80)
+	lvl.UpdateObjectLocation(id)
+	require.True(t, lvl.IsObjectPlaced(id), "object should be placed")
+	require.NotEqual(t, int16(0), lvl.Tile(3, 4).FirstObjectIndex, "tile should refer to object")
+
+	lvl.ClearObjectLocation(id)
+
+	assert.False(t, lvl.IsObjectPlaced(id), "object should not be placed")
+	assert.Equal(t, int16(0), lvl.Tile(3, 4).FirstObjectIndex, "tile should not refer to object")
+	assert.Equal(t, byte(1), lvl.Object(id).InUse, "object should remain in use")
+}
+
+func TestLevelSetObjectClassLimitUpdatesMovedObjects(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	var created []level.ObjectID
+	for i := 0; i < 4; i++ {
+		id, err := lvl.NewObject(object.ClassGrenade)
+		require.Nil(t, err, "no error expected creating object")
+		created = append(created, id)
+	}
+	lvl.DelObject(created[0])
+	lvl.DelObject(created[1])
+
+	err := lvl.SetObjectClassLimit(object.ClassGrenade, 2)
+	require.Nil(t, err, "no error expected resizing")
+
+	active, limit := lvl.ObjectClassStats(object.ClassGrenade)
+	assert.Equal(t, 2, active, "active count mismatch")
+	assert.Equal(t, 2, limit, "limit mismatch")
+	for _, id := range created[2:] {
+		index := int(lvl.Object(id).ClassTableIndex)
+		assert.True(t, (index > 0) && (index <= 2), "object %d should refer to entry within table, has %d", id, index)
+	}
+	assert.NotEqual(t, 0, len(lvl.ObjectClassData(created[3])), "class data should be available")
+	_, err = lvl.NewObject(object.ClassGrenade)
+	assert.NotNil(t, err, "table should be full")
+}
+
+func TestLevelSetObjectClassLimitFailsForTooManyObjects(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	for i := 0; i < 3; i++ {
+		_, err := lvl.NewObject(object.ClassGrenade)
+		require.Nil(t, err, "no error expected creating object")
+	}
+
+	err := lvl.SetObjectClassLimit(object.ClassGrenade, 2)
+	assert.NotNil(t, err, "error expected")
+	_, limit := lvl.ObjectClassStats(object.ClassGrenade)
+	assert.Equal(t, level.ObjectClassInfoFor(object.ClassGrenade).EntryCount-1, limit, "limit should not be modified")
+}
+
+func TestLevelSetObjectClassLimitIsEncoded(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	err := lvl.SetObjectClassLimit(object.ClassGrenade, 100)
+	require.Nil(t, err, "no error expected resizing")
+
+	data := lvl.EncodeState()
+	info := level.ObjectClassInfoFor(object.ClassGrenade)
+	assert.Equal(t, 101*(level.ObjectClassEntryHeaderSize+info.DataSize), len(data[lvlids.ObjectClassTablesStart+int(object.ClassGrenade)]))
+}
+
+func TestLevelSetObjectLimit(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	err := lvl.SetObjectLimit(level.DefaultObjectLimit + 100)
+	require.Nil(t, err, "no error expected resizing")
+	assert.Equal(t, level.DefaultObjectLimit+100, lvl.ObjectLimit())
+
+	data := lvl.EncodeState()
+	assert.Equal(t, (int(level.DefaultObjectLimit)+101)*level.ObjectMasterEntrySize, len(data[lvlids.ObjectMasterTable]))
+}
+
+func TestLevelResizedTablesAreReloaded(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	require.Nil(t, lvl.SetObjectLimit(level.DefaultObjectLimit+100), "no error expected resizing master table")
+	require.Nil(t, lvl.SetObjectClassLimit(object.ClassGrenade, 100), "no error expected resizing class table")
+	_, err := lvl.NewObject(object.ClassGrenade)
+	require.Nil(t, err, "no error expected creating object")
+
+	reloaded := lvltest.NewLevel(lvl.EncodeState())
+
+	assert.Equal(t, level.DefaultObjectLimit+100, reloaded.ObjectLimit(), "master table size should be kept")
+	active, limit := reloaded.ObjectClassStats(object.ClassGrenade)
+	assert.Equal(t, 1, active, "active objects should be kept")
+	assert.Equal(t, 100, limit, "class table size should be kept")
+}
+
+func TestLevelCompactObjectsRenumbersDensely(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	var created []level.ObjectID
+	for i := 0; i < 5; i++ {
+		id, err := lvl.NewObject(object.ClassGrenade)
+		require.Nil(t, err, "no error expected creating object")
+		obj := lvl.Object(id)
+		obj.X = level.CoordinateAt(byte(10+i), 0x80)
+		obj.Y = level.CoordinateAt(20, 0x80)
+		obj.Hitpoints = int16(100 + i)
+		lvl.UpdateObjectLocation(id)
+		lvl.ObjectClassData(id)[0] = byte(0xA0 + i)
+		created = append(created, id)
+	}
+	lvl.DelObject(created[0])
+	lvl.DelObject(created[2])
+	lvl.SetSurveillanceSource(0, created[4])
+	lvl.SetSurveillanceSurrogate(0, created[2])
+
+	mapping, released := lvl.CompactObjects()
+
+	require.Equal(t, map[level.ObjectID]level.ObjectID{created[1]: 1, created[3]: 2, created[4]: 3}, mapping)
+	assert.Equal(t, 0, released, "no class entries should be released")
+	for _, index := range []int{1, 3, 4} {
+		newID := mapping[created[index]]
+		obj := lvl.Object(newID)
+		assert.Equal(t, int16(100+index), obj.Hitpoints, "entry of object %d should be moved", newID)
+		assert.Equal(t, byte(0xA0+index), lvl.ObjectClassData(newID)[0], "class data of object %d should be moved", newID)
+		assert.True(t, lvl.IsObjectPlaced(newID), "object %d should be placed", newID)
+		assert.Equal(t, []level.ObjectID{newID}, tileObjects(lvl, 10+index, 20), "tile should refer to object %d", newID)
+	}
+	assert.Equal(t, 0, len(tileObjects(lvl, 10, 20)), "tile of deleted object should be empty")
+	assert.Equal(t, level.ObjectID(3), lvl.SurveillanceSources()[0], "surveillance source should be remapped")
+	assert.Equal(t, level.ObjectID(0), lvl.SurveillanceSurrogates()[0], "reference to unused object should be cleared")
+	active, _ := lvl.ObjectClassStats(object.ClassGrenade)
+	assert.Equal(t, 3, active, "class table should keep all entries")
+
+	id, err := lvl.NewObject(object.ClassGrenade)
+	require.Nil(t, err, "no error expected creating object after compaction")
+	assert.Equal(t, level.ObjectID(4), id, "next free ID expected")
+}
+
+func TestLevelCompactObjectsReleasesOrphanedClassEntries(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	orphaned, err := lvl.NewObject(object.ClassGrenade)
+	require.Nil(t, err, "no error expected creating object")
+	kept, err := lvl.NewObject(object.ClassGrenade)
+	require.Nil(t, err, "no error expected creating object")
+	lvl.ObjectClassData(kept)[0] = 0x42
+	lvl.Object(orphaned).ClassTableIndex = 0
+	lvl.DelObject(orphaned)
+	active, _ := lvl.ObjectClassStats(object.ClassGrenade)
+	require.Equal(t, 2, active, "orphaned entry should still be in use")
+
+	mapping, released := lvl.CompactObjects()
+
+	assert.Equal(t, 1, released, "orphaned entry should be released")
+	active, _ = lvl.ObjectClassStats(object.ClassGrenade)
+	assert.Equal(t, 1, active, "only the entry of the kept object should remain")
+	assert.Equal(t, level.ObjectID(1), mapping[kept])
+	assert.Equal(t, byte(0x42), lvl.ObjectClassData(mapping[kept])[0], "class data of kept object should be moved")
+}
+
+func tileObjects(lvl *level.Level, x, y int) []level.ObjectID {
+	data := lvl.EncodeState()[lvlids.ObjectCrossRefTable]
+	table := make(level.ObjectCrossReferenceTable, len(data)/level.ObjectCrossReferenceEntrySize)
+	_ = binary.Read(bytes.NewReader(data), binary.LittleEndian, table)
+	var ids []level.ObjectID
+	for index := lvl.Tile(x, y).FirstObjectIndex; (index != 0) && (len(ids) < len(table)); index = table[index].NextInTile {
+		ids = append(ids, table[index].ObjectID)
+	}
+	return ids
+}
@@ -0,0 +1,133 @@
+package level
+
+import (
+	"math"
+
+	"github.com/inkyblackness/hacked/ss1/content/object"
+)
+
+// ShadowMax is the highest shadow value, describing complete darkness.
+const ShadowMax = 15
+
+// LightingParameters control the automatic computation of tile shadows.
+type LightingParameters struct {
+	// Ambient is the light level present in every tile. Range: [0..ShadowMax].
+	Ambient int
+	// Intensity is the light level a source adds at its own position. Range: [0..ShadowMax].
+	Intensity int
+	// Radius is the distance, in tiles, at which a light source no longer contributes.
+	Radius float32
+	// IsSource returns true for objects that emit light.
+	IsSource func(object.Triple) bool
+}
+
+// DefaultLightingParameters returns parameters that consider all objects of the lighting subclass as sources.
+func DefaultLightingParameters() LightingParameters {
+	return LightingParameters{
+		Ambient:   4,
+		Intensity: 12,
+		Radius:    6,
+		IsSource: func(triple object.Triple) bool {
+			return (triple.Class == object.ClassBigStuff) && (triple.Subclass == 3)
+		},
+	}
+}
+
+// ShadowMap holds computed shadow values of a level.
+type ShadowMap struct {
+	Width  int
+	Height int
+	// Shadows are stored row by row, starting with the lowest Y coordinate.
+	Shadows []int
+}
+
+// Shadow returns the shadow value at given position. Returns ShadowMax if out of range.
+func (shadows ShadowMap) Shadow(x, y int) int {
+	if (x < 0) || (x >= shadows.Width) || (y < 0) || (y >= shadows.Height) {
+		return ShadowMax
+	}
+	return shadows.Shadows[y*shadows.Width+x]
+}
+
+type lightSource struct {
+	x, y float32
+}
+
+// ComputeShadows calculates the shadow of each tile, based on the light sources placed in the level.
+// Light falls off linearly with the distance to a source and is blocked by solid tiles.
+func (lvl *Level) ComputeShadows(param LightingParameters) ShadowMap {
+	width, height, _ := lvl.Size()
+	shadows := ShadowMap{Width: width, Height: height, Shadows: make([]int, width*height)}
+	var sources []lightSource
+	if param.IsSource != nil {
+		lvl.ForEachObject(func(id ObjectID, entry ObjectMasterEntry) {
+			if param.IsSource(entry.Triple()) {
+				sources = append(sources, lightSource{
+					x: float32(entry.X.Tile()) + float32(entry.X.Fine())/256,
+					y: float32(entry.Y.Tile()) + float32(entry.Y.Fine())/256,
+				})
+			}
+		})
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			light := float32(param.Ambient)
+			centerX, centerY := float32(x)+0.5, float32(y)+0.5
+			for _, source := range sources {
+				distance := float32(math.Hypot(float64(centerX-source.x), float64(centerY-source.y)))
+				if (distance >= param.Radius) || lvl.isLightBlocked(source.x, source.y, x, y) {
+					continue
+				}
+				light += float32(param.Intensity) * (1 - distance/param.Radius)
+			}
+			shadow := ShadowMax - int(math.Round(float64(light)))
+			if shadow < 0 {
+				shadow = 0
+			} else if shadow > ShadowMax {
+				shadow = ShadowMax
+			}
+			shadows.Shadows[y*width+x] = shadow
+		}
+	}
+	return shadows
+}
+
+// isLightBlocked returns true if a solid tile lies between the source and the center of the target tile.
+func (lvl *Level) isLightBlocked(fromX, fromY float32, toTileX, toTileY int) bool {
+	toX, toY := float32(toTileX)+0.5, float32(toTileY)+0.5
+	deltaX, deltaY := toX-fromX, toY-fromY
+	steps := int(math.Ceil(math.Hypot(float64(deltaX), float64(deltaY)) * 4))
+	for step := 1; step < steps; step++ {
+		fraction := float32(step) / float32(steps)
+		x := int(math.Floor(float64(fromX + deltaX*fraction)))
+		y := int(math.Floor(float64(fromY + deltaY*fraction)))
+		if (x == toTileX) && (y == toTileY) {
+			continue
+		}
+		tile := lvl.Tile(x, y)
+		if (tile == nil) || (tile.Type == TileTypeSolid) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyShadows sets the floor and ceiling shadows of all tiles according to the given map.
+// The light delta of the tiles is cleared, as it would otherwise offset the computed shadows.
+// Solid tiles, and tiles of cyberspace levels, are not modified.
+func (lvl *Level) ApplyShadows(shadows ShadowMap) {
+	if lvl.IsCyberspace() {
+		return
+	}
+	for y := 0; y < shadows.Height; y++ {
+		for x := 0; x < shadows.Width; x++ {
+			tile := lvl.Tile(x, y)
+			if (tile == nil) || (tile.Type == TileTypeSolid) {
+				continue
+			}
+			shadow := shadows.Shadow(x, y)
+			tile.Flags = tile.Flags.ForRealWorld().WithFloorShadow(shadow).WithCeilingShadow(shadow).AsTileFlag()
+			tile.LightDelta = 0
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package level_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvltest"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func litLevel(t *testing.T) *level.Level {
+	t.Helper()
+	lvl := lvltest.EmptyLevel(nil)
+	for x := 2; x <= 12; x++ {
+		lvl.Tile(x, 5).Type = level.TileTypeOpen
+	}
+	lvl.Tile(8, 5).Type = level.TileTypeSolid
+	id, err := lvl.NewObject(object.ClassBigStuff)
+	require.Nil(t, err, "no error expected creating object")
+	obj := lvl.Object(id)
+	obj.Subclass = 3
+	obj.X = level.CoordinateAt(4, 0x80)
+	obj.Y = level.CoordinateAt(5, 0x80)
+	lvl.UpdateObjectLocation(id)
+	return lvl
+}
+
+func TestLevelComputeShadowsFallsOffWithDistance(t *testing.T) {
+	lvl := litLevel(t)
+
+	shadows := lvl.ComputeShadows(level.DefaultLightingParameters())
+
+	assert.True(t, shadows.Shadow(4, 5) < shadows.Shadow(6, 5), "shadow should increase with distance")
+	assert.Equal(t, level.ShadowMax-4, shadows.Shadow(20, 20), "only ambient light expected far away")
+}
+
+func TestLevelComputeShadowsIsBlockedBySolidTiles(t *testing.T) {
+	lvl := litLevel(t)
+	param := level.DefaultLightingParameters()
+	param.Radius = 20
+
+	shadows := lvl.ComputeShadows(param)
+
+	assert.Equal(t, level.ShadowMax-param.Ambient, shadows.Shadow(9, 5), "light should be blocked")
+	assert.True(t, shadows.Shadow(7, 5) < level.ShadowMax-param.Ambient, "light should reach before block")
+}
+
+func TestLevelApplyShadows(t *testing.T) {
+	lvl := litLevel(t)
+	shadows := lvl.ComputeShadows(level.DefaultLightingParameters())
+	lvl.Tile(5, 5).LightDelta = 0x21
+
+	lvl.ApplyShadows(shadows)
+
+	flags := lvl.Tile(5, 5).Flags.ForRealWorld()
+	assert.Equal(t, shadows.Shadow(5, 5), flags.FloorShadow())
+	assert.Equal(t, shadows.Shadow(5, 5), flags.CeilingShadow())
+	assert.Equal(t, byte(0), lvl.Tile(5, 5).LightDelta, "light delta should be cleared")
+}
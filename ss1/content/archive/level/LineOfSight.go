@@ -0,0 +1,68 @@
+package level
+
+import "math"
+
+// lineOfSightStepsPerTile is the amount of samples taken per tile when checking a line of sight.
+const lineOfSightStepsPerTile = 16
+
+// FloorHeightAt returns the height of the floor at given map position, in tiles.
+// The position is given in tiles, with the fraction describing the location within the tile.
+// The second return value is false if the position is not within an open tile.
+func (lvl *Level) FloorHeightAt(x, y float32) (float32, bool) {
+	tile, fineX, fineY := lvl.tileAt(x, y)
+	if tile == nil {
+		return 0, false
+	}
+	factors := tile.Flags.SlopeControl().FloorSlopeFactors(tile.Type)
+	raw := float32(tile.Floor.AbsoluteHeight()) + factors.At(fineX, fineY)*float32(tile.SlopeHeight)
+	return lvl.tileHeightValue(raw), true
+}
+
+// CeilingHeightAt returns the height of the ceiling at given map position, in tiles.
+// The position is given in tiles, with the fraction describing the location within the tile.
+// The second return value is false if the position is not within an open tile.
+func (lvl *Level) CeilingHeightAt(x, y float32) (float32, bool) {
+	tile, fineX, fineY := lvl.tileAt(x, y)
+	if tile == nil {
+		return 0, false
+	}
+	factors := tile.Flags.SlopeControl().CeilingSlopeFactors(tile.Type)
+	raw := float32(tile.Ceiling.AbsoluteHeight()) - factors.At(fineX, fineY)*float32(tile.SlopeHeight)
+	return lvl.tileHeightValue(raw), true
+}
+
+// LineOfSight returns true if the straight line between the two given points only passes through open space.
+// Positions and heights are given in tiles. The line is blocked by solid tiles, as well as floors and ceilings.
+func (lvl *Level) LineOfSight(fromX, fromY, fromZ, toX, toY, toZ float32) bool {
+	deltaX, deltaY, deltaZ := toX-fromX, toY-fromY, toZ-fromZ
+	steps := int(math.Ceil(math.Hypot(float64(deltaX), float64(deltaY)) * lineOfSightStepsPerTile))
+	for step := 1; step < steps; step++ {
+		fraction := float32(step) / float32(steps)
+		x, y, z := fromX+deltaX*fraction, fromY+deltaY*fraction, fromZ+deltaZ*fraction
+		floor, floorOpen := lvl.FloorHeightAt(x, y)
+		ceiling, ceilingOpen := lvl.CeilingHeightAt(x, y)
+		if !floorOpen || !ceilingOpen || (z < floor) || (z > ceiling) {
+			return false
+		}
+	}
+	return true
+}
+
+func (lvl *Level) tileAt(x, y float32) (tile *TileMapEntry, fineX, fineY float32) {
+	if (x < 0) || (y < 0) {
+		return nil, 0, 0
+	}
+	tileX, tileY := math.Floor(float64(x)), math.Floor(float64(y))
+	tile = lvl.Tile(int(tileX), int(tileY))
+	fineX, fineY = x-float32(tileX), y-float32(tileY)
+	if (tile == nil) || !tile.Type.Info().IsOpenAt(fineX, fineY) {
+		return nil, 0, 0
+	}
+	return tile, fineX, fineY
+}
+
+func (lvl *Level) tileHeightValue(raw float32) float32 {
+	_, _, heightShift := lvl.Size()
+	value, _ := heightShift.valueFromScale(raw, float64(TileHeightUnitMax))
+	return value
+}
@@ -0,0 +1,85 @@
+package level_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvltest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func corridorLevel(t *testing.T) *level.Level {
+	t.Helper()
+	lvl := lvltest.EmptyLevel(nil)
+	for x := 2; x <= 10; x++ {
+		tile := lvl.Tile(x, 5)
+		tile.Type = level.TileTypeOpen
+		tile.Floor = tile.Floor.WithAbsoluteHeight(0)
+		tile.Ceiling = tile.Ceiling.WithAbsoluteHeight(16)
+	}
+	return lvl
+}
+
+func TestLevelFloorHeightAt(t *testing.T) {
+	lvl := corridorLevel(t)
+	tile := lvl.Tile(4, 5)
+	tile.Floor = tile.Floor.WithAbsoluteHeight(8)
+	_, _, heightShift := lvl.Size()
+	expected, _ := heightShift.ValueFromTileHeight(8)
+
+	height, open := lvl.FloorHeightAt(4.5, 5.5)
+	assert.True(t, open, "tile should be open")
+	assert.Equal(t, expected, height)
+
+	_, open = lvl.FloorHeightAt(4.5, 6.5)
+	assert.False(t, open, "solid tile should not be open")
+}
+
+func TestLevelLineOfSightAlongCorridor(t *testing.T) {
+	lvl := corridorLevel(t)
+
+	assert.True(t, lvl.LineOfSight(2.5, 5.5, 0.5, 10.5, 5.5, 0.5))
+}
+
+func TestLevelLineOfSightBlockedBySolidTile(t *testing.T) {
+	lvl := corridorLevel(t)
+	lvl.Tile(6, 5).Type = level.TileTypeSolid
+
+	assert.False(t, lvl.LineOfSight(2.5, 5.5, 0.5, 10.5, 5.5, 0.5))
+}
+
+func TestLevelLineOfSightBlockedByRaisedFloor(t *testing.T) {
+	lvl := corridorLevel(t)
+	tile := lvl.Tile(6, 5)
+	tile.Floor = tile.Floor.WithAbsoluteHeight(12)
+
+	assert.False(t, lvl.LineOfSight(2.5, 5.5, 0.5, 10.5, 5.5, 0.5))
+}
+
+func TestLevelFloorHeightAtValley(t *testing.T) {
+	lvl := corridorLevel(t)
+	tile := lvl.Tile(4, 5)
+	tile.Type = level.TileTypeValleySouthEastToNorthWest
+	tile.SlopeHeight = 8
+	_, _, heightShift := lvl.Size()
+	expected, _ := heightShift.ValueFromTileHeight(4)
+
+	height, open := lvl.FloorHeightAt(4.5, 5.5)
+	assert.True(t, open, "tile should be open")
+	assert.Equal(t, expected, height, "center of valley should be on the fold")
+}
+
+func TestLevelLineOfSightOverRidge(t *testing.T) {
+	lvl := corridorLevel(t)
+	tile := lvl.Tile(6, 5)
+	tile.Type = level.TileTypeRidgeNorthWestToSouthEast
+	tile.SlopeHeight = 16
+	tile.Flags = tile.Flags.WithSlopeControl(level.TileSlopeControlCeilingFlat)
+	_, _, heightShift := lvl.Size()
+	low, _ := heightShift.ValueFromTileHeight(2)
+	high, _ := heightShift.ValueFromTileHeight(10)
+
+	assert.False(t, lvl.LineOfSight(2.5, 5.5, low, 10.5, 5.5, low), "line below the fold should be blocked")
+	assert.True(t, lvl.LineOfSight(2.5, 5.5, high, 10.5, 5.5, high), "line above the fold should pass")
+}
@@ -6,3 +6,24 @@ const (
 	// LoopConfigEntrySize describes the size, in bytes, of a loop config entry.
 	LoopConfigEntrySize = 15
 )
+
+// LoopConfigEntry describes one repeated activity of a level, such as a moving platform or a looped trigger.
+type LoopConfigEntry struct {
+	// ObjectID refers to the object that is driven by the loop. Zero marks an unused entry.
+	ObjectID ObjectID
+	// Flags describe how the loop advances.
+	Flags byte
+	// CallbackType identifies the handler that is called for each cycle.
+	CallbackType uint16
+	// UserData is passed on to the handler.
+	UserData uint32
+	// FrameTime is the duration of one step, in milliseconds.
+	FrameTime uint16
+	// TimeRemainder is the time left until the next step, in milliseconds.
+	TimeRemainder uint32
+}
+
+// IsInUse returns true if the entry refers to an object.
+func (entry LoopConfigEntry) IsInUse() bool {
+	return entry.ObjectID != 0
+}
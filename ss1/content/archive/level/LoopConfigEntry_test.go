@@ -0,0 +1,16 @@
+package level_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoopConfigEntrySerializedSize(t *testing.T) {
+	var entry level.LoopConfigEntry
+	size := binary.Size(&entry)
+	assert.Equal(t, level.LoopConfigEntrySize, size, "Size mismatch")
+}
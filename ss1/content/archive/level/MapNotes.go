@@ -1,5 +1,11 @@
 package level
 
+import (
+	"bytes"
+
+	"github.com/inkyblackness/hacked/ss1/content/text"
+)
+
 const (
 	// MapNotesSize is the size, in bytes, of the map notes resource.
 	MapNotesSize = 0x0800
@@ -7,3 +13,67 @@ const (
 
 // MapNotesPointer is an offset into the map notes resource.
 type MapNotesPointer uint32
+
+// MapNote is a text note that is shown on the automap at a specific tile.
+type MapNote struct {
+	// X is the tile column of the note.
+	X byte
+	// Y is the tile row of the note.
+	Y byte
+	// Text is the content of the note.
+	Text string
+}
+
+// MapNotes is the list of notes of a level.
+// In serialized form, each note is stored with its tile position, followed by its zero-terminated text.
+// The notes are stored one after the other, the map notes pointer refers to the end of the last note.
+type MapNotes []MapNote
+
+// DecodeMapNotes returns the notes from given serialized data.
+// Only the data up to given pointer is considered. Incomplete entries are ignored.
+func DecodeMapNotes(data []byte, used MapNotesPointer) MapNotes {
+	notes := MapNotes{}
+	cp := text.DefaultCodepage()
+	if int(used) < len(data) {
+		data = data[:used]
+	}
+	for len(data) > 2 {
+		end := bytes.IndexByte(data[2:], 0x00)
+		if end < 0 {
+			break
+		}
+		notes = append(notes, MapNote{X: data[0], Y: data[1], Text: cp.Decode(data[2 : 2+end+1])})
+		data = data[2+end+1:]
+	}
+	return notes
+}
+
+// Size returns the amount of bytes the notes need in serialized form.
+func (notes MapNotes) Size() int {
+	cp := text.DefaultCodepage()
+	size := 0
+	for _, note := range notes {
+		size += 2 + len(cp.Encode(note.Text))
+	}
+	return size
+}
+
+// Encode serializes the notes into data of MapNotesSize length.
+// The returned pointer refers to the end of the last note.
+// Notes that do not fit within the resource are dropped.
+func (notes MapNotes) Encode() ([]byte, MapNotesPointer) {
+	data := make([]byte, MapNotesSize)
+	cp := text.DefaultCodepage()
+	used := 0
+	for _, note := range notes {
+		encoded := cp.Encode(note.Text)
+		if (used + 2 + len(encoded)) > MapNotesSize {
+			break
+		}
+		data[used] = note.X
+		data[used+1] = note.Y
+		copy(data[used+2:], encoded)
+		used += 2 + len(encoded)
+	}
+	return data, MapNotesPointer(used)
+}
@@ -0,0 +1,49 @@
+package level_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapNotesEncode(t *testing.T) {
+	notes := level.MapNotes{{X: 1, Y: 2, Text: "ab"}, {X: 3, Y: 4, Text: "c"}}
+	data, pointer := notes.Encode()
+
+	assert.Equal(t, level.MapNotesSize, len(data), "length mismatch")
+	assert.Equal(t, level.MapNotesPointer(9), pointer, "pointer mismatch")
+	assert.Equal(t, []byte{1, 2, 'a', 'b', 0x00, 3, 4, 'c', 0x00, 0x00}, data[:10], "data mismatch")
+}
+
+func TestMapNotesSize(t *testing.T) {
+	notes := level.MapNotes{{X: 1, Y: 2, Text: "ab"}, {X: 3, Y: 4, Text: ""}}
+	assert.Equal(t, 8, notes.Size())
+}
+
+func TestDecodeMapNotes(t *testing.T) {
+	data := []byte{10, 20, 'x', 'y', 0x00, 30, 40, 'z', 0x00, 50, 60, 'w', 0x00}
+	notes := level.DecodeMapNotes(data, 9)
+
+	assert.Equal(t, level.MapNotes{{X: 10, Y: 20, Text: "xy"}, {X: 30, Y: 40, Text: "z"}}, notes)
+}
+
+func TestDecodeMapNotesIgnoresIncompleteEntries(t *testing.T) {
+	data := []byte{10, 20, 'x', 'y', 0x00, 30, 40, 'z'}
+	notes := level.DecodeMapNotes(data, level.MapNotesPointer(len(data)))
+
+	assert.Equal(t, level.MapNotes{{X: 10, Y: 20, Text: "xy"}}, notes)
+}
+
+func TestMapNotesEncodeDropsNotesBeyondSize(t *testing.T) {
+	text := make([]byte, level.MapNotesSize-3)
+	for i := range text {
+		text[i] = 'a'
+	}
+	notes := level.MapNotes{{Text: string(text)}, {Text: "b"}}
+	data, pointer := notes.Encode()
+
+	assert.Equal(t, level.MapNotesPointer(level.MapNotesSize), pointer, "pointer mismatch")
+	assert.Equal(t, notes[:1], level.DecodeMapNotes(data, pointer), "only first note expected")
+}
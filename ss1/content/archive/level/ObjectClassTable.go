@@ -1,6 +1,9 @@
 package level
 
 import (
+	"errors"
+	"sort"
+
 	"github.com/inkyblackness/hacked/ss1/content/object"
 	"github.com/inkyblackness/hacked/ss1/serial"
 )
@@ -8,6 +11,8 @@ import (
 const (
 	// ObjectClassEntryHeaderSize is the size, in bytes, of the header prefix for each class entry.
 	ObjectClassEntryHeaderSize = 6
+
+	maxTableSize = 0x8000
 )
 
 // ObjectClassEntry describes an entry in a object-class specific list.
@@ -130,3 +135,122 @@ func (table ObjectClassTable) Release(index int) {
 	entry.Next = start.Next
 	start.Next = int16(index)
 }
+
+// UsedIndices returns the indices of all entries in the used chain, starting with the head of the chain.
+func (table ObjectClassTable) UsedIndices() []int {
+	var indices []int
+	if len(table) == 0 {
+		return indices
+	}
+	index := int(table[0].ObjectID)
+	for (index > 0) && (index < len(table)) && (len(indices) < len(table)) {
+		indices = append(indices, index)
+		index = int(table[index].Next)
+	}
+	return indices
+}
+
+// Resized returns a new table with given amount of entries, including the reserved entry.
+// Entries in use keep their index, unless their index is beyond the new size. Such entries are moved
+// to free entries with the lowest index. The returned map provides the new index for each moved entry.
+// The order of the used chain is kept, the free chain is rebuilt in ascending order.
+// Returns an error if the entries in use do not fit into a table of the new size.
+func (table ObjectClassTable) Resized(size int, dataSize int) (ObjectClassTable, map[int]int, error) {
+	used := table.UsedIndices()
+	if (size < 1) || (size > maxTableSize) {
+		return nil, nil, errors.New("invalid table size")
+	}
+	if len(used) > (size - 1) {
+		return nil, nil, errors.New("too many entries in use for new size")
+	}
+	resized := make(ObjectClassTable, size)
+	resized.AllocateData(dataSize)
+
+	taken := make([]bool, size)
+	newIndices := make([]int, len(used))
+	for i, index := range used {
+		if index < size {
+			newIndices[i] = index
+			taken[index] = true
+		}
+	}
+	moved := make(map[int]int)
+	nextFree := 1
+	for i, index := range used {
+		if index < size {
+			continue
+		}
+		for taken[nextFree] {
+			nextFree++
+		}
+		newIndices[i] = nextFree
+		taken[nextFree] = true
+		moved[index] = nextFree
+	}
+
+	start := &resized[0]
+	for i, index := range used {
+		entry := &resized[newIndices[i]]
+		entry.ObjectID = table[index].ObjectID
+		copy(entry.Data, table[index].Data)
+		if i > 0 {
+			entry.Prev = int16(newIndices[i-1])
+		}
+		if (i + 1) < len(used) {
+			entry.Next = int16(newIndices[i+1])
+		}
+	}
+	if len(used) > 0 {
+		start.ObjectID = ObjectID(newIndices[0])
+		start.Prev = int16(newIndices[len(newIndices)-1])
+	}
+	lastFree := start
+	for index := 1; index < size; index++ {
+		if !taken[index] {
+			lastFree.Next = int16(index)
+			lastFree = &resized[index]
+		}
+	}
+	return resized, moved, nil
+}
+
+// Compacted returns a new table of the same size, with all entries in use moved to the lowest indices.
+// The entries keep their relative order. The returned map provides the new index for each entry in use.
+// The order of the used chain is kept, the free chain is rebuilt in ascending order.
+func (table ObjectClassTable) Compacted(dataSize int) (ObjectClassTable, map[int]int) {
+	used := table.UsedIndices()
+	sorted := append([]int{}, used...)
+	sort.Ints(sorted)
+	mapping := make(map[int]int)
+	for newIndex, oldIndex := range sorted {
+		mapping[oldIndex] = newIndex + 1
+	}
+	compacted := make(ObjectClassTable, len(table))
+	compacted.AllocateData(dataSize)
+	if len(compacted) == 0 {
+		return compacted, mapping
+	}
+
+	start := &compacted[0]
+	for i, oldIndex := range used {
+		entry := &compacted[mapping[oldIndex]]
+		entry.ObjectID = table[oldIndex].ObjectID
+		copy(entry.Data, table[oldIndex].Data)
+		if i > 0 {
+			entry.Prev = int16(mapping[used[i-1]])
+		}
+		if (i + 1) < len(used) {
+			entry.Next = int16(mapping[used[i+1]])
+		}
+	}
+	if len(used) > 0 {
+		start.ObjectID = ObjectID(mapping[used[0]])
+		start.Prev = int16(mapping[used[len(used)-1]])
+	}
+	lastFree := start
+	for index := len(used) + 1; index < len(compacted); index++ {
+		lastFree.Next = int16(index)
+		lastFree = &compacted[index]
+	}
+	return compacted, mapping
+}
@@ -128,6 +128,85 @@ func TestObjectClassTableRelease(t *testing.T) {
 	}
 }
 
+func TestObjectClassTableResizedKeepsUsedEntries(t *testing.T) {
+	table := make(level.ObjectClassTable, 5)
+	table.AllocateData(1)
+	table.Reset()
+	first := table.Allocate()
+	second := table.Allocate()
+	table[first].Data[0] = 0xA1
+	table[second].Data[0] = 0xA2
+
+	resized, moved, err := table.Resized(10, 1)
+	require.Nil(t, err, "no error expected")
+	assert.Equal(t, 10, len(resized), "size mismatch")
+	assert.Equal(t, 0, len(moved), "no entries expected to be moved")
+	assert.Equal(t, table.UsedIndices(), resized.UsedIndices(), "used chain should be kept")
+	assert.Equal(t, byte(0xA1), resized[first].Data[0], "data of first entry should be kept")
+	assert.Equal(t, byte(0xA2), resized[second].Data[0], "data of second entry should be kept")
+	for i := 0; i < 7; i++ {
+		assert.NotEqual(t, 0, resized.Allocate(), "should be able to allocate at attempt %d", i)
+	}
+	assert.Equal(t, 0, resized.Allocate(), "table should be exhausted")
+}
+
+func TestObjectClassTableResizedMovesEntriesBeyondSize(t *testing.T) {
+	table := make(level.ObjectClassTable, 6)
+	table.AllocateData(1)
+	table.Reset()
+	var allocated []int
+	for i := 0; i < 5; i++ {
+		allocated = append(allocated, table.Allocate())
+	}
+	table.Release(allocated[0])
+	table.Release(allocated[2])
+	table[5].ObjectID = 50
+	table[5].Data[0] = 0x55
+
+	resized, moved, err := table.Resized(5, 1)
+	require.Nil(t, err, "no error expected")
+	require.Equal(t, map[int]int{5: 1}, moved, "entry 5 should be moved to first free entry")
+	assert.Equal(t, level.ObjectID(50), resized[1].ObjectID, "object reference should be moved")
+	assert.Equal(t, byte(0x55), resized[1].Data[0], "data should be moved")
+	assert.Equal(t, []int{1, 4, 2}, resized.UsedIndices(), "order of used chain should be kept")
+	assert.Equal(t, int16(3), resized[0].Next, "free chain should start at remaining free entry")
+	assert.Equal(t, int16(2), resized[0].Prev, "reserved entry should refer to tail of used chain")
+
+	resized.Release(2)
+	assert.Equal(t, []int{1, 4}, resized.UsedIndices(), "release should keep chain consistent")
+}
+
+func TestObjectClassTableResizedFailsIfUsedEntriesDoNotFit(t *testing.T) {
+	table := make(level.ObjectClassTable, 4)
+	table.Reset()
+	table.Allocate()
+	table.Allocate()
+
+	_, _, err := table.Resized(2, 0)
+	assert.NotNil(t, err, "error expected")
+}
+
+func TestObjectClassTableCompacted(t *testing.T) {
+	table := make(level.ObjectClassTable, 6)
+	table.AllocateData(1)
+	table.Reset()
+	var allocated []int
+	for i := 0; i < 5; i++ {
+		allocated = append(allocated, table.Allocate())
+	}
+	table.Release(allocated[0])
+	table.Release(allocated[2])
+	table[5].Data[0] = 0x55
+
+	compacted, mapping := table.Compacted(1)
+	assert.Equal(t, map[int]int{2: 1, 4: 2, 5: 3}, mapping, "mapping mismatch")
+	assert.Equal(t, []int{3, 2, 1}, compacted.UsedIndices(), "order of used chain should be kept")
+	assert.Equal(t, byte(0x55), compacted[3].Data[0], "data should be moved")
+	assert.Equal(t, 4, compacted.Allocate(), "first free entry should follow used entries")
+	assert.Equal(t, 5, compacted.Allocate(), "second free entry should follow")
+	assert.Equal(t, 0, compacted.Allocate(), "table should be exhausted")
+}
+
 func aRandomObjectClassEntry() level.ObjectClassEntry {
 	decoder := serial.NewDecoder(rand.Reader)
 	var dataSize byte
@@ -66,6 +66,18 @@ func (table ObjectCrossReferenceTable) Allocate() int {
 	return int(index)
 }
 
+// FreeCount returns the number of entries that can still be allocated.
+func (table ObjectCrossReferenceTable) FreeCount() int {
+	if len(table) < 2 {
+		return 0
+	}
+	count := 0
+	for index := table[0].NextInTile; (index > 0) && (int(index) < len(table)) && (count < len(table)); index = table[index].NextInTile {
+		count++
+	}
+	return count
+}
+
 // Release frees the entry with given index.
 func (table ObjectCrossReferenceTable) Release(index int) {
 	if (index < 1) || (index >= len(table)) {
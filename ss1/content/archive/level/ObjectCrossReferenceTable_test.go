@@ -112,3 +112,13 @@ func aRandomObjectCrossReferenceEntry() level.ObjectCrossReferenceEntry {
 	decoder.Code(&entry)
 	return entry
 }
+
+func TestObjectCrossReferenceTableFreeCount(t *testing.T) {
+	table := make(level.ObjectCrossReferenceTable, 10)
+	table.Reset()
+	assert.Equal(t, 9, table.FreeCount(), "all entries expected free")
+	table.Allocate()
+	table.Allocate()
+	assert.Equal(t, 7, table.FreeCount(), "free count should be reduced")
+	assert.Equal(t, 0, level.ObjectCrossReferenceTable{}.FreeCount(), "empty table has no free entries")
+}
@@ -1,11 +1,20 @@
 package level
 
-import "github.com/inkyblackness/hacked/ss1/content/object"
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/inkyblackness/hacked/ss1/content/object"
+)
 
 const (
 	// ObjectMasterEntrySize describes the size, in bytes, of a ObjectMasterEntry.
 	ObjectMasterEntrySize = 27
 
+	// DefaultObjectLimit is the highest object ID of a default table. This is the capacity the original engine expects.
+	DefaultObjectLimit = ObjectID(defaultObjectMasterEntryCount - 1)
+
 	defaultObjectMasterEntryCount = 872
 )
 
@@ -121,3 +130,94 @@ func (table ObjectMasterTable) Release(id ObjectID) {
 	entry.Next = start.Next
 	start.Next = id
 }
+
+// UsedIDs returns the identifiers of all entries in the used chain, starting with the head of the chain.
+func (table ObjectMasterTable) UsedIDs() []ObjectID {
+	var ids []ObjectID
+	if len(table) == 0 {
+		return ids
+	}
+	id := ObjectID(table[0].CrossReferenceTableIndex)
+	for (id > 0) && (int(id) < len(table)) && (len(ids) < len(table)) {
+		ids = append(ids, id)
+		id = table[id].Next
+	}
+	return ids
+}
+
+// Resized returns a new table with given amount of entries, including the reserved entry.
+// As object identifiers are referenced throughout the level, entries in use are never moved.
+// The free chain is rebuilt in ascending order.
+// Returns an error if an entry in use is beyond the new size.
+func (table ObjectMasterTable) Resized(size int) (ObjectMasterTable, error) {
+	if (size < 1) || (size > maxTableSize) {
+		return nil, errors.New("invalid table size")
+	}
+	used := table.UsedIDs()
+	taken := make([]bool, size)
+	for _, id := range used {
+		if int(id) >= size {
+			return nil, fmt.Errorf("object %d is beyond new size", int(id))
+		}
+		taken[id] = true
+	}
+	resized := make(ObjectMasterTable, size)
+	for _, id := range used {
+		resized[id] = table[id]
+	}
+	start := &resized[0]
+	if len(table) > 0 {
+		start.CrossReferenceTableIndex = table[0].CrossReferenceTableIndex
+		start.Prev = table[0].Prev
+	}
+	lastFree := start
+	for index := 1; index < size; index++ {
+		if !taken[index] {
+			lastFree.Next = ObjectID(index)
+			lastFree = &resized[index]
+		}
+	}
+	return resized, nil
+}
+
+// Compacted returns a new table of the same size, with all entries in use moved to the lowest identifiers.
+// The entries keep their relative order. The returned map provides the new identifier for each entry in use.
+// The order of the used chain is kept, the free chain is rebuilt in ascending order.
+// Any references to other tables, as well as any references held by other tables, are not updated.
+func (table ObjectMasterTable) Compacted() (ObjectMasterTable, map[ObjectID]ObjectID) {
+	used := table.UsedIDs()
+	sorted := append([]ObjectID{}, used...)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a] < sorted[b] })
+	mapping := make(map[ObjectID]ObjectID)
+	for newIndex, oldID := range sorted {
+		mapping[oldID] = ObjectID(newIndex + 1)
+	}
+	compacted := make(ObjectMasterTable, len(table))
+	if len(compacted) == 0 {
+		return compacted, mapping
+	}
+
+	start := &compacted[0]
+	for i, oldID := range used {
+		entry := &compacted[mapping[oldID]]
+		*entry = table[oldID]
+		entry.Prev = 0
+		entry.Next = 0
+		if i > 0 {
+			entry.Prev = mapping[used[i-1]]
+		}
+		if (i + 1) < len(used) {
+			entry.Next = mapping[used[i+1]]
+		}
+	}
+	if len(used) > 0 {
+		start.CrossReferenceTableIndex = int16(mapping[used[0]])
+		start.Prev = mapping[used[len(used)-1]]
+	}
+	lastFree := start
+	for index := len(used) + 1; index < len(compacted); index++ {
+		lastFree.Next = ObjectID(index)
+		lastFree = &compacted[index]
+	}
+	return compacted, mapping
+}
@@ -108,3 +108,49 @@ func TestObjectMasterTableRelease(t *testing.T) {
 		assert.NotEqual(t, level.ObjectID(0), id, "should have been able to re-allocate")
 	}
 }
+
+func TestObjectMasterTableResizedKeepsUsedEntries(t *testing.T) {
+	table := make(level.ObjectMasterTable, 5)
+	table.Reset()
+	first := table.Allocate()
+	second := table.Allocate()
+	table[second].Hitpoints = 20
+
+	resized, err := table.Resized(10)
+	require.Nil(t, err, "no error expected")
+	assert.Equal(t, 10, len(resized), "size mismatch")
+	assert.Equal(t, []level.ObjectID{second, first}, resized.UsedIDs(), "used chain should be kept")
+	assert.Equal(t, int16(20), resized[second].Hitpoints, "entry should be kept")
+	for i := 0; i < 7; i++ {
+		assert.NotEqual(t, level.ObjectID(0), resized.Allocate(), "should be able to allocate at attempt %d", i)
+	}
+	assert.Equal(t, level.ObjectID(0), resized.Allocate(), "table should be exhausted")
+}
+
+func TestObjectMasterTableResizedShrinksBelowUnusedEntries(t *testing.T) {
+	table := make(level.ObjectMasterTable, 10)
+	table.Reset()
+	var allocated []level.ObjectID
+	for i := 0; i < 5; i++ {
+		allocated = append(allocated, table.Allocate())
+	}
+	table.Release(allocated[4])
+	table.Release(allocated[1])
+
+	resized, err := table.Resized(5)
+	require.Nil(t, err, "no error expected")
+	assert.Equal(t, []level.ObjectID{4, 3, 1}, resized.UsedIDs(), "used chain should be kept")
+	assert.Equal(t, level.ObjectID(2), resized[0].Next, "free chain should start at remaining free entry")
+	assert.Equal(t, level.ObjectID(0), resized[2].Next, "free chain should end at remaining free entry")
+}
+
+func TestObjectMasterTableResizedFailsIfUsedEntryIsBeyondSize(t *testing.T) {
+	table := make(level.ObjectMasterTable, 10)
+	table.Reset()
+	for i := 0; i < 3; i++ {
+		table.Allocate()
+	}
+
+	_, err := table.Resized(3)
+	assert.NotNil(t, err, "error expected")
+}
@@ -0,0 +1,187 @@
+package level
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/inkyblackness/hacked/ss1/content/object"
+)
+
+const regionTextPrefix = "HackEd level region\n"
+
+// RegionObject is an object stored in a region.
+// The position of the object is relative to the origin of the region.
+type RegionObject struct {
+	// ID is the identifier the object had in the level the region was copied from.
+	// It allows to remap references among the objects of the region.
+	ID        ObjectID
+	Entry     ObjectMasterEntry
+	ClassData []byte
+}
+
+// Region is a rectangular extract of a level map, together with the objects placed within.
+type Region struct {
+	Cyberspace bool
+	Width      int
+	Height     int
+	// Tiles are stored row by row, starting with the lowest Y coordinate.
+	Tiles   []TileMapEntry
+	Objects []RegionObject
+}
+
+// RegionFromText decodes a region that was previously serialized with Text().
+func RegionFromText(value string) (Region, error) {
+	var region Region
+	if !strings.HasPrefix(value, regionTextPrefix) {
+		return region, errors.New("text is not a level region")
+	}
+	err := json.Unmarshal([]byte(value[len(regionTextPrefix):]), &region)
+	if err != nil {
+		return region, err
+	}
+	if (region.Width < 0) || (region.Height < 0) || (len(region.Tiles) != region.Width*region.Height) {
+		return Region{}, errors.New("region has inconsistent size")
+	}
+	return region, nil
+}
+
+// Text serializes the region into a textual form, suitable for a clipboard.
+func (region Region) Text() string {
+	data, _ := json.Marshal(region)
+	return regionTextPrefix + string(data)
+}
+
+// Tile returns the tile at given position, relative to the region. Returns nil if out of range.
+func (region Region) Tile(x, y int) *TileMapEntry {
+	if (x < 0) || (x >= region.Width) || (y < 0) || (y >= region.Height) {
+		return nil
+	}
+	return &region.Tiles[y*region.Width+x]
+}
+
+// CopyRegion extracts the tiles of given rectangle, including all objects within.
+// Parts of the rectangle outside the map are stored as solid tiles.
+func (lvl *Level) CopyRegion(fromX, fromY, width, height int) Region {
+	region := Region{
+		Cyberspace: lvl.IsCyberspace(),
+		Width:      width,
+		Height:     height,
+		Tiles:      make([]TileMapEntry, width*height),
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			target := region.Tile(x, y)
+			tile := lvl.Tile(fromX+x, fromY+y)
+			if tile != nil {
+				*target = *tile
+			} else {
+				target.Reset()
+			}
+			target.FirstObjectIndex = 0
+		}
+	}
+	lvl.ForEachObject(func(id ObjectID, entry ObjectMasterEntry) {
+		if !lvl.IsObjectPlaced(id) {
+			return
+		}
+		x := int(entry.X.Tile()) - fromX
+		y := int(entry.Y.Tile()) - fromY
+		if (x < 0) || (x >= width) || (y < 0) || (y >= height) {
+			return
+		}
+		entry.X = CoordinateAt(byte(x), entry.X.Fine())
+		entry.Y = CoordinateAt(byte(y), entry.Y.Fine())
+		classData := lvl.ObjectClassData(id)
+		region.Objects = append(region.Objects, RegionObject{
+			ID:        id,
+			Entry:     entry,
+			ClassData: append([]byte{}, classData...),
+		})
+	})
+	return region
+}
+
+// PasteRegion places the given region with its origin at given position.
+// Tiles outside of the map are skipped, as are objects placed on them.
+// The pasted objects are allocated anew; their IDs are returned in the order of the region objects,
+// with 0 for skipped objects. References among the objects are not updated, see lvlobj.PasteRegion().
+// If there is not enough room for the objects, an error is returned and the level is not modified.
+func (lvl *Level) PasteRegion(region Region, toX, toY int) ([]ObjectID, error) {
+	if region.Cyberspace != lvl.IsCyberspace() {
+		return nil, errors.New("region and level differ in cyberspace property")
+	}
+	var objects []RegionObject
+	for _, obj := range region.Objects {
+		if lvl.Tile(toX+int(obj.Entry.X.Tile()), toY+int(obj.Entry.Y.Tile())) != nil {
+			objects = append(objects, obj)
+		}
+	}
+	err := lvl.checkRoomForObjects(objects)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]ObjectID, len(region.Objects))
+	for index, source := range region.Objects {
+		if lvl.Tile(toX+int(source.Entry.X.Tile()), toY+int(source.Entry.Y.Tile())) == nil {
+			continue
+		}
+		id, err := lvl.NewObject(source.Entry.Class)
+		if err != nil {
+			for _, created := range ids {
+				lvl.DelObject(created)
+			}
+			return nil, err
+		}
+		obj := lvl.Object(id)
+		obj.Subclass = source.Entry.Subclass
+		obj.Type = source.Entry.Type
+		obj.X = CoordinateAt(byte(toX+int(source.Entry.X.Tile())), source.Entry.X.Fine())
+		obj.Y = CoordinateAt(byte(toY+int(source.Entry.Y.Tile())), source.Entry.Y.Fine())
+		obj.Z = source.Entry.Z
+		obj.XRotation = source.Entry.XRotation
+		obj.YRotation = source.Entry.YRotation
+		obj.ZRotation = source.Entry.ZRotation
+		obj.Hitpoints = source.Entry.Hitpoints
+		obj.Extra = source.Entry.Extra
+		copy(lvl.ObjectClassData(id), source.ClassData)
+		lvl.UpdateObjectLocation(id)
+		ids[index] = id
+	}
+
+	for y := 0; y < region.Height; y++ {
+		for x := 0; x < region.Width; x++ {
+			tile := lvl.Tile(toX+x, toY+y)
+			if tile == nil {
+				continue
+			}
+			firstObjectIndex := tile.FirstObjectIndex
+			*tile = *region.Tile(x, y)
+			tile.FirstObjectIndex = firstObjectIndex
+		}
+	}
+	return ids, nil
+}
+
+func (lvl *Level) checkRoomForObjects(objects []RegionObject) error {
+	required := make(map[object.Class]int)
+	for _, obj := range objects {
+		required[obj.Entry.Class]++
+	}
+	for class, count := range required {
+		active, limit := lvl.ObjectClassStats(class)
+		if (limit - active) < count {
+			return errors.New("not enough room for objects of class " + class.String())
+		}
+	}
+	used := 0
+	lvl.ForEachObject(func(ObjectID, ObjectMasterEntry) { used++ })
+	if (int(lvl.ObjectLimit()) - used) < len(objects) {
+		return errors.New("not enough room for objects")
+	}
+	if lvl.objectCrossRefTable.FreeCount() < len(objects) {
+		return errors.New("not enough room for object references to tiles")
+	}
+	return nil
+}
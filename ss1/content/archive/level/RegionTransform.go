@@ -0,0 +1,216 @@
+package level
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RegionTransform describes how a rectangular region of a map is rotated or mirrored.
+//
+// Rotations are counter-clockwise, as seen from above with north pointing up.
+// Object headings (ZRotation) are considered to increase clockwise, with 0 pointing north.
+type RegionTransform int
+
+// RegionTransform constants.
+const (
+	RegionRotate90         RegionTransform = 0
+	RegionRotate180        RegionTransform = 1
+	RegionRotate270        RegionTransform = 2
+	RegionMirrorEastWest   RegionTransform = 3
+	RegionMirrorNorthSouth RegionTransform = 4
+)
+
+// RegionTransforms returns all transform constants.
+func RegionTransforms() []RegionTransform {
+	return []RegionTransform{
+		RegionRotate90, RegionRotate180, RegionRotate270,
+		RegionMirrorEastWest, RegionMirrorNorthSouth,
+	}
+}
+
+// String returns the textual representation of the transform.
+func (transform RegionTransform) String() string {
+	switch transform {
+	case RegionRotate90:
+		return "Rotate 90"
+	case RegionRotate180:
+		return "Rotate 180"
+	case RegionRotate270:
+		return "Rotate 270"
+	case RegionMirrorEastWest:
+		return "Mirror East-West"
+	case RegionMirrorNorthSouth:
+		return "Mirror North-South"
+	default:
+		return fmt.Sprintf("Unknown%d", int(transform))
+	}
+}
+
+// Size returns the dimensions of a region after the transform.
+func (transform RegionTransform) Size(width, height int) (int, int) {
+	if (transform == RegionRotate90) || (transform == RegionRotate270) {
+		return height, width
+	}
+	return width, height
+}
+
+// Position transforms a fine position within a region of given tile dimensions.
+// Positions are relative to the origin of the region, with 256 units per tile.
+func (transform RegionTransform) Position(width, height int, x, y int) (int, int) {
+	fineWidth := width * 256
+	fineHeight := height * 256
+	limit := func(value, size int) int {
+		if value >= size {
+			return size - 1
+		}
+		return value
+	}
+	switch transform {
+	case RegionRotate90:
+		return limit(fineHeight-y, fineHeight), x
+	case RegionRotate180:
+		return limit(fineWidth-x, fineWidth), limit(fineHeight-y, fineHeight)
+	case RegionRotate270:
+		return y, limit(fineWidth-x, fineWidth)
+	case RegionMirrorEastWest:
+		return limit(fineWidth-x, fineWidth), y
+	case RegionMirrorNorthSouth:
+		return x, limit(fineHeight-y, fineHeight)
+	default:
+		return x, y
+	}
+}
+
+// Direction returns the direction the given one is turned into.
+func (transform RegionTransform) Direction(dir Direction) Direction {
+	switch transform {
+	case RegionRotate90:
+		return dir.Offset(-2)
+	case RegionRotate180:
+		return dir.Offset(4)
+	case RegionRotate270:
+		return dir.Offset(2)
+	case RegionMirrorEastWest:
+		return Direction((8 - int(dir)) % 8)
+	case RegionMirrorNorthSouth:
+		return Direction((12 - int(dir)) % 8)
+	default:
+		return dir
+	}
+}
+
+// TileType returns the tile type with its solid sides and slopes transformed.
+// Unknown types are returned unchanged.
+func (transform RegionTransform) TileType(tileType TileType) TileType {
+	if int(tileType) >= len(tileTypeInfoList) {
+		return tileType
+	}
+	info := tileType.Info()
+	var solidSides DirectionMask
+	var factors SlopeFactors
+	for dir := DirNorth; dir <= DirNorthWest; dir++ {
+		newDir := transform.Direction(dir)
+		if (info.SolidSides & dir.AsMask()) != 0 {
+			solidSides = solidSides.Plus(newDir)
+		}
+		factors[newDir] = info.SlopeFloorFactors[dir]
+	}
+	for _, candidate := range TileTypes() {
+		candidateInfo := candidate.Info()
+		if (candidateInfo.SolidSides == solidSides) && (candidateInfo.SlopeFloorFactors == factors) {
+			return candidate
+		}
+	}
+	return tileType
+}
+
+// TextureRotations returns the transformed rotation steps of a floor or ceiling texture.
+// Texture rotations turn clockwise. As textures can not be mirrored, mirror transforms only reflect the orientation.
+func (transform RegionTransform) TextureRotations(value int) int {
+	switch transform {
+	case RegionRotate90:
+		return value - 1
+	case RegionRotate180:
+		return value + 2
+	case RegionRotate270:
+		return value + 1
+	case RegionMirrorEastWest:
+		return -value
+	case RegionMirrorNorthSouth:
+		return 2 - value
+	default:
+		return value
+	}
+}
+
+// Heading returns the transformed heading (ZRotation) of an object.
+func (transform RegionTransform) Heading(value RotationUnit) RotationUnit {
+	switch transform {
+	case RegionRotate90:
+		return value - 0x40
+	case RegionRotate180:
+		return value + 0x80
+	case RegionRotate270:
+		return value + 0x40
+	case RegionMirrorEastWest:
+		return -value
+	case RegionMirrorNorthSouth:
+		return 0x80 - value
+	default:
+		return value
+	}
+}
+
+// Tile returns the given tile transformed.
+// The slope control needs no adaptation, as the ceiling slope is derived from the transformed tile type.
+func (transform RegionTransform) Tile(tile TileMapEntry) TileMapEntry {
+	tile.Type = transform.TileType(tile.Type)
+	tile.Floor = tile.Floor.WithTextureRotations(transform.TextureRotations(tile.Floor.TextureRotations()))
+	tile.Ceiling = tile.Ceiling.WithTextureRotations(transform.TextureRotations(tile.Ceiling.TextureRotations()))
+	return tile
+}
+
+// TransformRegion rotates or mirrors the given rectangle of the map, including all objects within.
+// The transformed region covers the same tiles, so the rectangle must be within the map,
+// and rotations by 90 or 270 degrees are only possible for square regions.
+// Objects keep their IDs.
+// An error is returned for regions that can not be transformed; the level is not modified in this case.
+func (lvl *Level) TransformRegion(fromX, fromY, width, height int, transform RegionTransform) error {
+	columns, rows, _ := lvl.Size()
+	if (width <= 0) || (height <= 0) || (fromX < 0) || (fromY < 0) || (fromX+width > columns) || (fromY+height > rows) {
+		return errors.New("region is not within the map")
+	}
+	if newWidth, newHeight := transform.Size(width, height); (newWidth != width) || (newHeight != height) {
+		return errors.New("region must be square for this transform")
+	}
+	region := lvl.CopyRegion(fromX, fromY, width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			newX, newY := transform.Position(width, height, x*256+128, y*256+128)
+			target := lvl.Tile(fromX+newX/256, fromY+newY/256)
+			firstObjectIndex := target.FirstObjectIndex
+			*target = transform.Tile(*region.Tile(x, y))
+			target.FirstObjectIndex = firstObjectIndex
+		}
+	}
+
+	var objects []ObjectID
+	lvl.ForEachObject(func(id ObjectID, entry ObjectMasterEntry) {
+		x := int(entry.X.Tile()) - fromX
+		y := int(entry.Y.Tile()) - fromY
+		if (x >= 0) && (x < width) && (y >= 0) && (y < height) {
+			objects = append(objects, id)
+		}
+	})
+	for _, id := range objects {
+		obj := lvl.Object(id)
+		relX := int(obj.X) - fromX*256
+		relY := int(obj.Y) - fromY*256
+		newX, newY := transform.Position(width, height, relX, relY)
+		obj.X = Coordinate(fromX*256 + newX)
+		obj.Y = Coordinate(fromY*256 + newY)
+		obj.ZRotation = transform.Heading(obj.ZRotation)
+		lvl.UpdateObjectLocation(id)
+	}
+	return nil
+}
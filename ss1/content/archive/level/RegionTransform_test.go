@@ -0,0 +1,107 @@
+package level_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvltest"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegionTransformTileType(t *testing.T) {
+	tt := []struct {
+		transform level.RegionTransform
+		from      level.TileType
+		expected  level.TileType
+	}{
+		{level.RegionRotate90, level.TileTypeOpen, level.TileTypeOpen},
+		{level.RegionRotate90, level.TileTypeSlopeWestToEast, level.TileTypeSlopeSouthToNorth},
+		{level.RegionRotate180, level.TileTypeSlopeWestToEast, level.TileTypeSlopeEastToWest},
+		{level.RegionRotate270, level.TileTypeSlopeWestToEast, level.TileTypeSlopeNorthToSouth},
+		{level.RegionRotate90, level.TileTypeDiagonalOpenSouthEast, level.TileTypeDiagonalOpenNorthEast},
+		{level.RegionMirrorEastWest, level.TileTypeDiagonalOpenSouthEast, level.TileTypeDiagonalOpenSouthWest},
+		{level.RegionMirrorNorthSouth, level.TileTypeDiagonalOpenSouthEast, level.TileTypeDiagonalOpenNorthEast},
+		{level.RegionMirrorEastWest, level.TileTypeSlopeSouthToNorth, level.TileTypeSlopeSouthToNorth},
+		{level.RegionMirrorNorthSouth, level.TileTypeSlopeSouthToNorth, level.TileTypeSlopeNorthToSouth},
+		{level.RegionRotate90, level.TileTypeValleySouthEastToNorthWest, level.TileTypeValleyNorthEastToSouthWest},
+		{level.RegionMirrorEastWest, level.TileTypeRidgeNorthWestToSouthEast, level.TileTypeRidgeNorthEastToSouthWest},
+	}
+	for _, tc := range tt {
+		td := tc
+		t.Run(fmt.Sprintf("%v of %v", td.transform, td.from), func(t *testing.T) {
+			assert.Equal(t, td.expected, td.transform.TileType(td.from))
+		})
+	}
+}
+
+func TestRegionTransformTileTypeIsReversible(t *testing.T) {
+	for _, tileType := range level.TileTypes() {
+		rotated := level.RegionRotate90.TileType(level.RegionRotate270.TileType(tileType))
+		assert.Equal(t, tileType, rotated, "rotation not reversible for %v", tileType)
+		mirrored := level.RegionMirrorEastWest.TileType(level.RegionMirrorEastWest.TileType(tileType))
+		assert.Equal(t, tileType, mirrored, "mirror not reversible for %v", tileType)
+	}
+}
+
+func TestLevelTransformRegionRotatesTilesAndObjects(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	lvl.Tile(10, 10).Type = level.TileTypeOpen
+	lvl.Tile(11, 10).Type = level.TileTypeSlopeWestToEast
+	lvl.Tile(11, 10).Floor = lvl.Tile(11, 10).Floor.WithTextureRotations(1)
+	id, err := lvl.NewObject(object.ClassGun)
+	require.Nil(t, err, "no error expected creating object")
+	obj := lvl.Object(id)
+	obj.X = level.CoordinateAt(11, 0x80)
+	obj.Y = level.CoordinateAt(10, 0x80)
+	obj.ZRotation = 0x40
+	lvl.UpdateObjectLocation(id)
+
+	err = lvl.TransformRegion(10, 10, 2, 2, level.RegionRotate90)
+	require.Nil(t, err, "no error expected transforming")
+
+	assert.Equal(t, level.TileTypeOpen, lvl.Tile(11, 10).Type)
+	assert.Equal(t, level.TileTypeSlopeSouthToNorth, lvl.Tile(11, 11).Type)
+	assert.Equal(t, 0, lvl.Tile(11, 11).Floor.TextureRotations())
+	assert.Equal(t, level.CoordinateAt(11, 0x80), obj.X)
+	assert.Equal(t, level.CoordinateAt(11, 0x80), obj.Y)
+	assert.Equal(t, level.RotationUnit(0), obj.ZRotation)
+	assert.NotEqual(t, int16(0), lvl.Tile(11, 11).FirstObjectIndex, "cross reference expected")
+}
+
+func TestLevelTransformRegionRejectsRotationOfNonSquareRegion(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	for x := 10; x < 13; x++ {
+		lvl.Tile(x, 10).Type = level.TileTypeOpen
+	}
+
+	for _, transform := range []level.RegionTransform{level.RegionRotate90, level.RegionRotate270} {
+		err := lvl.TransformRegion(10, 10, 3, 1, transform)
+		assert.NotNil(t, err, "error expected for %v", transform)
+		for x := 10; x < 13; x++ {
+			assert.Equal(t, level.TileTypeOpen, lvl.Tile(x, 10).Type, "tile %d should not be modified", x)
+		}
+		assert.Equal(t, level.TileTypeSolid, lvl.Tile(10, 11).Type, "tile outside region should not be modified")
+	}
+}
+
+func TestLevelTransformRegionMirrorsNonSquareRegion(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	lvl.Tile(10, 10).Type = level.TileTypeOpen
+	lvl.Tile(11, 10).Type = level.TileTypeSlopeWestToEast
+
+	err := lvl.TransformRegion(10, 10, 3, 1, level.RegionMirrorEastWest)
+	require.Nil(t, err, "no error expected")
+	assert.Equal(t, level.TileTypeSolid, lvl.Tile(10, 10).Type)
+	assert.Equal(t, level.TileTypeSlopeEastToWest, lvl.Tile(11, 10).Type)
+	assert.Equal(t, level.TileTypeOpen, lvl.Tile(12, 10).Type)
+}
+
+func TestLevelTransformRegionRejectsRegionOutsideMap(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	err := lvl.TransformRegion(62, 62, 4, 4, level.RegionRotate180)
+	assert.NotNil(t, err, "error expected")
+}
@@ -0,0 +1,117 @@
+package level_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvltest"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegionTextRoundTrip(t *testing.T) {
+	region := level.Region{
+		Width:  2,
+		Height: 1,
+		Tiles:  []level.TileMapEntry{{Type: level.TileTypeOpen}, {Type: level.TileTypeSlopeEastToWest}},
+		Objects: []level.RegionObject{
+			{ID: 12, Entry: level.ObjectMasterEntry{Class: object.ClassGun, X: level.CoordinateAt(1, 0x40)}, ClassData: []byte{1, 2}},
+		},
+	}
+
+	decoded, err := level.RegionFromText(region.Text())
+	require.Nil(t, err, "no error expected")
+	assert.Equal(t, region, decoded)
+}
+
+func TestRegionFromTextRejectsUnknownText(t *testing.T) {
+	_, err := level.RegionFromText("something else")
+	assert.NotNil(t, err, "error expected")
+}
+
+func TestLevelCopyAndPasteRegion(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	lvl.Tile(2, 3).Type = level.TileTypeOpen
+	lvl.Tile(3, 3).Type = level.TileTypeSlopeEastToWest
+	id, err := lvl.NewObject(object.ClassGun)
+	require.Nil(t, err, "no error expected creating object")
+	obj := lvl.Object(id)
+	obj.X = level.CoordinateAt(3, 0x20)
+	obj.Y = level.CoordinateAt(3, 0x30)
+	lvl.UpdateObjectLocation(id)
+	lvl.ObjectClassData(id)[0] = 0xAB
+
+	region := lvl.CopyRegion(2, 3, 2, 1)
+	require.Equal(t, 1, len(region.Objects), "object expected in region")
+
+	pastedIDs, err := lvl.PasteRegion(region, 10, 20)
+	require.Nil(t, err, "no error expected pasting")
+	assert.Equal(t, level.TileTypeOpen, lvl.Tile(10, 20).Type)
+	assert.Equal(t, level.TileTypeSlopeEastToWest, lvl.Tile(11, 20).Type)
+	require.Equal(t, 1, len(pastedIDs), "one object expected")
+	assert.NotEqual(t, id, pastedIDs[0], "new object ID expected")
+	pasted := lvl.Object(pastedIDs[0])
+	assert.Equal(t, level.CoordinateAt(11, 0x20), pasted.X)
+	assert.Equal(t, level.CoordinateAt(20, 0x30), pasted.Y)
+	assert.Equal(t, byte(0xAB), lvl.ObjectClassData(pastedIDs[0])[0])
+	assert.NotEqual(t, int16(0), lvl.Tile(11, 20).FirstObjectIndex, "cross reference expected")
+}
+
+func TestLevelCopyRegionSkipsUnplacedObjects(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	id, err := lvl.NewObject(object.ClassGun)
+	require.Nil(t, err, "no error expected creating object")
+	lvl.ClearObjectLocation(id)
+
+	region := lvl.CopyRegion(0, 0, 2, 2)
+
+	assert.Empty(t, region.Objects, "unplaced object should not be copied")
+}
+
+func TestLevelPasteRegionRejectsCyberspaceMismatch(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	_, err := lvl.PasteRegion(level.Region{Cyberspace: true}, 0, 0)
+	assert.NotNil(t, err, "error expected")
+}
+
+func TestLevelPasteRegionWithoutRoomDoesNotModifyLevel(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	active, _ := lvl.ObjectClassStats(object.ClassGun)
+	require.Nil(t, lvl.SetObjectClassLimit(object.ClassGun, active+1), "no error expected limiting class")
+	region := level.Region{
+		Width:  1,
+		Height: 1,
+		Tiles:  []level.TileMapEntry{{Type: level.TileTypeOpen}},
+		Objects: []level.RegionObject{
+			{Entry: level.ObjectMasterEntry{Class: object.ClassGun}},
+			{Entry: level.ObjectMasterEntry{Class: object.ClassGun}},
+		},
+	}
+
+	_, err := lvl.PasteRegion(region, 10, 20)
+	assert.NotNil(t, err, "error expected")
+	assert.Equal(t, level.TileTypeSolid, lvl.Tile(10, 20).Type, "tile should not be modified")
+	activeAfter, _ := lvl.ObjectClassStats(object.ClassGun)
+	assert.Equal(t, active, activeAfter, "no object should be created")
+}
+
+func TestLevelPasteRegionReturnsIDsInOrderOfRegion(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	region := level.Region{
+		Width:  1,
+		Height: 1,
+		Tiles:  []level.TileMapEntry{{Type: level.TileTypeOpen}},
+		Objects: []level.RegionObject{
+			{Entry: level.ObjectMasterEntry{Class: object.ClassGun, X: level.CoordinateAt(10, 0x80)}},
+			{Entry: level.ObjectMasterEntry{Class: object.ClassGun}},
+		},
+	}
+
+	ids, err := lvl.PasteRegion(region, 60, 0)
+	require.Nil(t, err, "no error expected")
+	require.Equal(t, 2, len(ids))
+	assert.Equal(t, level.ObjectID(0), ids[0], "object outside map should be skipped")
+	assert.NotEqual(t, level.ObjectID(0), ids[1], "object should be created")
+}
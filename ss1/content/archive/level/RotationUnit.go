@@ -1,5 +1,7 @@
 package level
 
+import "math"
+
 // RotationUnit describes a rotation in the range 0..255.
 type RotationUnit byte
 
@@ -7,3 +9,19 @@ type RotationUnit byte
 func (unit RotationUnit) ToDegrees() float64 {
 	return (float64(unit) * 360.0) / 256.0
 }
+
+// RotationUnitFromDegrees returns the rotation closest to the given angle in degrees.
+// Angles outside of [0..360) are wrapped around.
+func RotationUnitFromDegrees(degrees float64) RotationUnit {
+	return RotationUnit(int(math.Floor(degrees*256.0/360.0+0.5)) & 0xFF)
+}
+
+// SnappedTo returns the rotation rounded to the nearest multiple of the given increment.
+// An increment of zero returns the rotation unchanged.
+func (unit RotationUnit) SnappedTo(increment RotationUnit) RotationUnit {
+	if increment == 0 {
+		return unit
+	}
+	step := int(increment)
+	return RotationUnit((((int(unit) + step/2) / step) * step) & 0xFF)
+}
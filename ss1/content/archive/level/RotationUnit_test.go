@@ -0,0 +1,22 @@
+package level_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotationUnitFromDegrees(t *testing.T) {
+	assert.Equal(t, level.RotationUnit(0x00), level.RotationUnitFromDegrees(0))
+	assert.Equal(t, level.RotationUnit(0x40), level.RotationUnitFromDegrees(90))
+	assert.Equal(t, level.RotationUnit(0xC0), level.RotationUnitFromDegrees(-90))
+	assert.Equal(t, level.RotationUnit(0x00), level.RotationUnitFromDegrees(359.9))
+}
+
+func TestRotationUnitSnappedTo(t *testing.T) {
+	assert.Equal(t, level.RotationUnit(0x20), level.RotationUnit(0x1C).SnappedTo(0x20))
+	assert.Equal(t, level.RotationUnit(0x00), level.RotationUnit(0xF4).SnappedTo(0x20))
+	assert.Equal(t, level.RotationUnit(0x13), level.RotationUnit(0x13).SnappedTo(0))
+}
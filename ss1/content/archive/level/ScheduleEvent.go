@@ -0,0 +1,26 @@
+package level
+
+import "sort"
+
+// ScheduleEventSize is the size, in bytes, of one schedule event.
+const ScheduleEventSize = 8
+
+// ScheduleEvent is one entry of the level scheduler.
+type ScheduleEvent struct {
+	// Timestamp is the game time at which the event is due.
+	Timestamp uint16
+	// Type identifies the handler of the event.
+	Type uint16
+	// Data is handler specific.
+	Data [4]byte
+}
+
+// ScheduleEvents is a list of schedule events.
+type ScheduleEvents []ScheduleEvent
+
+// SortByTime orders the events by their timestamp.
+// The order of events with equal timestamp is kept.
+// A sorted list is also a valid representation of the queue of the scheduler.
+func (events ScheduleEvents) SortByTime() {
+	sort.SliceStable(events, func(a, b int) bool { return events[a].Timestamp < events[b].Timestamp })
+}
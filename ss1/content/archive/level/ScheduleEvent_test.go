@@ -0,0 +1,22 @@
+package level_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleEventSerializedSize(t *testing.T) {
+	var event level.ScheduleEvent
+	size := binary.Size(&event)
+	assert.Equal(t, level.ScheduleEventSize, size, "Size mismatch")
+}
+
+func TestScheduleEventsSortByTime(t *testing.T) {
+	events := level.ScheduleEvents{{Timestamp: 30, Type: 1}, {Timestamp: 10, Type: 2}, {Timestamp: 30, Type: 3}, {Timestamp: 20, Type: 4}}
+	events.SortByTime()
+	assert.Equal(t, level.ScheduleEvents{{Timestamp: 10, Type: 2}, {Timestamp: 20, Type: 4}, {Timestamp: 30, Type: 1}, {Timestamp: 30, Type: 3}}, events)
+}
@@ -11,6 +11,30 @@ func (factors SlopeFactors) Negated() SlopeFactors {
 	}
 }
 
+// At returns the factor at given position within the tile, interpolated from the corners.
+// The position is in range [0.0 .. 1.0], with 0.0 being the western, respectively southern, edge.
+// Tiles that are not planar, such as valleys and ridges, consist of two triangles folded along a diagonal.
+// The diagonal runs through the corner that differs from the others.
+func (factors SlopeFactors) At(fineX, fineY float32) float32 {
+	sw, se := factors[DirSouthWest], factors[DirSouthEast]
+	nw, ne := factors[DirNorthWest], factors[DirNorthEast]
+	if sw+ne == se+nw {
+		return sw + (se-sw)*fineX + (nw-sw)*fineY
+	}
+	if sw == ne {
+		// folded along the diagonal from south-east to north-west
+		if fineX+fineY <= 1 {
+			return sw + (se-sw)*fineX + (nw-sw)*fineY
+		}
+		return ne + (nw-ne)*(1-fineX) + (se-ne)*(1-fineY)
+	}
+	// folded along the diagonal from south-west to north-east
+	if fineX >= fineY {
+		return sw + (se-sw)*fineX + (ne-se)*fineY
+	}
+	return sw + (ne-nw)*fineX + (nw-sw)*fineY
+}
+
 // TileTypeInfo is the meta information about a tile type.
 type TileTypeInfo struct {
 	// Name is the textual representation of the tile type.
@@ -27,6 +51,28 @@ type TileTypeInfo struct {
 	SlopeInvertedType TileType
 }
 
+// IsOpenAt returns true if the given position within the tile is not blocked by a solid part.
+// The position is in range [0.0 .. 1.0], with 0.0 being the western, respectively southern, edge.
+func (info TileTypeInfo) IsOpenAt(fineX, fineY float32) bool {
+	isSolid := func(dir Direction) bool { return (info.SolidSides & dir.AsMask()) != 0 }
+	if isSolid(DirNorth) && isSolid(DirSouth) {
+		return false
+	}
+	if isSolid(DirNorth) && isSolid(DirWest) {
+		return fineY < fineX
+	}
+	if isSolid(DirNorth) && isSolid(DirEast) {
+		return fineY < (1.0 - fineX)
+	}
+	if isSolid(DirSouth) && isSolid(DirEast) {
+		return fineY > fineX
+	}
+	if isSolid(DirSouth) && isSolid(DirWest) {
+		return fineY > (1.0 - fineX)
+	}
+	return true
+}
+
 // nolint: govet
 var tileTypeInfoList = []TileTypeInfo{
 	{"Solid", DirNorth.Plus(DirEast).Plus(DirSouth).Plus(DirWest), SlopeFactors{0, 0, 0, 0, 0, 0, 0, 0}, TileTypeSolid},
@@ -0,0 +1,50 @@
+package level_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlopeFactorsAt(t *testing.T) {
+	tt := []struct {
+		tileType level.TileType
+		x, y     float32
+		expected float32
+	}{
+		{tileType: level.TileTypeOpen, x: 0.3, y: 0.7, expected: 0.0},
+		{tileType: level.TileTypeSlopeSouthToNorth, x: 0.3, y: 0.25, expected: 0.25},
+		{tileType: level.TileTypeSlopeWestToEast, x: 0.75, y: 0.1, expected: 0.75},
+
+		{tileType: level.TileTypeValleySouthEastToNorthWest, x: 0.5, y: 0.5, expected: 0.5},
+		{tileType: level.TileTypeValleySouthEastToNorthWest, x: 0.25, y: 0.25, expected: 0.75},
+		{tileType: level.TileTypeValleySouthEastToNorthWest, x: 0.75, y: 0.75, expected: 0.75},
+		{tileType: level.TileTypeValleySouthEastToNorthWest, x: 0.75, y: 0.5, expected: 0.5},
+		{tileType: level.TileTypeValleySouthWestToNorthEast, x: 0.5, y: 0.5, expected: 0.5},
+		{tileType: level.TileTypeValleySouthWestToNorthEast, x: 0.75, y: 0.25, expected: 0.75},
+		{tileType: level.TileTypeValleySouthWestToNorthEast, x: 0.25, y: 0.75, expected: 0.75},
+
+		{tileType: level.TileTypeRidgeNorthWestToSouthEast, x: 0.5, y: 0.5, expected: 0.5},
+		{tileType: level.TileTypeRidgeNorthWestToSouthEast, x: 0.25, y: 0.25, expected: 0.25},
+		{tileType: level.TileTypeRidgeNorthWestToSouthEast, x: 0.75, y: 0.75, expected: 0.25},
+		{tileType: level.TileTypeRidgeSouthWestToNorthEast, x: 0.5, y: 0.5, expected: 0.5},
+		{tileType: level.TileTypeRidgeSouthWestToNorthEast, x: 0.75, y: 0.25, expected: 0.25},
+		{tileType: level.TileTypeRidgeSouthWestToNorthEast, x: 0.25, y: 0.75, expected: 0.25},
+	}
+	for _, tc := range tt {
+		factors := tc.tileType.Info().SlopeFloorFactors
+		assert.InDelta(t, tc.expected, factors.At(tc.x, tc.y), 0.0001, "wrong factor for %v at (%v, %v)", tc.tileType, tc.x, tc.y)
+	}
+}
+
+func TestSlopeFactorsAtMatchesCorners(t *testing.T) {
+	for _, tileType := range level.TileTypes() {
+		factors := tileType.Info().SlopeFloorFactors
+		assert.Equal(t, factors[level.DirSouthWest], factors.At(0, 0), "south-west of %v", tileType)
+		assert.Equal(t, factors[level.DirSouthEast], factors.At(1, 0), "south-east of %v", tileType)
+		assert.Equal(t, factors[level.DirNorthWest], factors.At(0, 1), "north-west of %v", tileType)
+		assert.Equal(t, factors[level.DirNorthEast], factors.At(1, 1), "north-east of %v", tileType)
+	}
+}
@@ -0,0 +1,110 @@
+package level
+
+import "math"
+
+// TilePosition identifies a tile on the map.
+type TilePosition struct {
+	X int
+	Y int
+}
+
+// WallTextureBase returns the height, in tile height units, from which the wall textures of the tile
+// are applied upwards, before the wall texture offset is added. This is the lowest point of the floor.
+func (tile TileMapEntry) WallTextureBase() int {
+	factors := tile.Flags.SlopeControl().FloorSlopeFactors(tile.Type)
+	lowestFactor := factors[0]
+	for _, factor := range factors {
+		if factor < lowestFactor {
+			lowestFactor = factor
+		}
+	}
+	return int(tile.Floor.AbsoluteHeight()) + int(lowestFactor*float32(tile.SlopeHeight))
+}
+
+// AlignWallTextures sets the wall texture offsets of the given tiles so that the wall textures line up
+// vertically across connected tiles, see WallTextureBase().
+// Tiles are connected if they share an open side and the space between their floor and ceiling overlaps.
+// Each group of connected tiles is aligned to the first tile of the group, as listed in the given positions,
+// and takes over its wall texture pattern. A group of a single tile is aligned to its first connected
+// neighbor instead, if there is one. Solid tiles are ignored, as are cyberspace levels.
+// For height shifts above 5, the alignment is limited by the range of the offset.
+func (lvl *Level) AlignWallTextures(positions []TilePosition) {
+	if lvl.IsCyberspace() {
+		return
+	}
+	_, _, heightShift := lvl.Size()
+	period := int(TileHeightUnitMax)
+	if (heightShift >= 0) && (int(heightShift) < len(tileHeights)) {
+		period = int(math.Max(1, float64(TileHeightUnitMax)/tileHeights[heightShift]))
+	}
+	pending := make(map[TilePosition]bool)
+	for _, pos := range positions {
+		tile := lvl.Tile(pos.X, pos.Y)
+		if (tile != nil) && (tile.Type != TileTypeSolid) {
+			pending[pos] = true
+		}
+	}
+	for _, start := range positions {
+		if !pending[start] {
+			continue
+		}
+		delete(pending, start)
+		group := []TilePosition{start}
+		for index := 0; index < len(group); index++ {
+			for _, neighbor := range lvl.wallConnectedNeighbors(group[index]) {
+				if pending[neighbor] {
+					delete(pending, neighbor)
+					group = append(group, neighbor)
+				}
+			}
+		}
+		anchorPos := start
+		if neighbors := lvl.wallConnectedNeighbors(start); (len(group) == 1) && (len(neighbors) > 0) {
+			anchorPos = neighbors[0]
+		}
+		anchorTile := lvl.Tile(anchorPos.X, anchorPos.Y)
+		anchorFlags := anchorTile.Flags.ForRealWorld()
+		anchor := anchorTile.WallTextureBase() + int(anchorFlags.WallTextureOffset())
+		for _, pos := range group {
+			tile := lvl.Tile(pos.X, pos.Y)
+			offset := ((anchor-tile.WallTextureBase())%period + period) % period
+			tile.Flags = tile.Flags.ForRealWorld().
+				WithWallTextureOffset(TileHeightUnit(offset)).
+				WithWallTexturePattern(anchorFlags.WallTexturePattern()).
+				AsTileFlag()
+		}
+	}
+}
+
+// wallConnectedNeighbors returns the adjacent tiles that continue the walls of the given tile,
+// in the order north, east, south, west.
+func (lvl *Level) wallConnectedNeighbors(pos TilePosition) []TilePosition {
+	tile := lvl.Tile(pos.X, pos.Y)
+	if (tile == nil) || (tile.Type == TileTypeSolid) {
+		return nil
+	}
+	var neighbors []TilePosition
+	for _, side := range []struct {
+		dir    Direction
+		offset TilePosition
+	}{
+		{dir: DirNorth, offset: TilePosition{X: 0, Y: 1}},
+		{dir: DirEast, offset: TilePosition{X: 1, Y: 0}},
+		{dir: DirSouth, offset: TilePosition{X: 0, Y: -1}},
+		{dir: DirWest, offset: TilePosition{X: -1, Y: 0}},
+	} {
+		neighborPos := TilePosition{X: pos.X + side.offset.X, Y: pos.Y + side.offset.Y}
+		neighbor := lvl.Tile(neighborPos.X, neighborPos.Y)
+		if (neighbor == nil) || (neighbor.Type == TileTypeSolid) ||
+			((tile.Type.Info().SolidSides & side.dir.AsMask()) != 0) ||
+			((neighbor.Type.Info().SolidSides & side.dir.Offset(4).AsMask()) != 0) {
+			continue
+		}
+		if (tile.Floor.AbsoluteHeight() >= neighbor.Ceiling.AbsoluteHeight()) ||
+			(neighbor.Floor.AbsoluteHeight() >= tile.Ceiling.AbsoluteHeight()) {
+			continue
+		}
+		neighbors = append(neighbors, neighborPos)
+	}
+	return neighbors
+}
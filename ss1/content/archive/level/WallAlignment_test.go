@@ -0,0 +1,108 @@
+package level_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvltest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type wallTestTile struct {
+	tileType     level.TileType
+	floor        level.TileHeightUnit
+	ceiling      level.TileHeightUnit
+	slope        level.TileHeightUnit
+	slopeControl level.TileSlopeControl
+}
+
+func setWallTestTile(lvl *level.Level, x, y int, setup wallTestTile) *level.TileMapEntry {
+	tile := lvl.Tile(x, y)
+	tile.Type = setup.tileType
+	tile.Floor = tile.Floor.WithAbsoluteHeight(setup.floor)
+	tile.Ceiling = tile.Ceiling.WithAbsoluteHeight(setup.ceiling)
+	tile.SlopeHeight = setup.slope
+	tile.Flags = tile.Flags.WithSlopeControl(setup.slopeControl)
+	return tile
+}
+
+func wallTextureOffset(tile *level.TileMapEntry) level.TileHeightUnit {
+	return tile.Flags.ForRealWorld().WallTextureOffset()
+}
+
+func TestTileMapEntryWallTextureBase(t *testing.T) {
+	tt := []struct {
+		setup    wallTestTile
+		expected int
+	}{
+		{setup: wallTestTile{tileType: level.TileTypeOpen, floor: 4, ceiling: 16}, expected: 4},
+		{setup: wallTestTile{tileType: level.TileTypeSlopeSouthToNorth, floor: 2, ceiling: 20, slope: 4}, expected: 2},
+		{setup: wallTestTile{tileType: level.TileTypeSlopeSouthToNorth, floor: 6, ceiling: 20, slope: 4,
+			slopeControl: level.TileSlopeControlFloorFlat}, expected: 6},
+	}
+	for _, tc := range tt {
+		lvl := lvltest.EmptyLevel(nil)
+		tile := setWallTestTile(lvl, 0, 0, tc.setup)
+		assert.Equal(t, tc.expected, tile.WallTextureBase(), "wrong base for %v", tc.setup)
+	}
+}
+
+func TestLevelAlignWallTexturesAlignsConnectedTilesAcrossFloorAndSlopeChanges(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	lvl.SetHeightShift(3) // eight height units per tile
+	first := setWallTestTile(lvl, 2, 3, wallTestTile{tileType: level.TileTypeOpen, floor: 0, ceiling: 16})
+	first.Flags = first.Flags.ForRealWorld().
+		WithWallTextureOffset(3).
+		WithWallTexturePattern(level.WallTexturePatternFlipAlternating).
+		AsTileFlag()
+	step := setWallTestTile(lvl, 3, 3, wallTestTile{tileType: level.TileTypeOpen, floor: 4, ceiling: 16})
+	ramp := setWallTestTile(lvl, 4, 3, wallTestTile{tileType: level.TileTypeSlopeWestToEast, floor: 2, ceiling: 20, slope: 4})
+	flatRamp := setWallTestTile(lvl, 5, 3, wallTestTile{tileType: level.TileTypeSlopeWestToEast, floor: 6, ceiling: 20, slope: 4,
+		slopeControl: level.TileSlopeControlFloorFlat})
+
+	lvl.AlignWallTextures([]level.TilePosition{{X: 2, Y: 3}, {X: 3, Y: 3}, {X: 4, Y: 3}, {X: 5, Y: 3}})
+
+	assert.Equal(t, level.TileHeightUnit(3), wallTextureOffset(first), "first tile should keep offset")
+	assert.Equal(t, level.TileHeightUnit(7), wallTextureOffset(step), "raised floor")
+	assert.Equal(t, level.TileHeightUnit(1), wallTextureOffset(ramp), "sloped floor")
+	assert.Equal(t, level.TileHeightUnit(5), wallTextureOffset(flatRamp), "flat floor of sloped tile")
+	for _, tile := range []*level.TileMapEntry{step, ramp, flatRamp} {
+		assert.Equal(t, level.WallTexturePatternFlipAlternating, tile.Flags.ForRealWorld().WallTexturePattern(), "pattern should be taken over")
+	}
+}
+
+func TestLevelAlignWallTexturesSkipsTilesWithoutConnectingWalls(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	lvl.SetHeightShift(3)
+	setWallTestTile(lvl, 2, 3, wallTestTile{tileType: level.TileTypeOpen, floor: 0, ceiling: 8})
+	above := setWallTestTile(lvl, 3, 3, wallTestTile{tileType: level.TileTypeOpen, floor: 10, ceiling: 20})
+	above.Flags = above.Flags.ForRealWorld().WithWallTextureOffset(5).AsTileFlag()
+
+	lvl.AlignWallTextures([]level.TilePosition{{X: 2, Y: 3}, {X: 3, Y: 3}})
+
+	assert.Equal(t, level.TileHeightUnit(5), wallTextureOffset(above), "tile with separate height range should keep offset")
+}
+
+func TestLevelAlignWallTexturesAlignsSingleTileToNeighbor(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	lvl.SetHeightShift(3)
+	neighbor := setWallTestTile(lvl, 2, 4, wallTestTile{tileType: level.TileTypeOpen, floor: 0, ceiling: 16})
+	neighbor.Flags = neighbor.Flags.ForRealWorld().WithWallTextureOffset(2).AsTileFlag()
+	tile := setWallTestTile(lvl, 2, 3, wallTestTile{tileType: level.TileTypeOpen, floor: 5, ceiling: 16})
+
+	lvl.AlignWallTextures([]level.TilePosition{{X: 2, Y: 3}})
+
+	assert.Equal(t, level.TileHeightUnit(5), wallTextureOffset(tile))
+	assert.Equal(t, level.TileHeightUnit(2), wallTextureOffset(neighbor), "neighbor should not be modified")
+}
+
+func TestLevelAlignWallTexturesIgnoresSolidTiles(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	tile := lvl.Tile(2, 3)
+	tile.Flags = tile.Flags.ForRealWorld().WithWallTextureOffset(5).AsTileFlag()
+
+	lvl.AlignWallTextures([]level.TilePosition{{X: 2, Y: 3}})
+
+	assert.Equal(t, level.TileHeightUnit(5), tile.Flags.ForRealWorld().WallTextureOffset())
+}
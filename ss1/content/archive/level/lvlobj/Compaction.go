@@ -0,0 +1,194 @@
+package lvlobj
+
+import (
+	"fmt"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/interpreters"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+)
+
+// CompactionResult describes the outcome of compacting the objects of a level.
+type CompactionResult struct {
+	// Mapping provides the new identifier for each object that was renumbered.
+	Mapping map[level.ObjectID]level.ObjectID
+	// ClearedReferences lists the references to objects not in use, which were cleared.
+	// The sources of the references are given with their new identifier.
+	ClearedReferences []DanglingReference
+	// UpdatedIncoming lists the references of other levels that were updated to the new identifiers.
+	// The references are given with their previous identifier.
+	UpdatedIncoming []IncomingReference
+	// ReleasedClassEntries is the number of class table entries without an object, which were released.
+	ReleasedClassEntries int
+}
+
+// CompactObjects renumbers all objects of the level densely, see level.CompactObjects().
+// Additionally, all object references within the class data of the objects are updated, as well as
+// the references of the other levels to moved objects, see IncomingReferences().
+//
+// Compaction is refused, and no level is modified, if moved objects could still be referenced afterwards:
+// This is the case for ambiguous incoming references, and for active schedule events, of which the
+// data is not known to hold object identifiers.
+func CompactObjects(lvl *level.Level, others []*level.Level) (CompactionResult, error) {
+	planned := lvl.CompactionMapping()
+	moved := func(id level.ObjectID) bool {
+		newID, inUse := planned[id]
+		return inUse && (newID != id)
+	}
+	var incoming []IncomingReference
+	var holders []*level.Level
+	var ambiguous *IncomingReference
+	forEachIncomingReference(lvl, others, func(holder *level.Level, ref IncomingReference) {
+		if !moved(level.ObjectID(ref.ID)) {
+			return
+		}
+		if ref.Ambiguous && (ambiguous == nil) {
+			ambiguous = &ref
+		}
+		incoming = append(incoming, ref)
+		holders = append(holders, holder)
+	})
+	if ambiguous != nil {
+		return CompactionResult{}, fmt.Errorf("elevator panel %d of level %d refers to moved object %d, "+
+			"which is an elevator panel in yet another level", int(ambiguous.Source), ambiguous.Level, ambiguous.ID)
+	}
+	if schedules := lvl.Schedules(); len(schedules) > 0 {
+		for id := range planned {
+			if moved(id) {
+				return CompactionResult{}, fmt.Errorf("level has %d active schedule events, "+
+					"which may refer to moved objects", len(schedules))
+			}
+		}
+	}
+
+	mapping, released := lvl.CompactObjects()
+	result := CompactionResult{
+		Mapping:              make(map[level.ObjectID]level.ObjectID),
+		UpdatedIncoming:      incoming,
+		ReleasedClassEntries: released,
+	}
+	for oldID, newID := range mapping {
+		if oldID != newID {
+			result.Mapping[oldID] = newID
+		}
+	}
+
+	interpreterFactory := interpreterFactoryFor(lvl)
+	for id := level.ObjectID(1); int(id) <= len(mapping); id++ {
+		for _, ref := range ObjectReferences(interpreterFactory(lvl.Object(id).Triple(), lvl.ObjectClassData(id))) {
+			target, inUse := mapping[level.ObjectID(ref.ID)]
+			if !inUse {
+				result.ClearedReferences = append(result.ClearedReferences,
+					DanglingReference{ObjectReference: ref, Source: id, Reason: DanglingReasonUnused})
+			}
+			if int(target) != ref.ID {
+				SetObjectReference(lvl, id, ref.Key, target)
+			}
+		}
+	}
+	for index, ref := range incoming {
+		SetObjectReference(holders[index], ref.Source, ref.Key, mapping[level.ObjectID(ref.ID)])
+	}
+	return result, nil
+}
+
+// IncomingReference is a reference of an object in another level, which may refer to an object of a level.
+type IncomingReference struct {
+	ObjectReference
+
+	// Level is the identifier of the level that holds the reference.
+	Level int
+	// Source identifies the object that holds the reference.
+	Source level.ObjectID
+	// Ambiguous is set if the referenced object is an elevator panel in yet another level as well.
+	// It is then not known which level the reference is meant for.
+	Ambiguous bool
+}
+
+// elevatorDestinationKeys are the fields of elevator panels that refer to the panels of other levels.
+// They are plain indices, as they refer to objects outside of their own level.
+var elevatorDestinationKeys = []string{
+	"DestinationObjectIndex1", "DestinationObjectIndex2", "DestinationObjectIndex3",
+	"DestinationObjectIndex4", "DestinationObjectIndex5", "DestinationObjectIndex6",
+}
+
+// IncomingReferences returns the references of the other levels that may refer to objects of given level.
+// These are elevator destinations that point to an elevator panel of the level.
+// As it is not known which destination refers to which level, references to an object that is
+// an elevator panel in a third level as well are marked as ambiguous.
+func IncomingReferences(lvl *level.Level, others []*level.Level) []IncomingReference {
+	var references []IncomingReference
+	forEachIncomingReference(lvl, others, func(_ *level.Level, ref IncomingReference) {
+		references = append(references, ref)
+	})
+	return references
+}
+
+func forEachIncomingReference(lvl *level.Level, others []*level.Level,
+	handler func(holder *level.Level, ref IncomingReference)) {
+	for _, other := range others {
+		if !isRelatedLevel(lvl, other) {
+			continue
+		}
+		other.ForEachObject(func(id level.ObjectID, entry level.ObjectMasterEntry) {
+			for _, ref := range elevatorDestinations(other, id, entry) {
+				if isElevatorPanel(lvl, level.ObjectID(ref.ID)) {
+					handler(other, IncomingReference{
+						ObjectReference: ref,
+						Level:           other.ID(),
+						Source:          id,
+						Ambiguous:       isElevatorPanelElsewhere(level.ObjectID(ref.ID), others, lvl, other),
+					})
+				}
+			}
+		})
+	}
+}
+
+func isRelatedLevel(lvl *level.Level, other *level.Level) bool {
+	return (other != nil) && (other != lvl) && (other.IsCyberspace() == lvl.IsCyberspace())
+}
+
+func isElevatorPanelElsewhere(id level.ObjectID, levels []*level.Level, lvl *level.Level, holder *level.Level) bool {
+	for _, candidate := range levels {
+		if isRelatedLevel(lvl, candidate) && (candidate != holder) && isElevatorPanel(candidate, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func elevatorDestinations(lvl *level.Level, id level.ObjectID, entry level.ObjectMasterEntry) []ObjectReference {
+	if entry.Class != object.ClassFixture {
+		return nil
+	}
+	inst := interpreterFactoryFor(lvl)(entry.Triple(), lvl.ObjectClassData(id))
+	if !hasKey(inst, elevatorDestinationKeys[0]) {
+		return nil
+	}
+	var references []ObjectReference
+	for _, key := range elevatorDestinationKeys {
+		if value := int(inst.Get(key)); value > 0 {
+			references = append(references, ObjectReference{Key: key, ID: value})
+		}
+	}
+	return references
+}
+
+func isElevatorPanel(lvl *level.Level, id level.ObjectID) bool {
+	obj := lvl.Object(id)
+	if (obj == nil) || (obj.InUse == 0) || (obj.Class != object.ClassFixture) {
+		return false
+	}
+	inst := interpreterFactoryFor(lvl)(obj.Triple(), lvl.ObjectClassData(id))
+	return hasKey(inst, elevatorDestinationKeys[0])
+}
+
+func hasKey(inst *interpreters.Instance, key string) bool {
+	for _, candidate := range inst.Keys() {
+		if candidate == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package lvlobj_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvltest"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactObjectsUpdatesReferences(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	removedFirst := newObject(t, lvl, object.TripleFrom(8, 0, 0))
+	gun := newObject(t, lvl, object.TripleFrom(0, 0, 0))
+	removedSecond := newObject(t, lvl, object.TripleFrom(8, 0, 0))
+	container := newObject(t, lvl, object.TripleFrom(13, 1, 0))
+	data := lvl.ObjectClassData(container)
+	data[0] = byte(gun)
+	data[2] = byte(removedSecond)
+	lvl.DelObject(removedFirst)
+	lvl.DelObject(removedSecond)
+
+	result, err := lvlobj.CompactObjects(lvl, nil)
+	require.Nil(t, err, "no error expected")
+
+	newGun := result.Mapping[gun]
+	newContainer := result.Mapping[container]
+	require.Equal(t, map[level.ObjectID]level.ObjectID{gun: 1, container: 2}, result.Mapping)
+	assert.Equal(t, []lvlobj.DanglingReference{
+		{ObjectReference: lvlobj.ObjectReference{Key: "ObjectID2", ID: int(removedSecond)}, Source: newContainer, Reason: lvlobj.DanglingReasonUnused},
+	}, result.ClearedReferences)
+	assert.Equal(t, []level.ObjectID{newGun, 0, 0, 0}, lvlobj.ContainerContents(lvl, newContainer))
+	assert.Equal(t, 0, len(lvlobj.DanglingReferences(lvl)), "no dangling references expected")
+}
+
+func TestCompactObjectsReportsReleasedClassEntries(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	orphaned := newObject(t, lvl, object.TripleFrom(0, 0, 0))
+	lvl.Object(orphaned).ClassTableIndex = 0
+	lvl.DelObject(orphaned)
+
+	result, err := lvlobj.CompactObjects(lvl, nil)
+	require.Nil(t, err, "no error expected")
+
+	assert.Equal(t, 1, result.ReleasedClassEntries)
+}
+
+func TestCompactObjectsUpdatesIncomingReferencesOfMovedElevatorPanel(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	removed := newObject(t, lvl, object.TripleFrom(8, 0, 0))
+	panel := newObject(t, lvl, object.TripleFrom(9, 3, 4))
+	lvl.DelObject(removed)
+	other := lvltest.EmptyLevel(nil)
+	otherPanel := newObject(t, other, object.TripleFrom(9, 3, 4))
+	other.ObjectClassData(otherPanel)[8] = byte(panel)
+
+	incoming := lvlobj.IncomingReferences(lvl, []*level.Level{lvl, other})
+	result, err := lvlobj.CompactObjects(lvl, []*level.Level{lvl, other})
+	require.Nil(t, err, "no error expected")
+
+	expected := []lvlobj.IncomingReference{
+		{ObjectReference: lvlobj.ObjectReference{Key: "DestinationObjectIndex1", ID: int(panel)}, Level: other.ID(), Source: otherPanel},
+	}
+	require.Equal(t, expected, incoming)
+	assert.Equal(t, expected, result.UpdatedIncoming)
+	assert.Equal(t, level.ObjectID(1), result.Mapping[panel])
+	assert.Equal(t, byte(1), other.ObjectClassData(otherPanel)[8], "reference of other level should be updated")
+}
+
+func TestCompactObjectsRefusesAmbiguousIncomingReferences(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	removed := newObject(t, lvl, object.TripleFrom(8, 0, 0))
+	panel := newObject(t, lvl, object.TripleFrom(9, 3, 4))
+	lvl.DelObject(removed)
+	third := lvltest.EmptyLevel(nil)
+	thirdRemoved := newObject(t, third, object.TripleFrom(8, 0, 0))
+	thirdPanel := newObject(t, third, object.TripleFrom(9, 3, 4))
+	third.DelObject(thirdRemoved)
+	require.Equal(t, panel, thirdPanel, "panels of both levels should have the same identifier")
+	other := lvltest.EmptyLevel(nil)
+	otherPanel := newObject(t, other, object.TripleFrom(9, 3, 4))
+	other.ObjectClassData(otherPanel)[8] = byte(panel)
+
+	incoming := lvlobj.IncomingReferences(lvl, []*level.Level{lvl, other, third})
+	_, err := lvlobj.CompactObjects(lvl, []*level.Level{lvl, other, third})
+
+	require.Equal(t, 1, len(incoming), "one incoming reference expected")
+	assert.True(t, incoming[0].Ambiguous, "reference should be ambiguous")
+	assert.NotNil(t, err, "error expected")
+	assert.Equal(t, byte(1), lvl.Object(panel).InUse, "panel should not be moved")
+	assert.Equal(t, byte(panel), other.ObjectClassData(otherPanel)[8], "reference of other level should not be modified")
+}
+
+func TestCompactObjectsRefusesToMoveObjectsWithActiveSchedules(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	removed := newObject(t, lvl, object.TripleFrom(8, 0, 0))
+	kept := newObject(t, lvl, object.TripleFrom(8, 0, 0))
+	lvl.DelObject(removed)
+	err := lvl.SetSchedules(level.ScheduleEvents{{Timestamp: 10, Type: 2}})
+	require.Nil(t, err, "no error expected setting schedules")
+
+	_, err = lvlobj.CompactObjects(lvl, nil)
+
+	assert.NotNil(t, err, "error expected")
+	assert.Equal(t, byte(1), lvl.Object(kept).InUse, "object should not be moved")
+}
+
+func TestCompactObjectsAllowsActiveSchedulesIfNoObjectMoves(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	newObject(t, lvl, object.TripleFrom(8, 0, 0))
+	err := lvl.SetSchedules(level.ScheduleEvents{{Timestamp: 10, Type: 2}})
+	require.Nil(t, err, "no error expected setting schedules")
+
+	result, err := lvlobj.CompactObjects(lvl, nil)
+
+	require.Nil(t, err, "no error expected")
+	assert.Empty(t, result.Mapping)
+}
+
+func TestIncomingReferencesIgnoresNonPanelTargets(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	barrel := newObject(t, lvl, object.TripleFrom(8, 0, 0))
+	other := lvltest.EmptyLevel(nil)
+	otherPanel := newObject(t, other, object.TripleFrom(9, 3, 4))
+	other.ObjectClassData(otherPanel)[8] = byte(barrel)
+
+	assert.Empty(t, lvlobj.IncomingReferences(lvl, []*level.Level{lvl, other}))
+}
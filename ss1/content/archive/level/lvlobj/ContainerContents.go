@@ -0,0 +1,101 @@
+package lvlobj
+
+import (
+	"fmt"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+)
+
+// ContainerSlots is the number of objects a container can hold.
+const ContainerSlots = 4
+
+func containerSlotKey(slot int) string {
+	return fmt.Sprintf("ObjectID%d", slot+1)
+}
+
+// IsContainer returns true if the identified object can hold other objects.
+func IsContainer(lvl *level.Level, id level.ObjectID) bool {
+	obj := lvl.Object(id)
+	return (obj != nil) && (obj.InUse != 0) && (obj.Class == object.ClassContainer) &&
+		(len(lvl.ObjectClassData(id)) >= ContainerSlots*2)
+}
+
+// ContainerContents returns the objects stored in the slots of given container.
+// Empty slots are reported with an identifier of zero. Returns nil if the object is not a container.
+func ContainerContents(lvl *level.Level, id level.ObjectID) []level.ObjectID {
+	if !IsContainer(lvl, id) {
+		return nil
+	}
+	obj := lvl.Object(id)
+	inst := interpreterFactoryFor(lvl)(obj.Triple(), lvl.ObjectClassData(id))
+	contents := make([]level.ObjectID, ContainerSlots)
+	for slot := 0; slot < ContainerSlots; slot++ {
+		contents[slot] = level.ObjectID(int16(inst.Get(containerSlotKey(slot))))
+	}
+	return contents
+}
+
+// SetContainerContent stores the given object in the slot of a container.
+// An identifier of zero empties the slot.
+func SetContainerContent(lvl *level.Level, id level.ObjectID, slot int, content level.ObjectID) {
+	if !IsContainer(lvl, id) || (slot < 0) || (slot >= ContainerSlots) {
+		return
+	}
+	SetObjectReference(lvl, id, containerSlotKey(slot), content)
+}
+
+// ContainerOf returns the container and slot that hold the given object.
+// Returns zero and -1 if the object is not within any container.
+func ContainerOf(lvl *level.Level, content level.ObjectID) (container level.ObjectID, slot int) {
+	slot = -1
+	lvl.ForEachObject(func(id level.ObjectID, entry level.ObjectMasterEntry) {
+		if (container != 0) || (entry.Class != object.ClassContainer) {
+			return
+		}
+		for index, candidate := range ContainerContents(lvl, id) {
+			if candidate == content {
+				container = id
+				slot = index
+				return
+			}
+		}
+	})
+	return
+}
+
+// IsWithin returns true if the given object is the container itself, or is stored in it, directly or nested.
+// Putting a container into an object that is within it would create a cycle.
+func IsWithin(lvl *level.Level, id level.ObjectID, container level.ObjectID) bool {
+	visited := make(map[level.ObjectID]bool)
+	for (id != 0) && !visited[id] {
+		if id == container {
+			return true
+		}
+		visited[id] = true
+		id, _ = ContainerOf(lvl, id)
+	}
+	return false
+}
+
+// NewContainedObject creates a new object and stores it in the given slot of a container.
+// The new object has no place in the world.
+func NewContainedObject(lvl *level.Level, container level.ObjectID, slot int, triple object.Triple) (level.ObjectID, error) {
+	contents := ContainerContents(lvl, container)
+	if contents == nil {
+		return 0, fmt.Errorf("object %d is not a container", container)
+	}
+	if (slot < 0) || (slot >= ContainerSlots) || (contents[slot] != 0) {
+		return 0, fmt.Errorf("slot %d of container %d is not available", slot, container)
+	}
+	id, err := lvl.NewObject(triple.Class)
+	if err != nil {
+		return 0, err
+	}
+	obj := lvl.Object(id)
+	obj.Subclass = triple.Subclass
+	obj.Type = triple.Type
+	lvl.ClearObjectLocation(id)
+	SetContainerContent(lvl, container, slot, id)
+	return id, nil
+}
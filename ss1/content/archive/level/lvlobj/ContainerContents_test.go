@@ -0,0 +1,74 @@
+package lvlobj_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvltest"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewContainedObject(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	container := newObject(t, lvl, object.TripleFrom(13, 1, 0))
+
+	id, err := lvlobj.NewContainedObject(lvl, container, 2, object.TripleFrom(8, 1, 3))
+	require.Nil(t, err, "no error expected")
+
+	assert.Equal(t, []level.ObjectID{0, 0, id, 0}, lvlobj.ContainerContents(lvl, container))
+	assert.False(t, lvl.IsObjectPlaced(id), "contained object should not be placed")
+	assert.Equal(t, object.TripleFrom(8, 1, 3), lvl.Object(id).Triple())
+}
+
+func TestNewContainedObjectFailsForOccupiedSlot(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	container := newObject(t, lvl, object.TripleFrom(13, 1, 0))
+	_, err := lvlobj.NewContainedObject(lvl, container, 0, object.TripleFrom(8, 1, 3))
+	require.Nil(t, err, "no error expected")
+
+	_, err = lvlobj.NewContainedObject(lvl, container, 0, object.TripleFrom(8, 1, 3))
+
+	assert.NotNil(t, err, "error expected")
+}
+
+func TestNewContainedObjectFailsForNonContainer(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	door := newObject(t, lvl, object.TripleFrom(10, 0, 0))
+
+	_, err := lvlobj.NewContainedObject(lvl, door, 0, object.TripleFrom(8, 1, 3))
+
+	assert.NotNil(t, err, "error expected")
+}
+
+func TestContainerOf(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	newObject(t, lvl, object.TripleFrom(13, 1, 0))
+	container := newObject(t, lvl, object.TripleFrom(13, 0, 0))
+	id, err := lvlobj.NewContainedObject(lvl, container, 3, object.TripleFrom(8, 1, 3))
+	require.Nil(t, err, "no error expected")
+
+	holder, slot := lvlobj.ContainerOf(lvl, id)
+
+	assert.Equal(t, container, holder)
+	assert.Equal(t, 3, slot)
+}
+
+func TestIsWithin(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	outer := newObject(t, lvl, object.TripleFrom(13, 1, 0))
+	inner := newObject(t, lvl, object.TripleFrom(13, 1, 0))
+	other := newObject(t, lvl, object.TripleFrom(13, 1, 0))
+	lvlobj.SetContainerContent(lvl, outer, 0, inner)
+	item, err := lvlobj.NewContainedObject(lvl, inner, 1, object.TripleFrom(8, 1, 3))
+	require.Nil(t, err, "no error expected")
+
+	assert.True(t, lvlobj.IsWithin(lvl, outer, outer), "container should be within itself")
+	assert.True(t, lvlobj.IsWithin(lvl, inner, outer), "direct content should be within")
+	assert.True(t, lvlobj.IsWithin(lvl, item, outer), "nested content should be within")
+	assert.False(t, lvlobj.IsWithin(lvl, outer, inner), "holder should not be within its content")
+	assert.False(t, lvlobj.IsWithin(lvl, item, other), "unrelated container should not hold the item")
+}
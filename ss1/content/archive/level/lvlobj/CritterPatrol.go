@@ -0,0 +1,48 @@
+package lvlobj
+
+import (
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+)
+
+const (
+	critterPatrolTileXKey = "PatrolTileX"
+	critterPatrolTileYKey = "PatrolTileY"
+)
+
+// IsCritter returns true if the identified object is a critter in use.
+func IsCritter(lvl *level.Level, id level.ObjectID) bool {
+	obj := lvl.Object(id)
+	return (obj != nil) && (obj.InUse != 0) && (obj.Class == object.ClassCritter)
+}
+
+// CritterPatrolTile returns the tile the given critter patrols to.
+// The class data of a critter holds only this one waypoint. The patrol route of a critter
+// is therefore the way between its position and the patrol tile.
+// As the border of a map is always solid, a patrol tile at (0, 0) is considered to be not set.
+// Returns false if the object is not a critter, or the patrol tile is not set.
+//
+// The target tile of a critter is not covered, as it is the runtime state of the AI,
+// describing where the critter is currently heading for.
+func CritterPatrolTile(lvl *level.Level, id level.ObjectID) (x, y int, set bool) {
+	if !IsCritter(lvl, id) {
+		return 0, 0, false
+	}
+	inst := interpreterFactoryFor(lvl)(lvl.Object(id).Triple(), lvl.ObjectClassData(id))
+	x, y = int(inst.Get(critterPatrolTileXKey)), int(inst.Get(critterPatrolTileYKey))
+	return x, y, (x != 0) || (y != 0)
+}
+
+// SetCritterPatrolTile sets the tile the given critter patrols to.
+// Setting tile (0, 0) clears the patrol tile.
+// Returns false if the object is not a critter, or the tile is outside of the level.
+func SetCritterPatrolTile(lvl *level.Level, id level.ObjectID, x, y int) bool {
+	width, height, _ := lvl.Size()
+	if !IsCritter(lvl, id) || (x < 0) || (x >= width) || (y < 0) || (y >= height) {
+		return false
+	}
+	inst := interpreterFactoryFor(lvl)(lvl.Object(id).Triple(), lvl.ObjectClassData(id))
+	inst.Set(critterPatrolTileXKey, uint32(x))
+	inst.Set(critterPatrolTileYKey, uint32(y))
+	return true
+}
@@ -0,0 +1,67 @@
+package lvlobj_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvltest"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCritterPatrolTile(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	critter := newObject(t, lvl, object.TripleFrom(14, 0, 0))
+	data := lvl.ObjectClassData(critter)
+	data[0x18] = 10
+	data[0x19] = 20
+
+	x, y, set := lvlobj.CritterPatrolTile(lvl, critter)
+	assert.True(t, set, "patrol tile should be set")
+	assert.Equal(t, 10, x)
+	assert.Equal(t, 20, y)
+}
+
+func TestCritterPatrolTileIsNotSetByDefault(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	critter := newObject(t, lvl, object.TripleFrom(14, 0, 0))
+
+	_, _, set := lvlobj.CritterPatrolTile(lvl, critter)
+	assert.False(t, set, "patrol tile should not be set")
+}
+
+func TestSetCritterPatrolTile(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	critter := newObject(t, lvl, object.TripleFrom(14, 0, 0))
+	data := lvl.ObjectClassData(critter)
+	data[0x1A] = 5
+	data[0x1B] = 6
+
+	require.True(t, lvlobj.SetCritterPatrolTile(lvl, critter, 30, 40))
+
+	assert.Equal(t, []byte{30, 40}, data[0x18:0x1A])
+	assert.Equal(t, []byte{5, 6}, data[0x1A:0x1C], "target tile should not be modified")
+}
+
+func TestSetCritterPatrolTileIgnoresOtherClasses(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	gun := newObject(t, lvl, object.TripleFrom(0, 0, 0))
+
+	assert.False(t, lvlobj.SetCritterPatrolTile(lvl, gun, 1, 2))
+	_, _, set := lvlobj.CritterPatrolTile(lvl, gun)
+	assert.False(t, set)
+}
+
+func TestSetCritterPatrolTileRefusesTilesOutsideOfLevel(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	critter := newObject(t, lvl, object.TripleFrom(14, 0, 0))
+	width, height, _ := lvl.Size()
+
+	assert.False(t, lvlobj.SetCritterPatrolTile(lvl, critter, width, 1), "x beyond level expected to fail")
+	assert.False(t, lvlobj.SetCritterPatrolTile(lvl, critter, 1, height), "y beyond level expected to fail")
+	_, _, set := lvlobj.CritterPatrolTile(lvl, critter)
+	assert.False(t, set, "patrol tile should not be set")
+	assert.True(t, lvlobj.SetCritterPatrolTile(lvl, critter, width-1, height-1), "last tile expected to work")
+}
@@ -4,6 +4,11 @@ import (
 	"github.com/inkyblackness/hacked/ss1/content/interpreters"
 )
 
+// baseCritter describes the class data of critters.
+// The orientation of a critter is the Z rotation of its object entry, PendingZRotation is the heading it turns to.
+// The patrol tile is the only stored waypoint: A patrolling critter moves between its position and this tile.
+// The target and path fields are runtime state of the AI, describing where the critter is currently heading for.
+// Coordinates are in tiles and limited by the size of the level.
 var baseCritter = interpreters.New().
 	With("PendingZRotation", 0, 4).
 	With("ForwardVelocityFraction", 4, 2).
@@ -13,7 +18,7 @@ var baseCritter = interpreters.New().
 	With("Unknown000E", 0x0E, 2).As(interpreters.SpecialValue("Unknown")).
 	With("Unknown0010", 0x10, 2).As(interpreters.SpecialValue("Unknown")).
 	With("RoamingState", 0x14, 1).
-	With("PrimaryState", 0x15, 1).As(interpreters.EnumValue(map[uint32]string{
+	With("Mood", 0x15, 1).As(interpreters.EnumValue(map[uint32]string{
 	0: "docile",
 	1: "cautious",
 	2: "hostile",
@@ -24,11 +29,14 @@ var baseCritter = interpreters.New().
 	7: "confused"})).
 	With("SecondaryState", 0x16, 1).
 	With("TertiaryState", 0x17, 1).
-	With("AICoordinates0018", 0x18, 2).As(interpreters.SpecialValue("Unknown")).
-	With("AICoordinates001A", 0x1A, 2).As(interpreters.SpecialValue("Unknown")).
-	With("AICoordinates001C", 0x1C, 2).As(interpreters.SpecialValue("Unknown")).
-	With("Always255", 0x1E, 1).As(interpreters.RangedValue(0, 255)).
-	With("AICoordinates001F", 0x1F, 1).As(interpreters.SpecialValue("Unknown")).
+	With("PatrolTileX", 0x18, 1).As(interpreters.SpecialValue("TileX")).
+	With("PatrolTileY", 0x19, 1).As(interpreters.SpecialValue("TileY")).
+	With("TargetTileX", 0x1A, 1).As(interpreters.SpecialValue("TileX")).
+	With("TargetTileY", 0x1B, 1).As(interpreters.SpecialValue("TileY")).
+	With("PathTileX", 0x1C, 1).As(interpreters.SpecialValue("TileX")).
+	With("PathTileY", 0x1D, 1).As(interpreters.SpecialValue("TileY")).
+	With("PathID", 0x1E, 1).As(interpreters.FormattedRangedValue(0, 255, pathIDFormatter)).
+	With("PathAttempts", 0x1F, 1).As(interpreters.RangedValue(0, 255)).
 	With("LootObjectID1", 0x20, 2).As(interpreters.ObjectID()).
 	With("LootObjectID2", 0x22, 2).As(interpreters.ObjectID()).
 	With("Unknown0026", 0x26, 2).As(interpreters.SpecialValue("Unknown"))
@@ -38,3 +46,10 @@ func initCritters() interpreterRetriever {
 
 	return class
 }
+
+func pathIDFormatter(value int) string {
+	if value == 0xFF {
+		return "no path"
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package lvlobj
+
+import (
+	"strings"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+)
+
+// DanglingReason describes why a reference is considered to be dangling.
+type DanglingReason int
+
+// DanglingReason constants are listed below.
+const (
+	// DanglingReasonUnused is for references to objects that are not in use.
+	DanglingReasonUnused DanglingReason = 0
+	// DanglingReasonWrongClass is for references to objects of a class that is not expected for the field.
+	DanglingReasonWrongClass DanglingReason = 1
+)
+
+// String returns a textual representation.
+func (reason DanglingReason) String() string {
+	switch reason {
+	case DanglingReasonUnused:
+		return "Unused"
+	case DanglingReasonWrongClass:
+		return "Wrong Class"
+	default:
+		return "Unknown"
+	}
+}
+
+// DanglingReference describes a reference of an object that does not point to a proper object.
+type DanglingReference struct {
+	ObjectReference
+
+	// Source identifies the object that holds the reference.
+	Source level.ObjectID
+	// Reason describes what is wrong with the reference.
+	Reason DanglingReason
+}
+
+// inventoryClasses are those that can be contained in containers.
+var inventoryClasses = []object.Class{
+	object.ClassGun, object.ClassAmmo, object.ClassGrenade, object.ClassDrug,
+	object.ClassHardware, object.ClassSoftware, object.ClassSmallStuff,
+}
+
+// expectedReferenceClasses lists the well-known references, per class of the source object and field key.
+var expectedReferenceClasses = map[object.Class]map[string][]object.Class{
+	object.ClassContainer: {
+		"ObjectID1": inventoryClasses, "ObjectID2": inventoryClasses,
+		"ObjectID3": inventoryClasses, "ObjectID4": inventoryClasses,
+	},
+	object.ClassSmallStuff: { // briefcases and corpses
+		"ObjectID1": inventoryClasses, "ObjectID2": inventoryClasses,
+		"ObjectID3": inventoryClasses, "ObjectID4": inventoryClasses,
+	},
+	object.ClassBigStuff: { // cabinet furniture
+		"Object1ID": inventoryClasses, "Object2ID": inventoryClasses,
+	},
+	object.ClassCritter: {
+		"LootObjectID1": inventoryClasses, "LootObjectID2": inventoryClasses,
+	},
+	object.ClassDoor: {
+		"OtherObjectID": {object.ClassDoor},
+	},
+	object.ClassTrap: { // AI hints are chained
+		"NextObjectID": {object.ClassTrap},
+	},
+}
+
+// ExpectedClasses returns the classes an object reference of given source object may refer to.
+// Only the well-known references are restricted: contained objects, loot, linked doors and chained AI hints.
+// An empty list means that any class is accepted, as is the case for triggers and the targets of actions.
+func ExpectedClasses(source object.Triple, key string) []object.Class {
+	return expectedReferenceClasses[source.Class][key]
+}
+
+// DanglingReferences scans all objects of the level and returns those references that
+// point to objects not in use, or to objects of an unexpected class.
+func DanglingReferences(lvl *level.Level) []DanglingReference {
+	var dangling []DanglingReference
+	interpreterFactory := interpreterFactoryFor(lvl)
+	lvl.ForEachObject(func(id level.ObjectID, entry level.ObjectMasterEntry) {
+		inst := interpreterFactory(entry.Triple(), lvl.ObjectClassData(id))
+		for _, ref := range ObjectReferences(inst) {
+			target := lvl.Object(level.ObjectID(ref.ID))
+			if (target == nil) || (target.InUse == 0) {
+				dangling = append(dangling, DanglingReference{ObjectReference: ref, Source: id, Reason: DanglingReasonUnused})
+			} else if !isClassExpected(ExpectedClasses(entry.Triple(), ref.Key), target.Class) {
+				dangling = append(dangling, DanglingReference{ObjectReference: ref, Source: id, Reason: DanglingReasonWrongClass})
+			}
+		}
+	})
+	return dangling
+}
+
+// SetObjectReference changes the identified reference of the given source object to the given target.
+// A target of zero clears the reference.
+func SetObjectReference(lvl *level.Level, source level.ObjectID, key string, target level.ObjectID) {
+	obj := lvl.Object(source)
+	if (obj == nil) || (obj.InUse == 0) {
+		return
+	}
+	inst := interpreterFactoryFor(lvl)(obj.Triple(), lvl.ObjectClassData(source))
+	subKeys := strings.Split(key, ".")
+	valueIndex := len(subKeys) - 1
+	for subIndex := 0; subIndex < valueIndex; subIndex++ {
+		inst = inst.Refined(subKeys[subIndex])
+	}
+	inst.Set(subKeys[valueIndex], uint32(target))
+}
+
+func interpreterFactoryFor(lvl *level.Level) InterpreterFactory {
+	if lvl.IsCyberspace() {
+		return ForCyberspace
+	}
+	return ForRealWorld
+}
+
+func isClassExpected(expected []object.Class, class object.Class) bool {
+	if len(expected) == 0 {
+		return true
+	}
+	for _, candidate := range expected {
+		if candidate == class {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package lvlobj_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvltest"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newObject(t *testing.T, lvl *level.Level, triple object.Triple) level.ObjectID {
+	t.Helper()
+	id, err := lvl.NewObject(triple.Class)
+	require.Nil(t, err, "no error expected creating object")
+	obj := lvl.Object(id)
+	obj.Subclass = triple.Subclass
+	obj.Type = triple.Type
+	return id
+}
+
+func TestDanglingReferences(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	gun := newObject(t, lvl, object.TripleFrom(0, 0, 0))
+	door := newObject(t, lvl, object.TripleFrom(10, 0, 0))
+	removed := newObject(t, lvl, object.TripleFrom(8, 0, 0))
+	container := newObject(t, lvl, object.TripleFrom(13, 1, 0))
+	data := lvl.ObjectClassData(container)
+	data[0] = byte(gun)
+	data[2] = byte(door)
+	data[4] = byte(removed)
+	lvl.DelObject(removed)
+
+	dangling := lvlobj.DanglingReferences(lvl)
+
+	assert.Equal(t, []lvlobj.DanglingReference{
+		{ObjectReference: lvlobj.ObjectReference{Key: "ObjectID2", ID: int(door)}, Source: container, Reason: lvlobj.DanglingReasonWrongClass},
+		{ObjectReference: lvlobj.ObjectReference{Key: "ObjectID3", ID: int(removed)}, Source: container, Reason: lvlobj.DanglingReasonUnused},
+	}, dangling)
+}
+
+func TestDanglingReferencesOfWellKnownClasses(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	gun := newObject(t, lvl, object.TripleFrom(0, 0, 0))
+	door := newObject(t, lvl, object.TripleFrom(10, 0, 0))
+	otherDoor := newObject(t, lvl, object.TripleFrom(10, 0, 1))
+	critter := newObject(t, lvl, object.TripleFrom(14, 0, 0))
+	lvl.ObjectClassData(door)[6] = byte(gun)
+	lvl.ObjectClassData(otherDoor)[6] = byte(door)
+	lvl.ObjectClassData(critter)[0x20] = byte(gun)
+	lvl.ObjectClassData(critter)[0x22] = byte(door)
+
+	dangling := lvlobj.DanglingReferences(lvl)
+
+	assert.ElementsMatch(t, []lvlobj.DanglingReference{
+		{ObjectReference: lvlobj.ObjectReference{Key: "OtherObjectID", ID: int(gun)}, Source: door, Reason: lvlobj.DanglingReasonWrongClass},
+		{ObjectReference: lvlobj.ObjectReference{Key: "LootObjectID2", ID: int(door)}, Source: critter, Reason: lvlobj.DanglingReasonWrongClass},
+	}, dangling)
+}
+
+func TestExpectedClassesAcceptsAnyClassForTriggers(t *testing.T) {
+	assert.Empty(t, lvlobj.ExpectedClasses(object.TripleFrom(12, 0, 0), "Action.TriggerOtherObjects.Object2ID"))
+	assert.Equal(t, []object.Class{object.ClassDoor}, lvlobj.ExpectedClasses(object.TripleFrom(10, 0, 0), "OtherObjectID"))
+}
+
+func TestSetObjectReferenceInRefinement(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	target := newObject(t, lvl, object.TripleFrom(10, 0, 0))
+	trap := newObject(t, lvl, object.TripleFrom(12, 0, 0))
+	data := lvl.ObjectClassData(trap)
+	data[0] = 6 // trigger other objects
+
+	lvlobj.SetObjectReference(lvl, trap, "Action.TriggerOtherObjects.Object2ID", target)
+
+	assert.Equal(t, []lvlobj.ObjectReference{{Key: "Action.TriggerOtherObjects.Object2ID", ID: int(target)}},
+		lvlobj.ObjectReferences(lvlobj.ForRealWorld(lvl.Object(trap).Triple(), lvl.ObjectClassData(trap))))
+}
@@ -0,0 +1,44 @@
+package lvlobj
+
+import (
+	"sort"
+
+	"github.com/inkyblackness/hacked/ss1/content/interpreters"
+)
+
+// LevelDestinations returns the levels an object can bring the player to, based on its interpreted class data.
+// Considered are transport actions to other levels, cyberspace terminals, and elevator panels.
+// The returned list is sorted and contains each level only once.
+func LevelDestinations(inst *interpreters.Instance) []int {
+	found := make(map[int]bool)
+	collectLevelDestinations(inst, found)
+	destinations := make([]int, 0, len(found))
+	for level := range found {
+		destinations = append(destinations, level)
+	}
+	sort.Ints(destinations)
+	return destinations
+}
+
+func collectLevelDestinations(inst *interpreters.Instance, found map[int]bool) {
+	for _, key := range inst.Keys() {
+		switch key {
+		case "CrossLevelTransportDestination":
+			if inst.Get("CrossLevelTransportFlag") == 0 {
+				found[int(inst.Get(key))] = true
+			}
+		case "TargetLevel":
+			found[int(inst.Get(key))] = true
+		case "AccessibleBitmask":
+			mask := inst.Get(key)
+			for level := 0; level < 16; level++ {
+				if (mask & (1 << uint(level))) != 0 {
+					found[level] = true
+				}
+			}
+		}
+	}
+	for _, key := range inst.ActiveRefinements() {
+		collectLevelDestinations(inst.Refined(key), found)
+	}
+}
@@ -0,0 +1,41 @@
+package lvlobj_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelDestinationsOfElevatorPanel(t *testing.T) {
+	data := make([]byte, 24)
+	data[18] = 0x06
+	data[19] = 0x80
+
+	destinations := lvlobj.LevelDestinations(lvlobj.ForRealWorld(object.TripleFrom(9, 3, 4), data))
+
+	assert.Equal(t, []int{1, 2, 15}, destinations)
+}
+
+func TestLevelDestinationsOfTransportAction(t *testing.T) {
+	data := make([]byte, 22)
+	data[0] = 1 // transport hacker
+	data[6+12] = 4
+
+	destinations := lvlobj.LevelDestinations(lvlobj.ForRealWorld(object.TripleFrom(12, 0, 0), data))
+
+	assert.Equal(t, []int{4}, destinations)
+}
+
+func TestLevelDestinationsOfTransportActionWithinLevel(t *testing.T) {
+	data := make([]byte, 22)
+	data[0] = 1
+	data[6+12] = 4
+	data[6+13] = 0x10
+
+	destinations := lvlobj.LevelDestinations(lvlobj.ForRealWorld(object.TripleFrom(12, 0, 0), data))
+
+	assert.Equal(t, []int{}, destinations)
+}
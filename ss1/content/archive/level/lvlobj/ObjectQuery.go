@@ -0,0 +1,130 @@
+package lvlobj
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/interpreters"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+)
+
+// ObjectQuery describes criteria to find objects of a level.
+// Objects must match all criteria to be found.
+type ObjectQuery struct {
+	// Class limits the search to objects of one class. A negative value matches all classes.
+	Class int
+	// Triple limits the search to objects of one type. Only considered if Class is not negative.
+	Triple object.Triple
+	// AnyType is set if all types of the class match.
+	AnyType bool
+
+	// MinTileX, MinTileY, MaxTileX and MaxTileY limit the search to objects within a range of tiles, inclusive.
+	MinTileX int
+	MinTileY int
+	MaxTileX int
+	MaxTileY int
+
+	// MinHitpoints and MaxHitpoints limit the search to objects within a range of hitpoints, inclusive.
+	MinHitpoints int
+	MaxHitpoints int
+
+	// PropertyKey limits the search to objects that have a class property of this key. Empty for any.
+	PropertyKey string
+	// PropertyValue is the value the property must have. Only considered if PropertyKey is not empty.
+	PropertyValue uint32
+}
+
+// DefaultObjectQuery returns a query that matches all objects.
+func DefaultObjectQuery() ObjectQuery {
+	return ObjectQuery{
+		Class:        -1,
+		AnyType:      true,
+		MaxTileX:     63,
+		MaxTileY:     63,
+		MinHitpoints: -0x8000,
+		MaxHitpoints: 0x7FFF,
+	}
+}
+
+// Matches returns true if the given object fulfills all criteria of the query.
+func (query ObjectQuery) Matches(lvl *level.Level, id level.ObjectID, entry level.ObjectMasterEntry) bool {
+	if query.Class >= 0 {
+		if int(entry.Class) != query.Class {
+			return false
+		}
+		if !query.AnyType && (entry.Triple() != query.Triple) {
+			return false
+		}
+	}
+	tileX, tileY := int(entry.X.Tile()), int(entry.Y.Tile())
+	if (tileX < query.MinTileX) || (tileX > query.MaxTileX) || (tileY < query.MinTileY) || (tileY > query.MaxTileY) {
+		return false
+	}
+	if (int(entry.Hitpoints) < query.MinHitpoints) || (int(entry.Hitpoints) > query.MaxHitpoints) {
+		return false
+	}
+	if len(query.PropertyKey) > 0 {
+		value, available := PropertyValue(interpreterFactoryFor(lvl)(entry.Triple(), lvl.ObjectClassData(id)), query.PropertyKey)
+		if !available || (value != query.PropertyValue) {
+			return false
+		}
+	}
+	return true
+}
+
+// FindObjects returns the identifier of all objects of the level that match the query.
+func FindObjects(lvl *level.Level, query ObjectQuery) []level.ObjectID {
+	var found []level.ObjectID
+	lvl.ForEachObject(func(id level.ObjectID, entry level.ObjectMasterEntry) {
+		if query.Matches(lvl, id, entry) {
+			found = append(found, id)
+		}
+	})
+	sort.Slice(found, func(a, b int) bool { return found[a] < found[b] })
+	return found
+}
+
+// PropertyKeys returns the keys of all fields of given instance, including those of active refinements.
+// Keys of refinements are prefixed with the refinement key and a dot.
+func PropertyKeys(inst *interpreters.Instance) []string {
+	var keys []string
+	collectPropertyKeys(inst, "", &keys)
+	return keys
+}
+
+func collectPropertyKeys(inst *interpreters.Instance, prefix string, keys *[]string) {
+	for _, key := range inst.Keys() {
+		*keys = append(*keys, prefix+key)
+	}
+	for _, key := range inst.ActiveRefinements() {
+		collectPropertyKeys(inst.Refined(key), prefix+key+".", keys)
+	}
+}
+
+// PropertyValue returns the value of the field identified by the given key.
+// The key may address fields of refinements, separated by dots.
+// Returns false if the field is not available, for example because the refinement is not active.
+func PropertyValue(inst *interpreters.Instance, key string) (uint32, bool) {
+	subKeys := strings.Split(key, ".")
+	valueIndex := len(subKeys) - 1
+	for subIndex := 0; subIndex < valueIndex; subIndex++ {
+		if !containsKey(inst.ActiveRefinements(), subKeys[subIndex]) {
+			return 0, false
+		}
+		inst = inst.Refined(subKeys[subIndex])
+	}
+	if !containsKey(inst.Keys(), subKeys[valueIndex]) {
+		return 0, false
+	}
+	return inst.Get(subKeys[valueIndex]), true
+}
+
+func containsKey(keys []string, key string) bool {
+	for _, candidate := range keys {
+		if candidate == key {
+			return true
+		}
+	}
+	return false
+}
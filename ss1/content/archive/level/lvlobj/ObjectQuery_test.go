@@ -0,0 +1,82 @@
+package lvlobj_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvltest"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func placedObject(t *testing.T, lvl *level.Level, triple object.Triple, x, y int) level.ObjectID {
+	t.Helper()
+	id := newObject(t, lvl, triple)
+	obj := lvl.Object(id)
+	obj.X = level.CoordinateAt(byte(x), 0x80)
+	obj.Y = level.CoordinateAt(byte(y), 0x80)
+	lvl.UpdateObjectLocation(id)
+	return id
+}
+
+func TestFindObjectsByDefaultFindsAll(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	first := placedObject(t, lvl, object.TripleFrom(0, 0, 0), 1, 1)
+	second := placedObject(t, lvl, object.TripleFrom(10, 0, 0), 2, 2)
+
+	assert.Equal(t, []level.ObjectID{first, second}, lvlobj.FindObjects(lvl, lvlobj.DefaultObjectQuery()))
+}
+
+func TestFindObjectsByClassAndType(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	placedObject(t, lvl, object.TripleFrom(10, 0, 0), 1, 1)
+	door := placedObject(t, lvl, object.TripleFrom(10, 0, 1), 1, 1)
+	placedObject(t, lvl, object.TripleFrom(8, 0, 1), 1, 1)
+
+	query := lvlobj.DefaultObjectQuery()
+	query.Class = int(object.ClassDoor)
+	query.AnyType = false
+	query.Triple = object.TripleFrom(10, 0, 1)
+
+	assert.Equal(t, []level.ObjectID{door}, lvlobj.FindObjects(lvl, query))
+}
+
+func TestFindObjectsByTileRangeAndHitpoints(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	placedObject(t, lvl, object.TripleFrom(8, 0, 0), 1, 1)
+	inside := placedObject(t, lvl, object.TripleFrom(8, 0, 0), 5, 6)
+	weak := placedObject(t, lvl, object.TripleFrom(8, 0, 0), 6, 5)
+	lvl.Object(inside).Hitpoints = 20
+	lvl.Object(weak).Hitpoints = 2
+
+	query := lvlobj.DefaultObjectQuery()
+	query.MinTileX, query.MinTileY, query.MaxTileX, query.MaxTileY = 4, 4, 8, 8
+	query.MinHitpoints = 10
+
+	assert.Equal(t, []level.ObjectID{inside}, lvlobj.FindObjects(lvl, query))
+}
+
+func TestFindObjectsByPropertyValue(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	transport := placedObject(t, lvl, object.TripleFrom(12, 0, 0), 1, 1)
+	lvl.ObjectClassData(transport)[0] = 1
+	other := placedObject(t, lvl, object.TripleFrom(12, 0, 0), 1, 1)
+	lvl.ObjectClassData(other)[0] = 6
+
+	query := lvlobj.DefaultObjectQuery()
+	query.PropertyKey = "Action.Type"
+	query.PropertyValue = 1
+
+	assert.Equal(t, []level.ObjectID{transport}, lvlobj.FindObjects(lvl, query))
+}
+
+func TestPropertyValueOfInactiveRefinementIsNotAvailable(t *testing.T) {
+	data := make([]byte, 28)
+	data[0] = 1
+
+	_, available := lvlobj.PropertyValue(lvlobj.ForRealWorld(object.TripleFrom(12, 0, 0), data), "Action.TriggerOtherObjects.Object1ID")
+
+	assert.False(t, available)
+}
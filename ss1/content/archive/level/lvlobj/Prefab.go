@@ -0,0 +1,155 @@
+package lvlobj
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+)
+
+// PrefabObject is one object of a prefab.
+type PrefabObject struct {
+	level.RegionObject
+
+	// Placed is set if the object has a place in the world. Objects within containers are not placed.
+	Placed bool
+	// HeightAboveFloor is the height of a placed object above the floor at its position, in tiles.
+	HeightAboveFloor float32
+}
+
+// Prefab is a group of objects that can be instantiated repeatedly.
+// The positions of the objects are stored relative to the lowest tile coordinates of the group.
+type Prefab struct {
+	Name        string
+	Cyberspace  bool
+	SourceLevel int
+	Objects     []PrefabObject
+}
+
+// NewPrefab creates a prefab from the identified objects of the level.
+// Identifiers of objects that are not in use are ignored.
+// The indices into the tables of the level are not stored, as they are meaningless elsewhere.
+// The identifiers are kept to remap the references among the objects of the prefab.
+func NewPrefab(lvl *level.Level, name string, ids []level.ObjectID) Prefab {
+	prefab := Prefab{
+		Name:        name,
+		Cyberspace:  lvl.IsCyberspace(),
+		SourceLevel: lvl.ID(),
+	}
+	_, _, heightShift := lvl.Size()
+	minX, minY := 64, 64
+	for _, id := range ids {
+		obj := lvl.Object(id)
+		if (obj == nil) || (obj.InUse == 0) {
+			continue
+		}
+		placed := lvl.IsObjectPlaced(id)
+		var heightAboveFloor float32
+		if placed {
+			height, _ := heightShift.ValueFromObjectHeight(obj.Z)
+			heightAboveFloor = height - floorHeightAt(lvl, obj.X, obj.Y)
+			if int(obj.X.Tile()) < minX {
+				minX = int(obj.X.Tile())
+			}
+			if int(obj.Y.Tile()) < minY {
+				minY = int(obj.Y.Tile())
+			}
+		}
+		entry := *obj
+		entry.ClassTableIndex = 0
+		entry.CrossReferenceTableIndex = 0
+		entry.Next = 0
+		entry.Prev = 0
+		entry.Z = 0
+		prefab.Objects = append(prefab.Objects, PrefabObject{
+			RegionObject: level.RegionObject{
+				ID:        id,
+				Entry:     entry,
+				ClassData: append([]byte{}, lvl.ObjectClassData(id)...),
+			},
+			Placed:           placed,
+			HeightAboveFloor: heightAboveFloor,
+		})
+	}
+	for index := range prefab.Objects {
+		entry := &prefab.Objects[index].Entry
+		if prefab.Objects[index].Placed {
+			entry.X = level.CoordinateAt(byte(int(entry.X.Tile())-minX), entry.X.Fine())
+			entry.Y = level.CoordinateAt(byte(int(entry.Y.Tile())-minY), entry.Y.Fine())
+		} else {
+			entry.X = level.CoordinateAt(0, entry.X.Fine())
+			entry.Y = level.CoordinateAt(0, entry.Y.Fine())
+		}
+	}
+	return prefab
+}
+
+// Instantiate creates new objects of the prefab in the level, with the lowest tile of the group at given position.
+// Placed objects keep their height above the floor of the tile they are placed on.
+// References among the objects of the prefab are remapped to the new objects.
+// References to other objects are kept if the prefab is instantiated in the level it was created from,
+// and cleared otherwise.
+// The identifiers of the new objects are returned, in the order of the prefab.
+// If the prefab does not fit, an error is returned and the level is not modified.
+func (prefab Prefab) Instantiate(lvl *level.Level, toX, toY int) ([]level.ObjectID, error) {
+	region := level.Region{Cyberspace: prefab.Cyberspace}
+	for _, obj := range prefab.Objects {
+		if lvl.Tile(toX+int(obj.Entry.X.Tile()), toY+int(obj.Entry.Y.Tile())) == nil {
+			return nil, errors.New("prefab does not fit on the map at this position")
+		}
+		region.Objects = append(region.Objects, obj.RegionObject)
+	}
+	ids, err := lvl.PasteRegion(region, toX, toY)
+	if err != nil {
+		return nil, err
+	}
+	remapReferences(lvl, region.Objects, ids, prefab.SourceLevel != lvl.ID())
+	_, _, heightShift := lvl.Size()
+	for index, obj := range prefab.Objects {
+		if !obj.Placed {
+			lvl.ClearObjectLocation(ids[index])
+			continue
+		}
+		entry := lvl.Object(ids[index])
+		entry.Z = heightShift.ValueToObjectHeight(floorHeightAt(lvl, entry.X, entry.Y) + obj.HeightAboveFloor)
+	}
+	return ids, nil
+}
+
+// floorHeightAt returns the height of the floor at given position, in tiles.
+// For positions in solid areas, the base floor height of the tile is returned.
+func floorHeightAt(lvl *level.Level, x, y level.Coordinate) float32 {
+	height, open := lvl.FloorHeightAt(float32(x)/256, float32(y)/256)
+	if open {
+		return height
+	}
+	tile := lvl.Tile(int(x.Tile()), int(y.Tile()))
+	if tile == nil {
+		return 0
+	}
+	_, _, heightShift := lvl.Size()
+	height, _ = heightShift.ValueFromTileHeight(tile.Floor.AbsoluteHeight())
+	return height
+}
+
+// PrefabLibrary is a collection of prefabs.
+type PrefabLibrary struct {
+	Prefabs []Prefab
+}
+
+// PrefabLibraryFromJSON decodes a library that was previously encoded with JSON().
+func PrefabLibraryFromJSON(data []byte) (PrefabLibrary, error) {
+	var lib PrefabLibrary
+	err := json.Unmarshal(data, &lib)
+	if err != nil {
+		return PrefabLibrary{}, fmt.Errorf("invalid prefab library: %v", err)
+	}
+	return lib, nil
+}
+
+// JSON encodes the library.
+func (lib PrefabLibrary) JSON() []byte {
+	data, _ := json.MarshalIndent(lib, "", "  ")
+	return data
+}
@@ -0,0 +1,123 @@
+package lvlobj_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvltest"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefabInstantiateRemapsInternalReferences(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	door := placedObject(t, lvl, object.TripleFrom(10, 0, 0), 5, 6)
+	trap := placedObject(t, lvl, object.TripleFrom(12, 0, 0), 4, 8)
+	data := lvl.ObjectClassData(trap)
+	data[0] = 6 // trigger other objects
+	data[6] = byte(door)
+	prefab := lvlobj.NewPrefab(lvl, "test", []level.ObjectID{door, trap})
+
+	ids, err := prefab.Instantiate(lvl, 20, 30)
+	require.Nil(t, err, "no error expected")
+	require.Equal(t, 2, len(ids))
+
+	newDoor := lvl.Object(ids[0])
+	assert.Equal(t, 21, int(newDoor.X.Tile()))
+	assert.Equal(t, 30, int(newDoor.Y.Tile()))
+	assert.Equal(t, []lvlobj.ObjectReference{{Key: "Action.TriggerOtherObjects.Object1ID", ID: int(ids[0])}},
+		lvlobj.ObjectReferences(lvlobj.ForRealWorld(lvl.Object(ids[1]).Triple(), lvl.ObjectClassData(ids[1]))))
+}
+
+func TestPrefabInstantiateClearsExternalReferencesInOtherLevel(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	door := placedObject(t, lvl, object.TripleFrom(10, 0, 0), 5, 6)
+	trap := placedObject(t, lvl, object.TripleFrom(12, 0, 0), 4, 8)
+	data := lvl.ObjectClassData(trap)
+	data[0] = 6
+	data[6] = byte(door)
+	prefab := lvlobj.NewPrefab(lvl, "test", []level.ObjectID{trap})
+	prefab.SourceLevel = lvl.ID() + 1
+
+	ids, err := prefab.Instantiate(lvl, 20, 30)
+	require.Nil(t, err, "no error expected")
+
+	assert.Empty(t, lvlobj.ObjectReferences(lvlobj.ForRealWorld(lvl.Object(ids[0]).Triple(), lvl.ObjectClassData(ids[0]))))
+}
+
+func TestPrefabInstantiateKeepsContainedObjectsUnplaced(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	container := placedObject(t, lvl, object.TripleFrom(13, 1, 0), 5, 6)
+	item, err := lvlobj.NewContainedObject(lvl, container, 0, object.TripleFrom(8, 0, 1))
+	require.Nil(t, err, "no error expected")
+	prefab := lvlobj.NewPrefab(lvl, "test", []level.ObjectID{container, item})
+
+	ids, err := prefab.Instantiate(lvl, 10, 10)
+	require.Nil(t, err, "no error expected")
+
+	assert.False(t, lvl.IsObjectPlaced(ids[1]), "contained object should not be placed")
+	assert.Equal(t, []level.ObjectID{ids[1], 0, 0, 0}, lvlobj.ContainerContents(lvl, ids[0]))
+}
+
+func TestPrefabInstantiateFailsOutsideMap(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	first := placedObject(t, lvl, object.TripleFrom(8, 0, 0), 5, 6)
+	second := placedObject(t, lvl, object.TripleFrom(8, 0, 0), 9, 6)
+	prefab := lvlobj.NewPrefab(lvl, "test", []level.ObjectID{first, second})
+
+	_, err := prefab.Instantiate(lvl, 60, 6)
+
+	assert.NotNil(t, err, "error expected")
+}
+
+func TestPrefabLibraryRoundTrip(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	door := placedObject(t, lvl, object.TripleFrom(10, 0, 0), 5, 6)
+	lib := lvlobj.PrefabLibrary{Prefabs: []lvlobj.Prefab{lvlobj.NewPrefab(lvl, "door", []level.ObjectID{door})}}
+
+	decoded, err := lvlobj.PrefabLibraryFromJSON(lib.JSON())
+	require.Nil(t, err, "no error expected")
+
+	assert.Equal(t, lib, decoded)
+}
+
+func TestNewPrefabStripsTableIndices(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	first := placedObject(t, lvl, object.TripleFrom(8, 0, 0), 5, 6)
+	second := placedObject(t, lvl, object.TripleFrom(8, 0, 0), 5, 6)
+
+	prefab := lvlobj.NewPrefab(lvl, "test", []level.ObjectID{first, second})
+
+	for _, obj := range prefab.Objects {
+		assert.Equal(t, int16(0), obj.Entry.ClassTableIndex, "class table index should be stripped")
+		assert.Equal(t, int16(0), obj.Entry.CrossReferenceTableIndex, "cross reference should be stripped")
+		assert.Equal(t, level.ObjectID(0), obj.Entry.Next, "next should be stripped")
+		assert.Equal(t, level.ObjectID(0), obj.Entry.Prev, "prev should be stripped")
+	}
+}
+
+func TestPrefabInstantiatePlacesObjectsOnFloorOfTargetTile(t *testing.T) {
+	lvl := lvltest.EmptyLevel(func(tileMap level.TileMap) {
+		for _, pos := range [][3]int{{5, 6, 4}, {20, 30, 12}} {
+			tile := tileMap.Tile(pos[0], pos[1])
+			tile.Type = level.TileTypeOpen
+			tile.Floor = tile.Floor.WithAbsoluteHeight(level.TileHeightUnit(pos[2]))
+			tile.Ceiling = tile.Ceiling.WithAbsoluteHeight(24)
+		}
+	})
+	_, _, heightShift := lvl.Size()
+	sourceFloor, _ := heightShift.ValueFromTileHeight(4)
+	targetFloor, _ := heightShift.ValueFromTileHeight(12)
+	barrel := placedObject(t, lvl, object.TripleFrom(8, 0, 0), 5, 6)
+	lvl.Object(barrel).Z = heightShift.ValueToObjectHeight(sourceFloor + 0.25)
+	prefab := lvlobj.NewPrefab(lvl, "test", []level.ObjectID{barrel})
+
+	ids, err := prefab.Instantiate(lvl, 20, 30)
+	require.Nil(t, err, "no error expected")
+
+	height, _ := heightShift.ValueFromObjectHeight(lvl.Object(ids[0]).Z)
+	assert.InDelta(t, targetFloor+0.25, height, 0.05, "object should keep its height above the floor")
+}
@@ -0,0 +1,42 @@
+package lvlobj
+
+import (
+	"github.com/inkyblackness/hacked/ss1/content/interpreters"
+)
+
+// ObjectReference describes a field of interpreted class data that refers to another object of the same level.
+type ObjectReference struct {
+	// Key is the name of the field. Fields of refinements are prefixed with the refinement key and a dot.
+	Key string
+	// ID is the identifier of the referenced object.
+	ID int
+}
+
+// ObjectReferences returns all references to other objects, based on the interpreted class data.
+// A field is considered to be a reference if it is described as an object identifier.
+// Fields with a value of zero do not refer to an object and are not returned.
+func ObjectReferences(inst *interpreters.Instance) []ObjectReference {
+	var references []ObjectReference
+	collectObjectReferences(inst, "", &references)
+	return references
+}
+
+func collectObjectReferences(inst *interpreters.Instance, prefix string, references *[]ObjectReference) {
+	isObjectID := false
+	simplifier := interpreters.NewSimplifier(func(minValue, maxValue int64, formatter interpreters.RawValueFormatter) {})
+	simplifier.SetObjectIDHandler(func() { isObjectID = true })
+	for _, key := range inst.Keys() {
+		isObjectID = false
+		inst.Describe(key, simplifier)
+		if !isObjectID {
+			continue
+		}
+		id := int(int16(inst.Get(key)))
+		if id > 0 {
+			*references = append(*references, ObjectReference{Key: prefix + key, ID: id})
+		}
+	}
+	for _, key := range inst.ActiveRefinements() {
+		collectObjectReferences(inst.Refined(key), prefix+key+".", references)
+	}
+}
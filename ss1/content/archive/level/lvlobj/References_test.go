@@ -0,0 +1,41 @@
+package lvlobj_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectReferencesOfContainer(t *testing.T) {
+	data := make([]byte, 21)
+	data[0] = 5
+	data[4] = 0x07
+	data[5] = 0x01
+
+	references := lvlobj.ObjectReferences(lvlobj.ForRealWorld(object.TripleFrom(13, 1, 0), data))
+
+	assert.Equal(t, []lvlobj.ObjectReference{{Key: "ObjectID1", ID: 5}, {Key: "ObjectID3", ID: 0x107}}, references)
+}
+
+func TestObjectReferencesOfTriggerAction(t *testing.T) {
+	data := make([]byte, 28)
+	data[0] = 6 // trigger other objects
+	data[6+4] = 20
+
+	references := lvlobj.ObjectReferences(lvlobj.ForRealWorld(object.TripleFrom(12, 0, 0), data))
+
+	assert.Equal(t, []lvlobj.ObjectReference{{Key: "Action.TriggerOtherObjects.Object2ID", ID: 20}}, references)
+}
+
+func TestObjectReferencesIgnoresOtherFields(t *testing.T) {
+	data := make([]byte, 22)
+	data[0] = 1 // transport hacker
+	data[6] = 10
+
+	references := lvlobj.ObjectReferences(lvlobj.ForRealWorld(object.TripleFrom(12, 0, 0), data))
+
+	assert.Empty(t, references)
+}
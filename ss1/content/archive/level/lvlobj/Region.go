@@ -0,0 +1,44 @@
+package lvlobj
+
+import (
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+)
+
+// PasteRegion places the given region in the level, see level.PasteRegion().
+// References among the pasted objects are remapped to the new objects.
+// References to other objects are cleared, as the region may be pasted into another level or
+// the level may have changed since copying. Either way, these references would refer to unrelated objects.
+func PasteRegion(lvl *level.Level, region level.Region, toX, toY int) ([]level.ObjectID, error) {
+	ids, err := lvl.PasteRegion(region, toX, toY)
+	if err != nil {
+		return nil, err
+	}
+	remapReferences(lvl, region.Objects, ids, true)
+	return ids, nil
+}
+
+// remapReferences updates the references of newly created objects.
+// The sources describe the original objects, ids their new identifier, with 0 for objects that were not created.
+// References to other sources are changed to their new identifier, or cleared if the source was not created.
+// References to objects that are not part of the sources are cleared if requested.
+func remapReferences(lvl *level.Level, sources []level.RegionObject, ids []level.ObjectID, clearExternal bool) {
+	remapped := make(map[int]level.ObjectID)
+	for index, source := range sources {
+		if source.ID != 0 {
+			remapped[int(source.ID)] = ids[index]
+		}
+	}
+	interpreterFactory := interpreterFactoryFor(lvl)
+	for _, id := range ids {
+		if id == 0 {
+			continue
+		}
+		for _, ref := range ObjectReferences(interpreterFactory(lvl.Object(id).Triple(), lvl.ObjectClassData(id))) {
+			if newID, internal := remapped[ref.ID]; internal {
+				SetObjectReference(lvl, id, ref.Key, newID)
+			} else if clearExternal {
+				SetObjectReference(lvl, id, ref.Key, 0)
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package lvlobj_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlobj"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvltest"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasteRegionRemapsInternalReferences(t *testing.T) {
+	lvl := lvltest.EmptyLevel(nil)
+	external := placedObject(t, lvl, object.TripleFrom(10, 0, 0), 30, 30)
+	door := placedObject(t, lvl, object.TripleFrom(10, 0, 0), 5, 6)
+	trap := placedObject(t, lvl, object.TripleFrom(12, 0, 0), 4, 6)
+	otherTrap := placedObject(t, lvl, object.TripleFrom(12, 0, 0), 5, 6)
+	data := lvl.ObjectClassData(trap)
+	data[0] = 6 // trigger other objects
+	data[6] = byte(door)
+	otherData := lvl.ObjectClassData(otherTrap)
+	otherData[0] = 6
+	otherData[6] = byte(external)
+	region := lvl.CopyRegion(4, 6, 2, 1)
+
+	ids, err := lvlobj.PasteRegion(lvl, region, 20, 30)
+	require.Nil(t, err, "no error expected")
+	require.Equal(t, 3, len(ids))
+
+	references := func(id level.ObjectID) []lvlobj.ObjectReference {
+		return lvlobj.ObjectReferences(lvlobj.ForRealWorld(lvl.Object(id).Triple(), lvl.ObjectClassData(id)))
+	}
+	newIDs := make(map[level.ObjectID]level.ObjectID)
+	for index, obj := range region.Objects {
+		newIDs[obj.ID] = ids[index]
+	}
+	assert.Equal(t, []lvlobj.ObjectReference{{Key: "Action.TriggerOtherObjects.Object1ID", ID: int(newIDs[door])}},
+		references(newIDs[trap]), "internal reference should be remapped")
+	assert.Empty(t, references(newIDs[otherTrap]), "external reference should be cleared")
+}
+
+func TestPasteRegionClearsExternalReferencesInOtherLevel(t *testing.T) {
+	source := lvltest.EmptyLevel(nil)
+	external := placedObject(t, source, object.TripleFrom(10, 0, 0), 30, 30)
+	trap := placedObject(t, source, object.TripleFrom(12, 0, 0), 4, 6)
+	data := source.ObjectClassData(trap)
+	data[0] = 6 // trigger other objects
+	data[6] = byte(external)
+	region := source.CopyRegion(4, 6, 1, 1)
+	target := lvltest.EmptyLevel(nil)
+	placedObject(t, target, object.TripleFrom(8, 0, 0), 1, 1)
+	placedObject(t, target, object.TripleFrom(8, 0, 0), 1, 1)
+
+	ids, err := lvlobj.PasteRegion(target, region, 10, 10)
+	require.Nil(t, err, "no error expected")
+	require.Equal(t, 1, len(ids))
+
+	references := lvlobj.ObjectReferences(lvlobj.ForRealWorld(target.Object(ids[0]).Triple(), target.ObjectClassData(ids[0])))
+	assert.Empty(t, references, "reference into source level should be cleared")
+}
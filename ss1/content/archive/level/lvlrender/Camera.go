@@ -0,0 +1,68 @@
+package lvlrender
+
+import (
+	"math"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+)
+
+// DefaultEyeHeight is the height, in tiles, above the floor a camera is placed by CameraAt.
+const DefaultEyeHeight = 0.75
+
+// Camera describes the viewpoint for a first-person rendering.
+// Positions are in tiles, with X pointing east, Y pointing north, and Z pointing up.
+type Camera struct {
+	X, Y, Z float32
+	// Heading is the horizontal view direction, in degrees. 0 faces north, 90 east.
+	Heading float32
+	// Pitch is the vertical view direction, in degrees. Positive values look up.
+	Pitch float32
+	// FieldOfView is the horizontal opening angle, in degrees.
+	FieldOfView float32
+}
+
+// CameraAt returns a camera that stands in the center of the given tile, at eye height above the floor.
+func CameraAt(lvl *level.Level, tileX, tileY int, heading float32) Camera {
+	cam := Camera{
+		X:           float32(tileX) + 0.5,
+		Y:           float32(tileY) + 0.5,
+		Z:           DefaultEyeHeight,
+		Heading:     heading,
+		FieldOfView: 75,
+	}
+	tile := lvl.Tile(tileX, tileY)
+	if tile != nil {
+		_, _, heightShift := lvl.Size()
+		floor, err := heightShift.ValueFromTileHeight(tile.Floor.AbsoluteHeight())
+		if err == nil {
+			cam.Z += floor
+		}
+	}
+	return cam
+}
+
+type cameraBasis struct {
+	origin  [3]float32
+	right   [3]float32
+	up      [3]float32
+	forward [3]float32
+}
+
+func (cam Camera) basis() cameraBasis {
+	heading := float64(cam.Heading) * math.Pi / 180.0
+	pitch := float64(cam.Pitch) * math.Pi / 180.0
+	sinH, cosH := float32(math.Sin(heading)), float32(math.Cos(heading))
+	sinP, cosP := float32(math.Sin(pitch)), float32(math.Cos(pitch))
+	return cameraBasis{
+		origin:  [3]float32{cam.X, cam.Y, cam.Z},
+		forward: [3]float32{sinH * cosP, cosH * cosP, sinP},
+		right:   [3]float32{cosH, -sinH, 0},
+		up:      [3]float32{-sinH * sinP, -cosH * sinP, cosP},
+	}
+}
+
+func (basis cameraBasis) toView(vert vertex) [3]float32 {
+	d := [3]float32{vert.x - basis.origin[0], vert.y - basis.origin[1], vert.z - basis.origin[2]}
+	dot := func(a [3]float32) float32 { return a[0]*d[0] + a[1]*d[1] + a[2]*d[2] }
+	return [3]float32{dot(basis.right), dot(basis.up), dot(basis.forward)}
+}
@@ -0,0 +1,215 @@
+// Package lvlrender creates images of levels without the need of graphics hardware.
+package lvlrender
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/bitmap"
+)
+
+const nearPlane = 0.05
+
+// TextureRetriever returns the bitmap for the identified game texture.
+type TextureRetriever func(index level.TextureIndex) (*bitmap.Bitmap, error)
+
+// FirstPersonRenderer renders a perspective view of a level with a software rasterizer.
+type FirstPersonRenderer struct {
+	palette  bitmap.Palette
+	textures TextureRetriever
+	bitmaps  map[level.TextureIndex]*bitmap.Bitmap
+}
+
+// NewFirstPersonRenderer returns a new instance.
+func NewFirstPersonRenderer(palette bitmap.Palette, textures TextureRetriever) *FirstPersonRenderer {
+	return &FirstPersonRenderer{
+		palette:  palette,
+		textures: textures,
+		bitmaps:  make(map[level.TextureIndex]*bitmap.Bitmap),
+	}
+}
+
+type viewVertex struct {
+	x, y, z float32
+	u, v    float32
+}
+
+type screenVertex struct {
+	x, y   float32
+	invZ   float32
+	uOverZ float32
+	vOverZ float32
+}
+
+type rasterTarget struct {
+	img   *image.RGBA
+	depth []float32
+}
+
+// Render creates an image of given size, seen through the camera.
+func (renderer *FirstPersonRenderer) Render(lvl *level.Level, cam Camera, width, height int) *image.RGBA {
+	if (width <= 0) || (height <= 0) {
+		return image.NewRGBA(image.Rect(0, 0, 0, 0))
+	}
+	target := rasterTarget{
+		img:   image.NewRGBA(image.Rect(0, 0, width, height)),
+		depth: make([]float32, width*height),
+	}
+	for i := 0; i < len(target.img.Pix); i += 4 {
+		target.img.Pix[i+3] = 0xFF
+	}
+	fieldOfView := cam.FieldOfView
+	if (fieldOfView <= 0) || (fieldOfView >= 180) {
+		fieldOfView = 75
+	}
+	focal := float32(width) / 2 / float32(math.Tan(float64(fieldOfView)*math.Pi/360.0))
+	basis := cam.basis()
+
+	for _, surf := range newGeometryBuilder(lvl).build() {
+		viewVertices := make([]viewVertex, 0, len(surf.vertices))
+		for _, vert := range surf.vertices {
+			pos := basis.toView(vert)
+			viewVertices = append(viewVertices, viewVertex{x: pos[0], y: pos[1], z: pos[2], u: vert.u, v: vert.v})
+		}
+		clipped := clipNear(viewVertices)
+		if len(clipped) < 3 {
+			continue
+		}
+		projected := make([]screenVertex, len(clipped))
+		for index, vert := range clipped {
+			invZ := 1 / vert.z
+			projected[index] = screenVertex{
+				x:      float32(width)/2 + vert.x*focal*invZ,
+				y:      float32(height)/2 - vert.y*focal*invZ,
+				invZ:   invZ,
+				uOverZ: vert.u * invZ,
+				vOverZ: vert.v * invZ,
+			}
+		}
+		shade := renderer.shaderFor(surf)
+		for index := 1; index < len(projected)-1; index++ {
+			target.rasterize(projected[0], projected[index], projected[index+1], shade)
+		}
+	}
+	return target.img
+}
+
+func (renderer *FirstPersonRenderer) bitmapFor(index level.TextureIndex) *bitmap.Bitmap {
+	bmp, cached := renderer.bitmaps[index]
+	if !cached {
+		var err error
+		bmp, err = renderer.textures(index)
+		if (err != nil) || (bmp == nil) || (bmp.Header.Width == 0) || (bmp.Header.Height == 0) {
+			bmp = nil
+		}
+		renderer.bitmaps[index] = bmp
+	}
+	return bmp
+}
+
+func (renderer *FirstPersonRenderer) shaderFor(surf surface) func(u, v float32) color.RGBA {
+	light := surf.light
+	lit := func(rgb bitmap.RGB) color.RGBA {
+		return color.RGBA{
+			R: uint8(float32(rgb.Red) * light),
+			G: uint8(float32(rgb.Green) * light),
+			B: uint8(float32(rgb.Blue) * light),
+			A: 0xFF,
+		}
+	}
+	var bmp *bitmap.Bitmap
+	if surf.texture >= 0 {
+		bmp = renderer.bitmapFor(surf.texture)
+	}
+	if bmp == nil {
+		flat := lit(renderer.palette[surf.paletteIndex])
+		if surf.texture >= 0 {
+			flat = lit(bitmap.RGB{Red: 0x80, Green: 0x80, Blue: 0x80})
+		}
+		return func(float32, float32) color.RGBA { return flat }
+	}
+	bmpWidth := int(bmp.Header.Width)
+	bmpHeight := int(bmp.Header.Height)
+	stride := int(bmp.Header.Stride)
+	return func(u, v float32) color.RGBA {
+		x := int((u - float32(math.Floor(float64(u)))) * float32(bmpWidth))
+		y := int((v - float32(math.Floor(float64(v)))) * float32(bmpHeight))
+		if x >= bmpWidth {
+			x = bmpWidth - 1
+		}
+		if y >= bmpHeight {
+			y = bmpHeight - 1
+		}
+		return lit(renderer.palette[bmp.Pixels[y*stride+x]])
+	}
+}
+
+// clipNear clips the polygon against the near plane.
+func clipNear(vertices []viewVertex) []viewVertex {
+	var result []viewVertex
+	count := len(vertices)
+	for index := 0; index < count; index++ {
+		current := vertices[index]
+		next := vertices[(index+1)%count]
+		currentInside := current.z >= nearPlane
+		nextInside := next.z >= nearPlane
+		if currentInside {
+			result = append(result, current)
+		}
+		if currentInside != nextInside {
+			t := (nearPlane - current.z) / (next.z - current.z)
+			result = append(result, viewVertex{
+				x: current.x + (next.x-current.x)*t,
+				y: current.y + (next.y-current.y)*t,
+				z: nearPlane,
+				u: current.u + (next.u-current.u)*t,
+				v: current.v + (next.v-current.v)*t,
+			})
+		}
+	}
+	return result
+}
+
+func (target *rasterTarget) rasterize(a, b, c screenVertex, shade func(u, v float32) color.RGBA) {
+	area := (b.x-a.x)*(c.y-a.y) - (b.y-a.y)*(c.x-a.x)
+	if area == 0 {
+		return
+	}
+	bounds := target.img.Bounds()
+	minX := int(math.Max(math.Floor(float64(min3(a.x, b.x, c.x))), 0))
+	maxX := int(math.Min(math.Ceil(float64(max3(a.x, b.x, c.x))), float64(bounds.Dx()-1)))
+	minY := int(math.Max(math.Floor(float64(min3(a.y, b.y, c.y))), 0))
+	maxY := int(math.Min(math.Ceil(float64(max3(a.y, b.y, c.y))), float64(bounds.Dy()-1)))
+
+	for y := minY; y <= maxY; y++ {
+		py := float32(y) + 0.5
+		for x := minX; x <= maxX; x++ {
+			px := float32(x) + 0.5
+			w0 := ((b.x-px)*(c.y-py) - (b.y-py)*(c.x-px)) / area
+			w1 := ((c.x-px)*(a.y-py) - (c.y-py)*(a.x-px)) / area
+			w2 := 1 - w0 - w1
+			if (w0 < 0) || (w1 < 0) || (w2 < 0) {
+				continue
+			}
+			invZ := w0*a.invZ + w1*b.invZ + w2*c.invZ
+			depthIndex := y*bounds.Dx() + x
+			if invZ <= target.depth[depthIndex] {
+				continue
+			}
+			target.depth[depthIndex] = invZ
+			u := (w0*a.uOverZ + w1*b.uOverZ + w2*c.uOverZ) / invZ
+			v := (w0*a.vOverZ + w1*b.vOverZ + w2*c.vOverZ) / invZ
+			target.img.SetRGBA(x, y, shade(u, v))
+		}
+	}
+}
+
+func min3(a, b, c float32) float32 {
+	return float32(math.Min(float64(a), math.Min(float64(b), float64(c))))
+}
+
+func max3(a, b, c float32) float32 {
+	return float32(math.Max(float64(a), math.Max(float64(b), float64(c))))
+}
@@ -0,0 +1,71 @@
+package lvlrender_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlrender"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvltest"
+	"github.com/inkyblackness/hacked/ss1/content/bitmap"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// roomLevel returns a level with an open room from (10, 10) to (12, 12).
+func roomLevel() *level.Level {
+	return lvltest.EmptyLevel(func(tileMap level.TileMap) {
+		for y := 10; y <= 12; y++ {
+			for x := 10; x <= 12; x++ {
+				tile := tileMap.Tile(x, y)
+				tile.Type = level.TileTypeOpen
+				tile.Floor = tile.Floor.WithAbsoluteHeight(0)
+				tile.Ceiling = tile.Ceiling.WithAbsoluteHeight(8)
+			}
+		}
+	})
+}
+
+func redTexture(level.TextureIndex) (*bitmap.Bitmap, error) {
+	bmp := &bitmap.Bitmap{Pixels: []byte{1, 1, 1, 1}}
+	bmp.Header.Width = 2
+	bmp.Header.Height = 2
+	bmp.Header.Stride = 2
+	return bmp, nil
+}
+
+func TestFirstPersonRendererDrawsEnclosedRoom(t *testing.T) {
+	var palette bitmap.Palette
+	palette[1] = bitmap.RGB{Red: 200}
+	renderer := lvlrender.NewFirstPersonRenderer(palette, redTexture)
+	lvl := roomLevel()
+
+	img := renderer.Render(lvl, lvlrender.CameraAt(lvl, 11, 11, 0), 64, 48)
+
+	assert.Equal(t, 64, img.Bounds().Dx())
+	assert.Equal(t, 48, img.Bounds().Dy())
+	for _, pos := range [][2]int{{32, 24}, {0, 0}, {63, 47}, {10, 40}} {
+		pixel := img.RGBAAt(pos[0], pos[1])
+		assert.True(t, pixel.R > 0, "pixel at %v should be drawn", pos)
+		assert.Equal(t, uint8(0), pixel.G, "pixel at %v should be red", pos)
+	}
+}
+
+func TestFirstPersonRendererOutsideMapIsBlack(t *testing.T) {
+	renderer := lvlrender.NewFirstPersonRenderer(bitmap.Palette{}, redTexture)
+	lvl := roomLevel()
+	cam := lvlrender.Camera{X: -10, Y: -10, Z: 0.5, Heading: 180, FieldOfView: 60}
+
+	img := renderer.Render(lvl, cam, 16, 16)
+
+	assert.Equal(t, uint8(0), img.RGBAAt(8, 8).R)
+}
+
+func TestFirstPersonRendererReturnsEmptyImageForInvalidSize(t *testing.T) {
+	renderer := lvlrender.NewFirstPersonRenderer(bitmap.Palette{}, redTexture)
+	lvl := roomLevel()
+
+	for _, size := range [][2]int{{0, 0}, {-1, 10}, {10, -1}} {
+		img := renderer.Render(lvl, lvlrender.CameraAt(lvl, 11, 11, 0), size[0], size[1])
+		assert.True(t, img.Bounds().Empty(), "image for size %v should be empty", size)
+	}
+}
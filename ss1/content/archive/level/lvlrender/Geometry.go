@@ -0,0 +1,265 @@
+package lvlrender
+
+import (
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+)
+
+// vertex is a point in world space (in tiles, Z up) with texture coordinates.
+type vertex struct {
+	x, y, z float32
+	u, v    float32
+}
+
+// surface is a convex polygon that is rendered with one texture (or color) and one light level.
+type surface struct {
+	vertices []vertex
+	// texture is the game-wide texture index. A negative value means the surface uses paletteIndex instead.
+	texture      level.TextureIndex
+	paletteIndex byte
+	light        float32
+}
+
+type tileCorners struct {
+	floor   [8]float32
+	ceiling [8]float32
+}
+
+var cornerOffsets = map[level.Direction][2]float32{
+	level.DirNorthEast: {1, 1},
+	level.DirSouthEast: {1, 0},
+	level.DirSouthWest: {0, 0},
+	level.DirNorthWest: {0, 1},
+}
+
+var sideCorners = map[level.Direction][2]level.Direction{
+	level.DirNorth: {level.DirNorthWest, level.DirNorthEast},
+	level.DirEast:  {level.DirNorthEast, level.DirSouthEast},
+	level.DirSouth: {level.DirSouthEast, level.DirSouthWest},
+	level.DirWest:  {level.DirSouthWest, level.DirNorthWest},
+}
+
+var sideNeighbors = map[level.Direction][2]int{
+	level.DirNorth: {0, 1},
+	level.DirEast:  {1, 0},
+	level.DirSouth: {0, -1},
+	level.DirWest:  {-1, 0},
+}
+
+// geometryBuilder creates the surfaces of a level.
+type geometryBuilder struct {
+	lvl         *level.Level
+	heightScale float32
+	atlas       level.TextureAtlas
+	cyberspace  bool
+}
+
+func newGeometryBuilder(lvl *level.Level) *geometryBuilder {
+	_, _, heightShift := lvl.Size()
+	fullHeight, err := heightShift.ValueFromTileHeight(level.TileHeightUnitMax)
+	if err != nil {
+		fullHeight = 1.0
+	}
+	return &geometryBuilder{
+		lvl:         lvl,
+		heightScale: fullHeight / float32(level.TileHeightUnitMax),
+		atlas:       lvl.TextureAtlas(),
+		cyberspace:  lvl.IsCyberspace(),
+	}
+}
+
+// tileHeight returns the height in tiles of given raw height.
+func (builder *geometryBuilder) tileHeight(raw float32) float32 {
+	return raw * builder.heightScale
+}
+
+func (builder *geometryBuilder) corners(tile *level.TileMapEntry) tileCorners {
+	var corners tileCorners
+	slopeControl := tile.Flags.SlopeControl()
+	floorFactors := slopeControl.FloorSlopeFactors(tile.Type)
+	ceilingFactors := slopeControl.CeilingSlopeFactors(tile.Type)
+	slope := float32(tile.SlopeHeight)
+	floor := float32(tile.Floor.AbsoluteHeight())
+	ceiling := float32(tile.Ceiling.AbsoluteHeight())
+	for dir := level.DirNorth; dir <= level.DirNorthWest; dir++ {
+		corners.floor[dir] = builder.tileHeight(floor + floorFactors[dir]*slope)
+		corners.ceiling[dir] = builder.tileHeight(ceiling - ceilingFactors[dir]*slope)
+	}
+	return corners
+}
+
+func (builder *geometryBuilder) shadowLight(shadow int) float32 {
+	if shadow < 0 {
+		shadow = 0
+	}
+	if shadow > 15 {
+		shadow = 15
+	}
+	return 1.0 - float32(shadow)/16.0
+}
+
+func (builder *geometryBuilder) atlasTexture(index int) level.TextureIndex {
+	if (index < 0) || (index >= len(builder.atlas)) {
+		return -1
+	}
+	return builder.atlas[index]
+}
+
+// build returns all surfaces of the level. Solid tiles have no surfaces.
+func (builder *geometryBuilder) build() []surface {
+	var surfaces []surface
+	columns, rows, _ := builder.lvl.Size()
+	for y := 0; y < rows; y++ {
+		for x := 0; x < columns; x++ {
+			surfaces = builder.appendTile(surfaces, x, y)
+		}
+	}
+	return surfaces
+}
+
+func (builder *geometryBuilder) appendTile(surfaces []surface, x, y int) []surface {
+	tile := builder.lvl.Tile(x, y)
+	if (tile == nil) || (tile.Type == level.TileTypeSolid) {
+		return surfaces
+	}
+	info := tile.Type.Info()
+	corners := builder.corners(tile)
+	isSolidSide := func(dir level.Direction) bool { return (info.SolidSides & dir.AsMask()) != 0 }
+
+	var openCorners []level.Direction
+	for _, corner := range []level.Direction{level.DirSouthWest, level.DirSouthEast, level.DirNorthEast, level.DirNorthWest} {
+		if !isSolidSide(corner.Offset(-1)) || !isSolidSide(corner.Offset(1)) {
+			openCorners = append(openCorners, corner)
+		}
+	}
+
+	floorTexture, ceilingTexture := level.TextureIndex(-1), level.TextureIndex(-1)
+	floorLight, ceilingLight := float32(1.0), float32(1.0)
+	var floorColor, ceilingColor byte
+	if builder.cyberspace {
+		floorColor = tile.TextureInfo.FloorPaletteIndex()
+		ceilingColor = tile.TextureInfo.CeilingPaletteIndex()
+	} else {
+		flags := tile.Flags.ForRealWorld()
+		floorTexture = builder.atlasTexture(tile.TextureInfo.FloorTextureIndex())
+		ceilingTexture = builder.atlasTexture(tile.TextureInfo.CeilingTextureIndex())
+		// LightDelta is considered an additional shadow offset; lower nibble for the floor, upper for the ceiling.
+		floorLight = builder.shadowLight(flags.FloorShadow() + int(tile.LightDelta&0x0F))
+		ceilingLight = builder.shadowLight(flags.CeilingShadow() + int(tile.LightDelta>>4))
+	}
+
+	flatVertex := func(corner level.Direction, z float32, rotations int) vertex {
+		offset := cornerOffsets[corner]
+		u, v := rotatedUV(offset[0], 1.0-offset[1], rotations)
+		return vertex{x: float32(x) + offset[0], y: float32(y) + offset[1], z: z, u: u, v: v}
+	}
+	floor := surface{texture: floorTexture, paletteIndex: floorColor, light: floorLight}
+	ceiling := surface{texture: ceilingTexture, paletteIndex: ceilingColor, light: ceilingLight}
+	for _, corner := range openCorners {
+		floor.vertices = append(floor.vertices, flatVertex(corner, corners.floor[corner], tile.Floor.TextureRotations()))
+	}
+	for index := len(openCorners) - 1; index >= 0; index-- {
+		corner := openCorners[index]
+		ceiling.vertices = append(ceiling.vertices, flatVertex(corner, corners.ceiling[corner], tile.Ceiling.TextureRotations()))
+	}
+	surfaces = append(surfaces, floor, ceiling)
+
+	wallLight := (floorLight + ceilingLight) / 2
+	ownWallTexture := builder.atlasTexture(tile.TextureInfo.WallTextureIndex())
+	wallAnchor := builder.tileHeight(float32(tile.WallTextureBase() + int(tile.Flags.ForRealWorld().WallTextureOffset())))
+	wall := func(from, to level.Direction, fromBottom, toBottom, fromTop, toTop float32, texture level.TextureIndex) surface {
+		fromOffset := cornerOffsets[from]
+		toOffset := cornerOffsets[to]
+		fromX, fromY := float32(x)+fromOffset[0], float32(y)+fromOffset[1]
+		toX, toY := float32(x)+toOffset[0], float32(y)+toOffset[1]
+		return surface{
+			texture:      texture,
+			paletteIndex: floorColor,
+			light:        wallLight,
+			vertices: []vertex{
+				{x: fromX, y: fromY, z: fromBottom, u: 0, v: wallAnchor - fromBottom},
+				{x: toX, y: toY, z: toBottom, u: 1, v: wallAnchor - toBottom},
+				{x: toX, y: toY, z: toTop, u: 1, v: wallAnchor - toTop},
+				{x: fromX, y: fromY, z: fromTop, u: 0, v: wallAnchor - fromTop},
+			},
+		}
+	}
+	if builder.cyberspace {
+		ownWallTexture = -1
+	}
+
+	if len(openCorners) == 3 {
+		var excluded level.Direction
+		for _, corner := range []level.Direction{level.DirSouthWest, level.DirSouthEast, level.DirNorthEast, level.DirNorthWest} {
+			if isSolidSide(corner.Offset(-1)) && isSolidSide(corner.Offset(1)) {
+				excluded = corner
+			}
+		}
+		from, to := excluded.Offset(-2), excluded.Offset(2)
+		surfaces = append(surfaces, wall(from, to,
+			corners.floor[from], corners.floor[to], corners.ceiling[from], corners.ceiling[to], ownWallTexture))
+	}
+
+	for _, side := range []level.Direction{level.DirNorth, level.DirEast, level.DirSouth, level.DirWest} {
+		if isSolidSide(side) {
+			continue
+		}
+		from, to := sideCorners[side][0], sideCorners[side][1]
+		offset := sideNeighbors[side]
+		other := builder.lvl.Tile(x+offset[0], y+offset[1])
+		texture := ownWallTexture
+		if (other != nil) && !builder.cyberspace && tile.Flags.ForRealWorld().UseAdjacentWallTexture() {
+			texture = builder.atlasTexture(other.TextureInfo.WallTextureIndex())
+		}
+		if (other == nil) || ((other.Type.Info().SolidSides & side.Offset(4).AsMask()) != 0) {
+			surfaces = append(surfaces, wall(from, to,
+				corners.floor[from], corners.floor[to], corners.ceiling[from], corners.ceiling[to], texture))
+			continue
+		}
+		otherCorners := builder.corners(other)
+		otherFrom, otherTo := mirroredCorner(from, side), mirroredCorner(to, side)
+		lower := func(a, b float32) float32 {
+			if a < b {
+				return a
+			}
+			return b
+		}
+		higher := func(a, b float32) float32 {
+			if a > b {
+				return a
+			}
+			return b
+		}
+		otherFloorFrom := lower(otherCorners.floor[otherFrom], corners.ceiling[from])
+		otherFloorTo := lower(otherCorners.floor[otherTo], corners.ceiling[to])
+		if (otherFloorFrom > corners.floor[from]) || (otherFloorTo > corners.floor[to]) {
+			surfaces = append(surfaces, wall(from, to,
+				corners.floor[from], corners.floor[to],
+				higher(otherFloorFrom, corners.floor[from]), higher(otherFloorTo, corners.floor[to]), texture))
+		}
+		otherCeilingFrom := higher(otherCorners.ceiling[otherFrom], corners.floor[from])
+		otherCeilingTo := higher(otherCorners.ceiling[otherTo], corners.floor[to])
+		if (otherCeilingFrom < corners.ceiling[from]) || (otherCeilingTo < corners.ceiling[to]) {
+			surfaces = append(surfaces, wall(from, to,
+				lower(otherCeilingFrom, corners.ceiling[from]), lower(otherCeilingTo, corners.ceiling[to]),
+				corners.ceiling[from], corners.ceiling[to], texture))
+		}
+	}
+
+	return surfaces
+}
+
+// mirroredCorner returns the corner of the neighboring tile, across given side, which shares the position.
+func mirroredCorner(corner level.Direction, side level.Direction) level.Direction {
+	if (side == level.DirNorth) || (side == level.DirSouth) {
+		return level.Direction((12 - int(corner)) % 8)
+	}
+	return level.Direction((8 - int(corner)) % 8)
+}
+
+// rotatedUV rotates the coordinates within the unit square clockwise by the given amount of quarters.
+func rotatedUV(u, v float32, rotations int) (float32, float32) {
+	for i := 0; i < (4+rotations%4)%4; i++ {
+		u, v = 1.0-v, u
+	}
+	return u, v
+}
@@ -0,0 +1,21 @@
+package lvlrender
+
+import (
+	"image"
+	"image/png"
+	"os"
+)
+
+// SavePNG stores the given image as PNG file.
+func SavePNG(filename string, img image.Image) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	err = png.Encode(file, img)
+	closeErr := file.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
@@ -0,0 +1,102 @@
+package lvlrender
+
+import (
+	"strings"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/bitmap"
+)
+
+// TileTextureFunc resolves which texture of the atlas is shown for a tile, and how it is rotated.
+type TileTextureFunc func(tile *level.TileMapEntry) (atlasIndex int, textureRotations int)
+
+// TileColorFunc returns an overlay color (RGBA, range [0..1]) for the tile at given position.
+type TileColorFunc func(x, y int) [4]float32
+
+// FloorTexture returns the properties for the floor.
+func FloorTexture(tile *level.TileMapEntry) (atlasIndex int, textureRotations int) {
+	return tile.TextureInfo.FloorTextureIndex(), tile.Floor.TextureRotations()
+}
+
+// CeilingTexture returns the properties for the ceiling.
+func CeilingTexture(tile *level.TileMapEntry) (atlasIndex int, textureRotations int) {
+	return tile.TextureInfo.CeilingTextureIndex(), tile.Ceiling.TextureRotations()
+}
+
+// WallTexture returns the properties for the wall.
+func WallTexture(tile *level.TileMapEntry) (atlasIndex int, textureRotations int) {
+	return tile.TextureInfo.WallTextureIndex(), 0
+}
+
+// TileTextureNamed returns the texture function for one of the names "none", "floor", "wall" or "ceiling".
+// The name is not case sensitive. The function is nil for "none".
+func TileTextureNamed(name string) (TileTextureFunc, bool) {
+	switch strings.ToLower(name) {
+	case "none":
+		return nil, true
+	case "floor":
+		return FloorTexture, true
+	case "wall":
+		return WallTexture, true
+	case "ceiling":
+		return CeilingTexture, true
+	default:
+		return nil, false
+	}
+}
+
+// FloorColors returns the colors of the floor of given level.
+// Cyberspace levels are colored with their palette entries, real-world levels with their shadows.
+func FloorColors(lvl *level.Level, palette bitmap.Palette) TileColorFunc {
+	if lvl.IsCyberspace() {
+		return tileColors(lvl, func(tile *level.TileMapEntry) [4]float32 {
+			return paletteColor(palette, tile.TextureInfo.FloorPaletteIndex())
+		})
+	}
+	return tileColors(lvl, func(tile *level.TileMapEntry) [4]float32 {
+		return [4]float32{0.0, 0.0, 0.0, float32(tile.Flags.ForRealWorld().FloorShadow()) / 15.0}
+	})
+}
+
+// CeilingColors returns the colors of the ceiling of given level.
+// Cyberspace levels are colored with their palette entries, real-world levels with their shadows.
+func CeilingColors(lvl *level.Level, palette bitmap.Palette) TileColorFunc {
+	if lvl.IsCyberspace() {
+		return tileColors(lvl, func(tile *level.TileMapEntry) [4]float32 {
+			return paletteColor(palette, tile.TextureInfo.CeilingPaletteIndex())
+		})
+	}
+	return tileColors(lvl, func(tile *level.TileMapEntry) [4]float32 {
+		return [4]float32{0.0, 0.0, 0.0, float32(tile.Flags.ForRealWorld().CeilingShadow()) / 15.0}
+	})
+}
+
+// TileColorsNamed returns the color function for one of the names "none", "floor" or "ceiling".
+// The name is not case sensitive. The function is nil for "none".
+func TileColorsNamed(name string, lvl *level.Level, palette bitmap.Palette) (TileColorFunc, bool) {
+	switch strings.ToLower(name) {
+	case "none":
+		return nil, true
+	case "floor":
+		return FloorColors(lvl, palette), true
+	case "ceiling":
+		return CeilingColors(lvl, palette), true
+	default:
+		return nil, false
+	}
+}
+
+func tileColors(lvl *level.Level, tileToColor func(*level.TileMapEntry) [4]float32) TileColorFunc {
+	return func(x, y int) [4]float32 {
+		tile := lvl.Tile(x, y)
+		if tile == nil {
+			return [4]float32{}
+		}
+		return tileToColor(tile)
+	}
+}
+
+func paletteColor(palette bitmap.Palette, index byte) [4]float32 {
+	rgb := palette[index]
+	return [4]float32{float32(rgb.Red) / 255, float32(rgb.Green) / 255, float32(rgb.Blue) / 255, 0.8}
+}
@@ -0,0 +1,40 @@
+package lvlrender_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlrender"
+	"github.com/inkyblackness/hacked/ss1/content/bitmap"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTileTextureNamed(t *testing.T) {
+	lvl := roomLevel()
+	tile := lvl.Tile(10, 10)
+	tile.TextureInfo = tile.TextureInfo.WithFloorTextureIndex(3).WithCeilingTextureIndex(4)
+
+	floor, known := lvlrender.TileTextureNamed("Floor")
+	require.True(t, known, "floor should be known")
+	atlasIndex, _ := floor(tile)
+	assert.Equal(t, 3, atlasIndex, "floor texture expected")
+	none, known := lvlrender.TileTextureNamed("none")
+	assert.True(t, known, "none should be known")
+	assert.Nil(t, none, "no function expected for none")
+	_, known = lvlrender.TileTextureNamed("sky")
+	assert.False(t, known, "unknown name should be rejected")
+}
+
+func TestTileColorsNamed(t *testing.T) {
+	lvl := roomLevel()
+	tile := lvl.Tile(10, 10)
+	tile.Flags = tile.Flags.ForRealWorld().WithFloorShadow(15).AsTileFlag()
+
+	colors, known := lvlrender.TileColorsNamed("FLOOR", lvl, bitmap.Palette{})
+	require.True(t, known, "floor should be known")
+	assert.Equal(t, [4]float32{0, 0, 0, 1}, colors(10, 10), "full shadow expected")
+	assert.Equal(t, [4]float32{}, colors(-1, 0), "no color expected outside map")
+	_, known = lvlrender.TileColorsNamed("wall", lvl, bitmap.Palette{})
+	assert.False(t, known, "unknown name should be rejected")
+}
@@ -0,0 +1,227 @@
+package lvlrender
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/bitmap"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+)
+
+// MapImageOptions describe what a map image shall contain.
+type MapImageOptions struct {
+	// PixelsPerTile is the edge length of one tile in the image.
+	PixelsPerTile int
+	// TextureFunc selects which texture is shown for a tile. No textures are drawn if nil.
+	TextureFunc TileTextureFunc
+	// ColorFunc returns an overlay color (RGBA, range [0..1]) for a tile. No overlay is drawn if nil.
+	ColorFunc TileColorFunc
+	// ObjectIcons provides the icons for objects. No objects are drawn if nil.
+	ObjectIcons ObjectIconRetriever
+}
+
+var (
+	mapSolidColor     = color.RGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xFF}
+	mapOpenColor      = color.RGBA{R: 0x60, G: 0x60, B: 0x60, A: 0xFF}
+	mapWallColor      = color.RGBA{R: 0xE0, G: 0xE0, B: 0xE0, A: 0xFF}
+	mapStepColor      = color.RGBA{R: 0x90, G: 0x90, B: 0x90, A: 0xFF}
+	mapIconSizeFactor = 2
+)
+
+// MapRenderer renders a top-down map of a level, with north pointing up.
+type MapRenderer struct {
+	palette  bitmap.Palette
+	textures TextureRetriever
+	bitmaps  map[level.TextureIndex]*bitmap.Bitmap
+}
+
+// NewMapRenderer returns a new instance.
+func NewMapRenderer(palette bitmap.Palette, textures TextureRetriever) *MapRenderer {
+	return &MapRenderer{
+		palette:  palette,
+		textures: textures,
+		bitmaps:  make(map[level.TextureIndex]*bitmap.Bitmap),
+	}
+}
+
+// Render creates the image of the whole level.
+func (renderer *MapRenderer) Render(lvl *level.Level, options MapImageOptions) *image.RGBA {
+	pixelsPerTile := options.PixelsPerTile
+	if pixelsPerTile < 4 {
+		pixelsPerTile = 4
+	}
+	columns, rows, _ := lvl.Size()
+	img := image.NewRGBA(image.Rect(0, 0, columns*pixelsPerTile, rows*pixelsPerTile))
+	atlas := lvl.TextureAtlas()
+
+	for y := 0; y < rows; y++ {
+		for x := 0; x < columns; x++ {
+			tile := lvl.Tile(x, y)
+			originX := x * pixelsPerTile
+			originY := (rows - 1 - y) * pixelsPerTile
+			var overlay [4]float32
+			if options.ColorFunc != nil {
+				overlay = options.ColorFunc(x, y)
+			}
+			var bmp *bitmap.Bitmap
+			rotations := 0
+			if (tile != nil) && (options.TextureFunc != nil) && !lvl.IsCyberspace() {
+				var atlasIndex int
+				atlasIndex, rotations = options.TextureFunc(tile)
+				if (atlasIndex >= 0) && (atlasIndex < len(atlas)) {
+					bmp = renderer.bitmapFor(atlas[atlasIndex])
+				}
+			}
+			typeInfo := level.TileTypeSolid.Info()
+			if tile != nil {
+				typeInfo = tile.Type.Info()
+			}
+			for pixelY := 0; pixelY < pixelsPerTile; pixelY++ {
+				for pixelX := 0; pixelX < pixelsPerTile; pixelX++ {
+					fineX := (float32(pixelX) + 0.5) / float32(pixelsPerTile)
+					fineY := 1.0 - (float32(pixelY)+0.5)/float32(pixelsPerTile)
+					var pixel color.RGBA
+					if !typeInfo.IsOpenAt(fineX, fineY) {
+						pixel = mapSolidColor
+					} else {
+						pixel = mapOpenColor
+						if bmp != nil {
+							u, v := rotatedUV(fineX, 1.0-fineY, rotations)
+							pixel = renderer.sample(bmp, u, v)
+						}
+						pixel = blend(pixel, overlay)
+					}
+					img.SetRGBA(originX+pixelX, originY+pixelY, pixel)
+				}
+			}
+			renderer.renderWalls(img, lvl, x, y, originX, originY, pixelsPerTile)
+		}
+	}
+
+	if options.ObjectIcons != nil {
+		renderer.renderObjects(img, lvl, options.ObjectIcons, rows, pixelsPerTile)
+	}
+	return img
+}
+
+func (renderer *MapRenderer) renderWalls(img *image.RGBA, lvl *level.Level, x, y int, originX, originY, pixelsPerTile int) {
+	tileType, _, wallHeights := lvl.MapGridInfo(x, y)
+	if tileType == level.TileTypeSolid {
+		return
+	}
+	colorFor := func(heights [3]float32) (color.RGBA, bool) {
+		if heights[1] >= float32(level.TileHeightUnitMax) {
+			return mapWallColor, true
+		} else if heights[1] != 0 {
+			return mapStepColor, true
+		}
+		return color.RGBA{}, false
+	}
+	last := pixelsPerTile - 1
+	if col, visible := colorFor(wallHeights.North); visible {
+		for i := 0; i < pixelsPerTile; i++ {
+			img.SetRGBA(originX+i, originY, col)
+		}
+	}
+	if col, visible := colorFor(wallHeights.South); visible {
+		for i := 0; i < pixelsPerTile; i++ {
+			img.SetRGBA(originX+i, originY+last, col)
+		}
+	}
+	if col, visible := colorFor(wallHeights.West); visible {
+		for i := 0; i < pixelsPerTile; i++ {
+			img.SetRGBA(originX, originY+i, col)
+		}
+	}
+	if col, visible := colorFor(wallHeights.East); visible {
+		for i := 0; i < pixelsPerTile; i++ {
+			img.SetRGBA(originX+last, originY+i, col)
+		}
+	}
+}
+
+func (renderer *MapRenderer) renderObjects(img *image.RGBA, lvl *level.Level, icons ObjectIconRetriever, rows, pixelsPerTile int) {
+	iconSize := pixelsPerTile / mapIconSizeFactor
+	if iconSize < 2 {
+		iconSize = 2
+	}
+	cache := make(map[object.Triple]*bitmap.Bitmap)
+	lvl.ForEachObject(func(id level.ObjectID, entry level.ObjectMasterEntry) {
+		triple := entry.Triple()
+		bmp, cached := cache[triple]
+		if !cached {
+			var err error
+			bmp, err = icons(triple)
+			if (err != nil) || (bmp == nil) || (bmp.Header.Width <= 0) || (bmp.Header.Height <= 0) {
+				bmp = nil
+			}
+			cache[triple] = bmp
+		}
+		if bmp == nil {
+			return
+		}
+		centerX := int(float32(entry.X) * float32(pixelsPerTile) / 256)
+		centerY := rows*pixelsPerTile - int(float32(entry.Y)*float32(pixelsPerTile)/256)
+		width := int(bmp.Header.Width)
+		height := int(bmp.Header.Height)
+		scale := float32(iconSize) / float32(width)
+		if height > width {
+			scale = float32(iconSize) / float32(height)
+		}
+		drawWidth := int(float32(width) * scale)
+		drawHeight := int(float32(height) * scale)
+		for drawY := 0; drawY < drawHeight; drawY++ {
+			for drawX := 0; drawX < drawWidth; drawX++ {
+				sourceX := int(float32(drawX) / scale)
+				sourceY := int(float32(drawY) / scale)
+				index := bmp.Pixels[sourceY*int(bmp.Header.Stride)+sourceX]
+				if index == 0 {
+					continue
+				}
+				rgb := renderer.palette[index]
+				img.SetRGBA(centerX-drawWidth/2+drawX, centerY-drawHeight/2+drawY,
+					color.RGBA{R: rgb.Red, G: rgb.Green, B: rgb.Blue, A: 0xFF})
+			}
+		}
+	})
+}
+
+func (renderer *MapRenderer) bitmapFor(index level.TextureIndex) *bitmap.Bitmap {
+	bmp, cached := renderer.bitmaps[index]
+	if !cached {
+		var err error
+		bmp, err = renderer.textures(index)
+		if (err != nil) || (bmp == nil) || (bmp.Header.Width <= 0) || (bmp.Header.Height <= 0) {
+			bmp = nil
+		}
+		renderer.bitmaps[index] = bmp
+	}
+	return bmp
+}
+
+func (renderer *MapRenderer) sample(bmp *bitmap.Bitmap, u, v float32) color.RGBA {
+	width := int(bmp.Header.Width)
+	height := int(bmp.Header.Height)
+	x := int(u * float32(width))
+	y := int(v * float32(height))
+	if x >= width {
+		x = width - 1
+	}
+	if y >= height {
+		y = height - 1
+	}
+	rgb := renderer.palette[bmp.Pixels[y*int(bmp.Header.Stride)+x]]
+	return color.RGBA{R: rgb.Red, G: rgb.Green, B: rgb.Blue, A: 0xFF}
+}
+
+func blend(base color.RGBA, overlay [4]float32) color.RGBA {
+	alpha := overlay[3]
+	if alpha <= 0 {
+		return base
+	}
+	mix := func(value uint8, over float32) uint8 {
+		return uint8(float32(value)*(1-alpha) + over*255*alpha)
+	}
+	return color.RGBA{R: mix(base.R, overlay[0]), G: mix(base.G, overlay[1]), B: mix(base.B, overlay[2]), A: 0xFF}
+}
@@ -0,0 +1,61 @@
+package lvlrender_test
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlrender"
+	"github.com/inkyblackness/hacked/ss1/content/bitmap"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapRendererCreatesImageOfConfiguredSize(t *testing.T) {
+	renderer := lvlrender.NewMapRenderer(bitmap.Palette{}, redTexture)
+	lvl := roomLevel()
+	columns, rows, _ := lvl.Size()
+
+	img := renderer.Render(lvl, lvlrender.MapImageOptions{PixelsPerTile: 8})
+
+	assert.Equal(t, columns*8, img.Bounds().Dx())
+	assert.Equal(t, rows*8, img.Bounds().Dy())
+}
+
+func TestMapRendererDrawsTexturesOfOpenTilesWithNorthUp(t *testing.T) {
+	var palette bitmap.Palette
+	palette[1] = bitmap.RGB{Red: 200}
+	renderer := lvlrender.NewMapRenderer(palette, redTexture)
+	lvl := roomLevel()
+	_, rows, _ := lvl.Size()
+	options := lvlrender.MapImageOptions{
+		PixelsPerTile: 8,
+		TextureFunc: func(tile *level.TileMapEntry) (int, int) {
+			return tile.TextureInfo.FloorTextureIndex(), 0
+		},
+	}
+
+	img := renderer.Render(lvl, options)
+
+	open := img.RGBAAt(11*8+4, (rows-1-11)*8+4)
+	assert.Equal(t, uint8(200), open.R, "open tile should be textured")
+	solid := img.RGBAAt(2*8+4, (rows-1-2)*8+4)
+	assert.NotEqual(t, uint8(200), solid.R, "solid tile should not be textured")
+	wall := img.RGBAAt(10*8, (rows-1-11)*8+4)
+	assert.NotEqual(t, uint8(200), wall.R, "western wall should be drawn")
+}
+
+func TestMapRendererBlendsOverlayColors(t *testing.T) {
+	renderer := lvlrender.NewMapRenderer(bitmap.Palette{}, redTexture)
+	lvl := roomLevel()
+	_, rows, _ := lvl.Size()
+	options := lvlrender.MapImageOptions{
+		PixelsPerTile: 8,
+		ColorFunc: func(x, y int) [4]float32 {
+			return [4]float32{0.0, 1.0, 0.0, 1.0}
+		},
+	}
+
+	img := renderer.Render(lvl, options)
+
+	assert.Equal(t, uint8(255), img.RGBAAt(11*8+4, (rows-1-11)*8+4).G)
+}
@@ -0,0 +1,42 @@
+package lvlrender
+
+import (
+	"github.com/inkyblackness/hacked/ss1/content/bitmap"
+	"github.com/inkyblackness/hacked/ss1/content/object"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+)
+
+// ObjectIconRetriever returns the map icon for given object type.
+type ObjectIconRetriever func(triple object.Triple) (*bitmap.Bitmap, error)
+
+// ObjectBitmapOffsets returns the index of the first bitmap of each object type
+// within the object bitmaps resource. The map icon is the next bitmap after this offset.
+func ObjectBitmapOffsets(properties object.PropertiesTable) map[object.Triple]int {
+	offsets := make(map[object.Triple]int)
+	offset := 0
+	properties.Iterate(func(triple object.Triple, prop *object.Properties) bool {
+		numExtra := prop.Common.Bitmap3D >> 12
+
+		if triple.Class != object.ClassTrap {
+			offsets[triple] = offset + 2
+		} else {
+			offsets[triple] = offset
+		}
+		offset += 3 + int(numExtra)
+		return true
+	})
+	return offsets
+}
+
+// ObjectIconsFrom returns a retriever that decodes object icons from given localizer.
+func ObjectIconsFrom(localizer resource.Localizer, properties object.PropertiesTable) ObjectIconRetriever {
+	offsets := ObjectBitmapOffsets(properties)
+	return func(triple object.Triple) (*bitmap.Bitmap, error) {
+		offset, known := offsets[triple]
+		if !known {
+			return nil, errUnknownObject
+		}
+		return bitmapFrom(localizer, resource.KeyOf(ids.ObjectBitmaps, resource.LangAny, offset+1))
+	}
+}
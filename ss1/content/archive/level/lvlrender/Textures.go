@@ -0,0 +1,40 @@
+package lvlrender
+
+import (
+	"errors"
+
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/bitmap"
+	"github.com/inkyblackness/hacked/ss1/resource"
+	"github.com/inkyblackness/hacked/ss1/world/ids"
+)
+
+var errUnknownObject = errors.New("unknown object")
+
+// TexturesFrom returns a retriever that decodes the large textures from given localizer.
+func TexturesFrom(localizer resource.Localizer) TextureRetriever {
+	return func(index level.TextureIndex) (*bitmap.Bitmap, error) {
+		return bitmapFrom(localizer, resource.KeyOf(ids.LargeTextures.Plus(int(index)), resource.LangAny, 0))
+	}
+}
+
+// PaletteFrom returns the identified game palette from given localizer.
+func PaletteFrom(localizer resource.Localizer, index int) (bitmap.Palette, error) {
+	cache := bitmap.NewPaletteCache(localizer)
+	return cache.Palette(resource.KeyOf(ids.GamePalettesStart.Plus(index), resource.LangAny, 0))
+}
+
+func bitmapFrom(localizer resource.Localizer, key resource.Key) (*bitmap.Bitmap, error) {
+	view, err := localizer.LocalizedResources(key.Lang).Select(key.ID)
+	if err != nil {
+		return nil, err
+	}
+	if view.ContentType() != resource.Bitmap {
+		return nil, errors.New("resource not a bitmap")
+	}
+	reader, err := view.Block(key.Index)
+	if err != nil {
+		return nil, err
+	}
+	return bitmap.Decode(reader)
+}
@@ -0,0 +1,52 @@
+// Package lvltest provides levels for tests that need them.
+package lvltest
+
+import (
+	"github.com/inkyblackness/hacked/ss1/content/archive/level"
+	"github.com/inkyblackness/hacked/ss1/content/archive/level/lvlids"
+	"github.com/inkyblackness/hacked/ss1/resource"
+)
+
+// levelBase is the resource ID of the first level range, the levels are created with level ID 1.
+const levelBase = resource.ID(4000)
+
+type levelDataFilter struct {
+	start resource.ID
+	data  [lvlids.PerLevel][]byte
+}
+
+func (filter levelDataFilter) Filter(lang resource.Language, id resource.ID) resource.List {
+	offset := int(id.Value()) - int(filter.start.Value())
+	if (offset < 0) || (offset >= lvlids.PerLevel) || (len(filter.data[offset]) == 0) {
+		return nil
+	}
+	res := resource.Resource{
+		ContentType:   resource.Archive,
+		BlockProvider: resource.MemoryBlockProvider([][]byte{filter.data[offset]}),
+	}
+	return resource.List{res.ToView()}
+}
+
+type levelDataLocalizer struct {
+	filter levelDataFilter
+}
+
+func (localizer levelDataLocalizer) LocalizedResources(lang resource.Language) resource.Selector {
+	return resource.Selector{Lang: lang, From: localizer.filter}
+}
+
+// NewLevel returns a level that is loaded from given data.
+// The data is indexed by the level-specific resource identifier, as returned by Level.EncodeState().
+func NewLevel(data [lvlids.PerLevel][]byte) *level.Level {
+	start := levelBase.Plus(lvlids.PerLevel)
+	return level.NewLevel(levelBase, 1, levelDataLocalizer{filter: levelDataFilter{start: start, data: data}})
+}
+
+// EmptyLevel returns a new level based on the data of an empty level.
+// The optional map modifier can make initial changes to the map.
+func EmptyLevel(mapModifier func(level.TileMap)) *level.Level {
+	if mapModifier == nil {
+		mapModifier = func(level.TileMap) {}
+	}
+	return NewLevel(level.EmptyLevelData(level.EmptyLevelParameters{MapModifier: mapModifier}))
+}